@@ -0,0 +1,210 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/messages"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// ActionType is what a trainer does with their turn.
+type ActionType string
+
+// The values match the "action" field of ATTACK_ANNOUNCE.
+const (
+	ActionAttack  ActionType = messages.ActionAttack  // Use one of the active Pokemon's moves
+	ActionProtect ActionType = messages.ActionProtect // Block the opponent's next attack
+	ActionItem    ActionType = messages.ActionItem    // Use a bag item on the active Pokemon
+	ActionSwitch  ActionType = messages.ActionSwitch  // Swap the active Pokemon with a reserve
+	ActionForfeit ActionType = messages.ActionForfeit // Give up the match; sent as GAME_OVER in a duel
+)
+
+// TurnAction is the action chosen for a turn. Only the fields that belong to
+// the action's type are used.
+type TurnAction struct {
+	Type        ActionType
+	Move        poke.Move     // ActionAttack
+	AttackBoost bool          // ActionAttack: spend an attack boost
+	ItemName    string        // ActionItem
+	SwitchTo    int           // ActionSwitch: index into the player's reserves
+	TimeUsed    time.Duration // Thinking time charged to the player's clock
+	Slot        int           // Doubles: slot of the acting Pokemon
+	Target      string        // Doubles: TargetFoe, TargetAlly or TargetSpread
+	TargetSlot  int           // Doubles: foe slot for TargetFoe
+}
+
+// Describe summarizes the action for logs and prompts.
+func (a TurnAction) Describe(user string) string {
+	switch a.Type {
+	case ActionProtect:
+		return user + " is protecting itself"
+	case ActionItem:
+		return user + " uses " + a.ItemName
+	case ActionSwitch:
+		return fmt.Sprintf("%s switches to reserve #%d", user, a.SwitchTo+1)
+	case ActionForfeit:
+		return user + " forfeits"
+	}
+	return user + " used " + a.Move.Name
+}
+
+// Priority brackets of the actions that are not moves. Switching and items
+// always go first, and protecting beats every ordinary move.
+const (
+	SwitchPriority  = 6
+	ItemPriority    = 6
+	ProtectPriority = 4
+)
+
+// ActionPriority returns the priority bracket of an action. Attacks use the
+// bracket of their move.
+func ActionPriority(action ActionType, move poke.Move) int {
+	switch action {
+	case ActionSwitch:
+		return SwitchPriority
+	case ActionItem:
+		return ItemPriority
+	case ActionProtect:
+		return ProtectPriority
+	}
+	return move.Priority
+}
+
+// Priority returns the action's priority bracket.
+func (a TurnAction) Priority() int {
+	return ActionPriority(a.Type, a.Move)
+}
+
+// AdvanceTurn passes the turn after an action. Turns come in rounds in which
+// each trainer acts once. A duel round is opened by OpenRound once both
+// trainers have chosen their action for it; until then the faster active
+// Pokemon, and otherwise the host, is to move. In doubles every active
+// Pokemon acts once per round, fastest first.
+func (g *Game) AdvanceTurn() {
+	if g.Rules.Doubles {
+		g.TurnsThisRound++
+		g.nextActor()
+		return
+	}
+
+	g.TurnsThisRound++
+	if g.TurnsThisRound < 2 {
+		if g.CurrentTurn == "host" {
+			g.CurrentTurn = "joiner"
+		} else {
+			g.CurrentTurn = "host"
+		}
+		return
+	}
+	g.TurnsThisRound = 0
+	g.CurrentTurn = g.roundOpener(0, 0)
+}
+
+// OpenRound decides who opens a duel round from the priority brackets of
+// the actions both trainers chose for it, before either is played: the
+// higher bracket goes first, then the faster active Pokemon, and otherwise
+// the host.
+func (g *Game) OpenRound(hostPriority int, joinerPriority int) {
+	g.CurrentTurn = g.roundOpener(hostPriority, joinerPriority)
+}
+
+// roundOpener returns who acts first in a round with the given brackets.
+func (g *Game) roundOpener(hostPriority int, joinerPriority int) string {
+	host, joiner := g.Host, g.Joiner
+	switch {
+	case host == nil || joiner == nil:
+		return "host"
+	case joinerPriority != hostPriority:
+		if joinerPriority > hostPriority {
+			return "joiner"
+		}
+		return "host"
+	case joiner.PokemonStruct.BattleStat(poke.StatSpeed) > host.PokemonStruct.BattleStat(poke.StatSpeed):
+		return "joiner"
+	}
+	return "host"
+}
+
+// ResolveProtect resolves a protect action. A Pokemon cannot protect two
+// turns in a row.
+func (g *Game) ResolveProtect(user *player.Player, defender *player.Player) (AttackOutcome, error) {
+	if user.LastAction == string(ActionProtect) {
+		return AttackOutcome{}, fmt.Errorf("%s cannot protect twice in a row", user.PokemonStruct.Name)
+	}
+	return AttackOutcome{
+		Action:            ActionProtect,
+		TypeEffectiveness: 1.0,
+		DefenderHP:        defender.PokemonStruct.HP,
+		AttackerHP:        user.PokemonStruct.HP,
+	}, nil
+}
+
+// ResolveSwitch resolves switching the active Pokemon with a reserve.
+func (g *Game) ResolveSwitch(user *player.Player, defender *player.Player, slot int) (AttackOutcome, error) {
+	if slot < 0 || slot >= len(user.Reserves) {
+		return AttackOutcome{}, fmt.Errorf("%s has no reserve #%d", user.TrainerName, slot+1)
+	}
+	if IsFainted(&user.Reserves[slot]) {
+		return AttackOutcome{}, fmt.Errorf("%s has fainted and cannot battle", user.Reserves[slot].Name)
+	}
+	return AttackOutcome{
+		Action:            ActionSwitch,
+		TypeEffectiveness: 1.0,
+		DefenderHP:        defender.PokemonStruct.HP,
+		AttackerHP:        user.Reserves[slot].HP,
+		SwitchTo:          slot,
+	}, nil
+}
+
+// ResolveAction resolves any non-forfeit turn action. Attacks consume the
+// RNG exactly as ResolveAttack does; other actions never touch it.
+func (g *Game) ResolveAction(
+	user *player.Player,
+	defender *player.Player,
+	action TurnAction,
+	defenderUsesBoost bool,
+) (AttackOutcome, error) {
+	switch action.Type {
+	case ActionProtect:
+		return g.ResolveProtect(user, defender)
+	case ActionItem:
+		return g.ResolveItem(user, defender, action.ItemName)
+	case ActionSwitch:
+		return g.ResolveSwitch(user, defender, action.SwitchTo)
+	case ActionAttack:
+		if err := checkChoiceLock(user, action.Move); err != nil {
+			return AttackOutcome{}, err
+		}
+		return g.ResolveAttack(user, defender, action.Move, action.AttackBoost, defenderUsesBoost), nil
+	}
+	return AttackOutcome{}, fmt.Errorf("unknown action %q", action.Type)
+}
+
+// checkChoiceLock returns an error if a Choice item keeps the user's active
+// Pokemon from using move.
+func checkChoiceLock(user *player.Player, move poke.Move) error {
+	mon := &user.PokemonStruct
+	if !mon.CanUse(move) {
+		return fmt.Errorf("%s is locked into %s by its %s", mon.Name, mon.ChoiceLock, mon.HeldItem)
+	}
+	return nil
+}
+
+// ReplaceFainted sends out the first healthy reserve if the active Pokemon
+// fainted. Both peers call it in the same order, so the choice needs no
+// message. Returns an event describing the replacement, or "" if none.
+func ReplaceFainted(p *player.Player) string {
+	if !IsFainted(&p.PokemonStruct) {
+		return ""
+	}
+	for i := range p.Reserves {
+		if !IsFainted(&p.Reserves[i]) {
+			fainted := p.PokemonStruct.Name
+			p.SwitchActive(i)
+			return fmt.Sprintf("%s fainted! %s was sent out!", fainted, p.PokemonStruct.Name)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,405 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// AttackOutcome is the result of resolving a single attack.
+// Both peers compute it independently and compare the results.
+type AttackOutcome struct {
+	Action            ActionType      // The kind of action that was resolved
+	Damage            int             // Damage dealt after all modifiers
+	DefenderHP        int             // Defender's HP after the attack
+	TypeEffectiveness float64         // Combined type multiplier against the defender
+	Critical          bool            // The attack landed a critical hit
+	Endured           bool            // The defender held on at 1 HP out of friendship
+	AttackerHP        int             // Attacker's HP after the action (item recoil or healing)
+	ItemUsed          string          // Bag item used instead of a move ("" when attacking)
+	Healed            int             // HP restored by the bag item
+	CuredStatus       string          // Status removed by the bag item
+	Protected         bool            // The defender's protection blocked the attack
+	SwitchTo          int             // Reserve slot switched in
+	Hits              []int           // Damage of each hit that landed, in order
+	Recoil            int             // HP the attacker lost to recoil
+	ItemRecoil        int             // HP the attacker lost to its held item, e.g. Life Orb
+	Drained           int             // HP the attacker restored by draining
+	Effects           []AppliedEffect // Scripted stat changes and statuses that landed
+	TargetSlot        int             // Doubles: the defender's slot that was hit
+	Spread            []AttackOutcome // Doubles: one outcome per foe hit by a spread move
+}
+
+// ResolveAttack resolves one attack from attacker to defender without changing
+// either player or the field. The game's RNG is consumed in a fixed order so
+// that both peers, starting from the same seed, reach the same outcome.
+func (g *Game) ResolveAttack(
+	attacker *player.Player,
+	defender *player.Player,
+	move poke.Move,
+	attackerUsesBoost bool,
+	defenderUsesBoost bool,
+) AttackOutcome {
+	return g.resolveAttack(attacker, defender, move, attackerUsesBoost, defenderUsesBoost, 1.0)
+}
+
+// resolveAttack is ResolveAttack with an extra damage scale, used for
+// doubles spread moves.
+func (g *Game) resolveAttack(
+	attacker *player.Player,
+	defender *player.Player,
+	move poke.Move,
+	attackerUsesBoost bool,
+	defenderUsesBoost bool,
+	spreadScale float64,
+) AttackOutcome {
+	outcome := AttackOutcome{
+		Action:            ActionAttack,
+		TypeEffectiveness: GetMoveEffectiveness(g.TypeChart, move, &defender.PokemonStruct),
+		DefenderHP:        defender.PokemonStruct.HP,
+		AttackerHP:        attacker.PokemonStruct.HP,
+	}
+
+	// A protected defender blocks the attack before any roll is made. Status
+	// moves that only change the field or the user are not aimed at it.
+	if defender.PokemonStruct.Protecting && (move.IsDamaging() || move.AffectsTarget()) {
+		outcome.Protected = true
+		return outcome
+	}
+
+	// Status moves only change the field and run their scripted effects
+	if !move.IsDamaging() {
+		outcome.Effects = g.resolveSecondaryEffects(attacker, defender, move, outcome)
+		return outcome
+	}
+
+	// Friendship crit roll happens before damage so the roll order never changes.
+	// Fixed damage ignores stats, so it can never be a critical hit.
+	if g.Rules.FriendshipEffects && move.FixedDamage == 0 {
+		outcome.Critical = g.RNG.Float64() < FriendshipCritChance(attacker.Friendship)
+	}
+
+	// Scripted damage modifiers are checked once, before any hit
+	scale := g.scriptedDamageMultiplier(attacker, defender, move) * spreadScale
+
+	// Each hit is calculated on its own; the move stops once the defender faints
+	hits := g.rollHits(move)
+	for i := 0; i < hits && outcome.Damage < defender.PokemonStruct.HP; i++ {
+		damage := g.hitDamage(attacker, defender, move, attackerUsesBoost, defenderUsesBoost, outcome.Critical, scale)
+		outcome.Hits = append(outcome.Hits, damage)
+		outcome.Damage += damage
+	}
+
+	outcome.DefenderHP = defender.PokemonStruct.HP - outcome.Damage
+	if outcome.DefenderHP < 0 {
+		outcome.DefenderHP = 0
+	}
+
+	// A close friend may hang on at 1 HP instead of fainting
+	if g.Rules.FriendshipEffects && outcome.DefenderHP == 0 && defender.PokemonStruct.HP > 1 {
+		if g.RNG.Float64() < FriendshipEndureChance(defender.Friendship) {
+			outcome.Endured = true
+			outcome.DefenderHP = 1
+			outcome.Hits[len(outcome.Hits)-1] -= outcome.Damage - (defender.PokemonStruct.HP - 1)
+			outcome.Damage = defender.PokemonStruct.HP - 1
+		}
+	}
+
+	// Drain moves restore part of the damage dealt, recoil moves cost part of it
+	if move.DrainPercent > 0 && outcome.Damage > 0 {
+		drain := max(outcome.Damage*move.DrainPercent/100, 1)
+		outcome.Drained = min(drain, attacker.PokemonStruct.MaxHP-outcome.AttackerHP)
+		outcome.AttackerHP += outcome.Drained
+	}
+	if move.RecoilPercent > 0 && outcome.Damage > 0 {
+		outcome.Recoil = min(max(outcome.Damage*move.RecoilPercent/100, 1), outcome.AttackerHP)
+		outcome.AttackerHP -= outcome.Recoil
+	}
+
+	// Life Orb costs the holder HP for every attack that lands
+	heldItem, hasItem := poke.GetItem(attacker.PokemonStruct.HeldItem)
+	if hasItem && heldItem.ResidualDamageFrac > 0 && outcome.Damage > 0 {
+		outcome.ItemRecoil = min(max(attacker.PokemonStruct.MaxHP/heldItem.ResidualDamageFrac, 1), outcome.AttackerHP)
+		outcome.AttackerHP -= outcome.ItemRecoil
+	}
+
+	outcome.Effects = g.resolveSecondaryEffects(attacker, defender, move, outcome)
+	return outcome
+}
+
+// rollHits returns how many times a move strikes. Moves with a fixed hit
+// count never touch the RNG; 2-5 hit moves follow the usual 35/35/15/15
+// odds, and any other range is uniform.
+func (g *Game) rollHits(move poke.Move) int {
+	switch {
+	case !move.IsMultiHit():
+		return 1
+	case move.MinHits >= move.MaxHits:
+		return move.MaxHits
+	case move.MinHits == 2 && move.MaxHits == 5:
+		roll := g.RNG.Intn(20)
+		switch {
+		case roll < 7:
+			return 2
+		case roll < 14:
+			return 3
+		case roll < 17:
+			return 4
+		}
+		return 5
+	}
+	minHits := max(move.MinHits, 1)
+	return minHits + g.RNG.Intn(move.MaxHits-minHits+1)
+}
+
+// hitDamage calculates the damage of a single hit with every modifier
+// applied, including the move's scripted damage scale. Fixed damage moves
+// skip the calculation and the RNG entirely, but still cannot hurt a Pokemon
+// that is immune to their type.
+func (g *Game) hitDamage(
+	attacker *player.Player,
+	defender *player.Player,
+	move poke.Move,
+	attackerUsesBoost bool,
+	defenderUsesBoost bool,
+	critical bool,
+	scale float64,
+) int {
+	if move.FixedDamage > 0 {
+		if GetMoveEffectiveness(g.TypeChart, move, &defender.PokemonStruct) == 0 {
+			return 0
+		}
+		return move.FixedDamage
+	}
+
+	damage := g.DamageModel().Damage(
+		&attacker.PokemonStruct,
+		&defender.PokemonStruct,
+		move,
+		g.TypeChart,
+		attackerUsesBoost && g.Rules.CanBoost(move.DamageCategory),
+		defenderUsesBoost && g.Rules.CanBoost(move.DamageCategory),
+		g.Rules.BoostMultiplier,
+		g.RNG,
+	)
+	if critical && damage > 0 {
+		damage = int(float64(damage) * CriticalMultiplier)
+	}
+
+	// A burn halves physical damage
+	if attacker.PokemonStruct.Status == poke.StatusBurn && move.DamageCategory == poke.Physical && damage > 1 {
+		damage /= 2
+	}
+
+	// Held items like Choice Band and Charcoal boost matching moves
+	heldItem, hasItem := poke.GetItem(attacker.PokemonStruct.HeldItem)
+	if multiplier := heldItem.AttackMultiplier(move); hasItem && multiplier != 1.0 && damage > 0 {
+		damage = max(int(math.Round(float64(damage)*multiplier)), 1)
+	}
+
+	// Weather and terrain scale the final damage
+	if multiplier := g.Field.DamageMultiplier(move); multiplier != 1.0 && damage > 0 {
+		damage = max(int(math.Round(float64(damage)*multiplier)), 1)
+	}
+
+	// Scripted effects like "double damage if the target is poisoned"
+	if scale != 1.0 && damage > 0 {
+		damage = max(int(math.Round(float64(damage)*scale)), 1)
+	}
+	return damage
+}
+
+// DescribeEffects narrates the hits, recoil, held item recoil and drain of
+// an attack, e.g. "Hit 3 times! Pikachu is damaged by recoil (12 HP)."
+func (o AttackOutcome) DescribeEffects(attacker string) string {
+	var parts []string
+	if len(o.Hits) > 1 {
+		parts = append(parts, fmt.Sprintf("Hit %d times!", len(o.Hits)))
+	}
+	if o.Drained > 0 {
+		parts = append(parts, fmt.Sprintf("%s drained %d HP.", attacker, o.Drained))
+	}
+	if o.Recoil > 0 {
+		parts = append(parts, fmt.Sprintf("%s is damaged by recoil (%d HP).", attacker, o.Recoil))
+	}
+	if o.ItemRecoil > 0 {
+		parts = append(parts, fmt.Sprintf("%s lost some HP to its held item (%d HP).", attacker, o.ItemRecoil))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ResolveItem resolves using a bag item instead of attacking. Like
+// ResolveAttack it changes nothing; the item is taken from the bag in
+// FinishAttack. Returns an error if the item is not in the user's bag.
+func (g *Game) ResolveItem(user *player.Player, defender *player.Player, itemName string) (AttackOutcome, error) {
+	outcome := AttackOutcome{
+		Action:            ActionItem,
+		TypeEffectiveness: 1.0,
+		DefenderHP:        defender.PokemonStruct.HP,
+		AttackerHP:        user.PokemonStruct.HP,
+		ItemUsed:          itemName,
+	}
+
+	item, ok := poke.GetItem(itemName)
+	if !ok || item.Kind != poke.BagItem {
+		return outcome, fmt.Errorf("%q is not a bag item", itemName)
+	}
+	if user.Bag[item.Name] <= 0 {
+		return outcome, fmt.Errorf("%s has no %s left", user.PokemonStruct.Name, item.Name)
+	}
+	outcome.ItemUsed = item.Name
+
+	if item.HealHP > 0 {
+		outcome.Healed = min(item.HealHP, user.PokemonStruct.MaxHP-user.PokemonStruct.HP)
+		outcome.AttackerHP += outcome.Healed
+	}
+	if item.CuresStatus {
+		outcome.CuredStatus = user.PokemonStruct.Status
+	}
+
+	return outcome, nil
+}
+
+// DescribeItem summarizes a bag item use, e.g. "Pikachu used a Potion and restored 20 HP."
+func (o AttackOutcome) DescribeItem(user string) string {
+	text := fmt.Sprintf("%s used a %s", user, o.ItemUsed)
+	switch {
+	case o.Healed > 0 && o.CuredStatus != poke.StatusNone:
+		text += fmt.Sprintf(", restored %d HP and is no longer %s", o.Healed, strings.ToLower(poke.StatusName(o.CuredStatus)))
+	case o.Healed > 0:
+		text += fmt.Sprintf(" and restored %d HP", o.Healed)
+	case o.CuredStatus != poke.StatusNone:
+		text += fmt.Sprintf(" and is no longer %s", strings.ToLower(poke.StatusName(o.CuredStatus)))
+	default:
+		text += ", but nothing happened"
+	}
+	return text + "."
+}
+
+// GetMoveEffectiveness returns the combined type multiplier of a move against
+// a Pokemon under the given type chart.
+func GetMoveEffectiveness(chart poke.TypeChart, move poke.Move, defender *poke.Pokemon) float64 {
+	return chart.Against(move.Type, defender.Type1, defender.Type2)
+}
+
+// FinishAttack applies a resolved action to both players and the field, runs
+// end-of-turn effects for both Pokemon and sends out reserves for any that
+// fainted. Returns the events in the order they happened.
+func (g *Game) FinishAttack(
+	attacker *player.Player,
+	defender *player.Player,
+	move poke.Move,
+	outcome AttackOutcome,
+) []string {
+	return g.finishAttack(attacker, defender, move, outcome, true)
+}
+
+// finishAttack is FinishAttack with the end-of-turn effects optional, so a
+// doubles spread move can apply several outcomes and end the turn once.
+func (g *Game) finishAttack(
+	attacker *player.Player,
+	defender *player.Player,
+	move poke.Move,
+	outcome AttackOutcome,
+	endOfTurn bool,
+) []string {
+	if lost := defender.PokemonStruct.HP - outcome.DefenderHP; lost > 0 {
+		attacker.DamageDealt += lost
+	}
+	defender.PokemonStruct.HP = outcome.DefenderHP
+
+	// Protection only lasts until the opponent's next action. In doubles it
+	// is only used up by an attack on its slot, and ends with the round.
+	if outcome.Action == ActionAttack || !g.Rules.Doubles {
+		defender.PokemonStruct.Protecting = false
+	}
+
+	var events []string
+	switch outcome.Action {
+	case ActionItem:
+		attacker.PokemonStruct.HP = outcome.AttackerHP
+		attacker.Bag.Take(outcome.ItemUsed)
+		if outcome.CuredStatus != poke.StatusNone {
+			attacker.PokemonStruct.Status = poke.StatusNone
+		}
+	case ActionProtect:
+		attacker.PokemonStruct.Protecting = true
+		events = append(events, attacker.PokemonStruct.Name+" protected itself!")
+	case ActionSwitch:
+		previous := attacker.PokemonStruct.Name
+		attacker.SwitchActive(outcome.SwitchTo)
+		events = append(events, fmt.Sprintf("%s, come back! Go, %s!", previous, attacker.PokemonStruct.Name))
+		events = append(events, g.Field.ApplyEntryAbility(&attacker.PokemonStruct)...)
+	default:
+		attacker.PokemonStruct.HP = outcome.AttackerHP
+		if item, ok := poke.GetItem(attacker.PokemonStruct.HeldItem); ok && item.LocksMove && attacker.PokemonStruct.ChoiceLock == "" {
+			attacker.PokemonStruct.ChoiceLock = move.Name
+		}
+		if outcome.Protected {
+			events = append(events, defender.PokemonStruct.Name+" protected itself from "+move.Name+"!")
+		}
+		events = append(events, applyEffects(attacker, defender, outcome.Effects)...)
+		events = append(events, g.Field.ApplyMove(move)...)
+	}
+	attacker.LastAction = string(outcome.Action)
+	if !endOfTurn {
+		return events
+	}
+
+	events = append(events, g.Field.EndOfTurn(&attacker.PokemonStruct, &defender.PokemonStruct)...)
+	events = append(events, g.heldItemEndOfTurn(attacker)...)
+	events = append(events, g.heldItemEndOfTurn(defender)...)
+
+	// Fainted Pokemon are replaced by the first healthy reserve, attacker first
+	for _, p := range []*player.Player{attacker, defender} {
+		if event := ReplaceFainted(p); event != "" {
+			events = append(events, event)
+			events = append(events, g.Field.ApplyEntryAbility(&p.PokemonStruct)...)
+		}
+	}
+	return events
+}
+
+// heldItemEndOfTurn applies status damage, held item effects and friendship
+// status recovery to one Pokemon at the end of a turn. The RNG is only used
+// when friendship effects are on and the Pokemon has a status.
+func (g *Game) heldItemEndOfTurn(p *player.Player) []string {
+	mon := &p.PokemonStruct
+	if mon.HP <= 0 {
+		return nil
+	}
+
+	var events []string
+	if fraction := poke.StatusResidualFraction(mon.Status); fraction > 0 {
+		damage := max(mon.MaxHP/fraction, 1)
+		ApplyDamage(mon, damage)
+		events = append(events, fmt.Sprintf("%s is hurt by its %s! (-%d HP)", mon.Name, mon.Status, damage))
+		if mon.HP <= 0 {
+			return events
+		}
+	}
+
+	item, ok := poke.GetItem(mon.HeldItem)
+	if ok && item.HealFraction > 0 && mon.HP < mon.MaxHP {
+		heal := min(max(mon.MaxHP/item.HealFraction, 1), mon.MaxHP-mon.HP)
+		mon.HP += heal
+		events = append(events, fmt.Sprintf("%s restored a little HP using its %s! (+%d HP)", mon.Name, item.Name, heal))
+	}
+	if ok && item.InflictsStatus != poke.StatusNone && mon.Status == poke.StatusNone {
+		mon.Status = item.InflictsStatus
+		events = append(events, fmt.Sprintf("%s was %s by the %s!", mon.Name, strings.ToLower(poke.StatusName(mon.Status)), item.Name))
+		return events
+	}
+
+	if g.Rules.FriendshipEffects && mon.Status != poke.StatusNone {
+		if g.RNG.Float64() < FriendshipShakeOffChance(p.Friendship) {
+			events = append(events, fmt.Sprintf("%s shook off its %s so its trainer wouldn't worry!", mon.Name, mon.Status))
+			mon.Status = poke.StatusNone
+		}
+	}
+
+	return events
+}
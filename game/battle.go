@@ -3,6 +3,7 @@ package game
 import (
 	"math"
 	"math/rand"
+	"strconv"
 
 	"github.com/zrygan/pokemonbattler/poke"
 )
@@ -19,12 +20,15 @@ const (
 
 // CalculateDamage calculates the damage dealt by an attack.
 // Uses the protocol-specified damage formula with type effectiveness.
+// It backs ProtocolDamage, the default DamageModel.
 func CalculateDamage(
 	attacker *poke.Pokemon,
 	defender *poke.Pokemon,
 	move poke.Move,
-	attackerUsesBoost bool, // Whether attacker uses a special attack boost
-	defenderUsesBoost bool, // Whether defender uses a special defense boost
+	chart poke.TypeChart, // Type matchups of the battle's ruleset
+	attackerUsesBoost bool, // Whether attacker uses an attack boost for the move's category
+	defenderUsesBoost bool, // Whether defender uses a defense boost for the move's category
+	boostMultiplier float64, // Stat multiplier of a boost (1.5 by default)
 	rng *rand.Rand, // Seeded random number generator
 ) int {
 	// Determine which stats to use based on move's damage category
@@ -32,28 +36,23 @@ func CalculateDamage(
 	var defenderStat float64
 
 	if move.DamageCategory == poke.Physical {
-		attackerStat = float64(attacker.Attack)
-		defenderStat = float64(defender.Defense)
+		attackerStat = float64(attacker.BattleStat(poke.StatAttack))
+		defenderStat = float64(defender.BattleStat(poke.StatDefense))
 	} else { // Special
-		attackerStat = float64(attacker.SpecialAttack)
-		defenderStat = float64(defender.SpecialDefense)
-
-		// Apply stat boosts if used
-		if attackerUsesBoost && move.DamageCategory == poke.Special {
-			attackerStat *= 1.5 // 50% boost
-		}
-		if defenderUsesBoost && move.DamageCategory == poke.Special {
-			defenderStat *= 1.5 // 50% boost
-		}
+		attackerStat = float64(attacker.BattleStat(poke.StatSpecialAttack))
+		defenderStat = float64(defender.BattleStat(poke.StatSpecialDefense))
 	}
 
-	// Calculate type effectiveness
-	type1Effectiveness := poke.GetTypeEffectiveness(move.Type, defender.Type1)
-	type2Effectiveness := 1.0
-	if defender.Type2 != "" {
-		type2Effectiveness = poke.GetTypeEffectiveness(move.Type, defender.Type2)
+	// Apply stat boosts if used; the ruleset decides which categories allow them
+	if attackerUsesBoost {
+		attackerStat *= boostMultiplier
+	}
+	if defenderUsesBoost {
+		defenderStat *= boostMultiplier
 	}
-	typeEffectiveness := type1Effectiveness * type2Effectiveness
+
+	// Calculate type effectiveness
+	typeEffectiveness := chart.Against(move.Type, defender.Type1, defender.Type2)
 
 	// Base power (default to 1.0 if not set)
 	basePower := move.BasePower
@@ -103,6 +102,10 @@ func GetStatusMessage(
 ) string {
 	msg := attacker.Name + " used " + move.Name + "! "
 
+	if !move.IsDamaging() {
+		return msg
+	}
+
 	if typeEffectiveness == 0 {
 		msg += "It had no effect..."
 	} else if typeEffectiveness > 2.0 {
@@ -113,7 +116,7 @@ func GetStatusMessage(
 		msg += "It was not very effective..."
 	}
 
-	msg += " Dealt " + string(rune(damage)) + " damage."
+	msg += " Dealt " + strconv.Itoa(damage) + " damage."
 
 	if IsFainted(defender) {
 		msg += " " + defender.Name + " fainted!"
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/zrygan/pokemonbattler/game/player"
 	"github.com/zrygan/pokemonbattler/messages"
@@ -20,8 +22,42 @@ type BattleContext struct {
 	OpponentAddr *net.UDPAddr
 	ReliableConn *reliability.ReliableConnection
 	IsHost       bool
+	Decider      Decider // Plays SelfPlayer when set; nil for a human
+
+	answerMu      sync.Mutex  // Guards pendingAnswer
+	pendingAnswer chan string // Receives the next input line while a question is open
+
+	stashed   []stashedMessage // Battle messages read while waiting for input
+	arrived   time.Time        // When the message waitForMessage last returned was received
+	foeCommit *roundCommit     // The opponent's ROUND_COMMIT for the duel round, until it acts
+}
+
+// stashedMessage is a battle message read while waiting for input, kept
+// for waitForMessage.
+type stashedMessage struct {
+	msg     *messages.Message
+	arrived time.Time
+}
+
+// roundCommit is the opponent's ROUND_COMMIT for the duel round being
+// played.
+type roundCommit struct {
+	priority int
+	pokemon  string // The opponent's active Pokemon when it committed
 }
 
+// stash keeps a battle message read while waiting for input, such as the
+// opponent's ROUND_COMMIT or GAME_OVER, for waitForMessage. Other messages
+// are dropped.
+func (bc *BattleContext) stash(msg *messages.Message) {
+	if msg.MessageType == messages.RoundCommit || msg.MessageType == messages.GameOver {
+		bc.stashed = append(bc.stashed, stashedMessage{msg: msg, arrived: time.Now()})
+	}
+}
+
+// DefenseBoostTimeout is how long the defender has to decide on a Special Defense boost.
+const DefenseBoostTimeout = 10 * time.Second
+
 // broadcastToSpectators sends a message to all spectators
 func (bc *BattleContext) broadcastToSpectators(msg []byte) {
 	if len(bc.Game.Spectators) > 0 {
@@ -45,9 +81,9 @@ func (bc *BattleContext) broadcastToSpectatorsExcept(msg []byte, exceptAddr *net
 }
 
 // ProcessTurn handles the attack, defense, and calculation phases of a turn.
-func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool) error {
+// On our turn action is what we do; on the opponent's turn it is ignored.
+func (bc *BattleContext) ProcessTurn(action TurnAction) error {
 	var opponentPeer peer.PeerDescriptor
-	var moveName string
 
 	isMyTurn := (bc.IsHost && bc.Game.CurrentTurn == "host") ||
 		(!bc.IsHost && bc.Game.CurrentTurn == "joiner")
@@ -56,7 +92,14 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 		// Attacker's turn
 		// Send ATTACK_ANNOUNCE
 		seqNum := bc.ReliableConn.GetNextSequenceNumber()
-		attackMsg := messages.MakeAttackAnnounce(selectedMove.Name, seqNum)
+		attackMsg := makeActionAnnounce(action, seqNum)
+		if bc.Game.Rules.Doubles {
+			messages.AddTarget(attackMsg, action.Slot, action.Target, action.TargetSlot)
+		}
+		if bc.Game.Clock.Enabled() {
+			messages.AddTimeUsed(attackMsg, int(action.TimeUsed.Milliseconds()))
+			bc.Game.Clock.Charge(bc.Game.CurrentTurn, action.TimeUsed)
+		}
 		attackMsgBytes := attackMsg.SerializeMessage()
 		// Send using proper communication mode handling
 		opponentPeer = peer.PeerDescriptor{Addr: bc.OpponentAddr}
@@ -85,34 +128,50 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 		)
 
 		// Calculate damage (attacker's calculation is authoritative)
-		opponentPokemon := getOpponentPokemon(bc)
-		damage := bc.calculateDamage(
-			&bc.SelfPlayer.PokemonStruct,
-			opponentPokemon,
-			selectedMove,
-			useAttackBoost,
-			false,
-		)
-
-		// Calculate projected HP
-		projectedHP := opponentPokemon.HP - damage
-		if projectedHP < 0 {
-			projectedHP = 0
+		opponentPlayer := getOpponentPlayer(bc)
+		selectedMove := action.Move
+		defenseBoost := (*defenseMsg.MessageParams)["defense_boost"] == "true"
+		if defenseBoost {
+			boostsLeft := opponentPlayer.DefenseBoostsLeft(selectedMove.DamageCategory)
+			if action.Type != ActionAttack || *boostsLeft <= 0 || !bc.Game.Rules.CanBoost(selectedMove.DamageCategory) {
+				return fmt.Errorf("opponent used a defense boost it does not have")
+			}
+			*boostsLeft--
+			bracing := opponentPlayer.PokemonStruct.Name
+			if bc.Game.Rules.Doubles {
+				bracing = opponentPlayer.TrainerName + "'s side"
+			}
+			fmt.Printf("\n%s braces itself with a %s Defense boost!\n", bracing, categoryName(selectedMove.DamageCategory))
+		}
+		outcome, err := bc.Game.ResolveTurn(bc.SelfPlayer, opponentPlayer, action, defenseBoost)
+		if err != nil {
+			return err
+		}
+		actingPokemon, targetPokemon := bc.Game.TurnPokemon(bc.SelfPlayer, opponentPlayer, action, outcome)
+		actorName := actingPokemon.Name
+		if bc.Game.Rules.Doubles && action.Type == ActionAttack {
+			fmt.Printf("\n%s targets %s!\n", actorName, bc.Game.DescribeTarget(action, targetPokemon))
+		}
+		if action.Type != ActionAttack {
+			fmt.Printf("\n%s\n", describeAction(actorName, selectedMove, outcome))
 		}
 
-		// Update opponent's HP in our tracking
-		opponentPokemon.HP = projectedHP
+		// Update opponent's HP in our tracking and run end-of-turn field effects
+		bc.announceOutcome(bc.SelfPlayer, actorName, targetPokemon.Name, outcome)
+		fieldEvents := bc.Game.FinishTurn(bc.SelfPlayer, opponentPlayer, action, outcome)
+		showFieldEvents(fieldEvents)
 
-		// Send CALCULATION_REPORT with the damage
+		// Send CALCULATION_REPORT with the damage and end-of-turn state
 		seqNum = bc.ReliableConn.GetNextSequenceNumber()
 		calcMsg := bc.makeCalculationReport(
-			bc.SelfPlayer.PokemonStruct.Name,
-			selectedMove.Name,
-			bc.SelfPlayer.PokemonStruct.HP,
-			damage,
-			projectedHP,
+			actingPokemon,
+			targetPokemon,
+			selectedMove,
+			outcome,
+			fieldEvents,
 			seqNum,
 		)
+
 		calcMsgBytes := calcMsg.SerializeMessage()
 		// Send using proper communication mode handling
 		opponentPeer = peer.PeerDescriptor{Addr: bc.OpponentAddr}
@@ -126,9 +185,12 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 			},
 		)
 
-		// Log the attack
+		// Log the action
 		logEntry := fmt.Sprintf("%s used %s and dealt %d damage to opponent (HP: %d)",
-			bc.SelfPlayer.PokemonStruct.Name, selectedMove.Name, damage, projectedHP)
+			actorName, selectedMove.Name, outcome.Damage, outcome.DefenderHP)
+		if action.Type != ActionAttack {
+			logEntry = describeAction(actorName, selectedMove, outcome)
+		}
 		bc.Game.BattleLog = append(bc.Game.BattleLog, logEntry)
 
 		// Wait for CALCULATION_CONFIRM from defender
@@ -150,8 +212,8 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 
 	} else {
 		// Defender's turn
-		// Wait for ATTACK_ANNOUNCE
-		attackMsg, err := bc.waitForMessage(messages.AttackAnnounce)
+		// Wait for ATTACK_ANNOUNCE, no longer than the attacker's time allows
+		attackMsg, err := bc.waitForAnnounce()
 		if err != nil {
 			return err
 		}
@@ -164,9 +226,47 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 			},
 		)
 
+		// Charge the attacker's thinking time to our copy of the clock
+		if bc.Game.Clock.Enabled() {
+			timeUsed, _ := (*attackMsg.MessageParams)["time_used"].(int)
+			used := time.Duration(timeUsed) * time.Millisecond
+			if err := bc.Game.Clock.CheckReported(used); err != nil {
+				return fmt.Errorf("opponent's clock is invalid: %w", err)
+			}
+			bc.Game.Clock.Charge(bc.Game.CurrentTurn, used)
+		}
+
+		// Decide on a defense boost before answering
+		opponentPlayer := getOpponentPlayer(bc)
+		opponentPokemon := opponentPlayer.Active(bc.Game.ActingSlot)
+		opponentAction := bc.actionFromAnnounce(*attackMsg.MessageParams, opponentPokemon)
+		if bc.Game.Rules.Doubles && opponentAction.Slot != bc.Game.ActingSlot {
+			return fmt.Errorf("opponent acted with slot %d out of turn", opponentAction.Slot+1)
+		}
+		// The action must be in the committed bracket unless the Pokemon it
+		// was chosen for has since fainted and been replaced
+		if commit := bc.foeCommit; commit != nil {
+			bc.foeCommit = nil
+			if commit.pokemon == opponentPokemon.Name && opponentAction.Priority() != commit.priority {
+				return fmt.Errorf("opponent committed to priority %+d but announced a priority %+d %s", commit.priority, opponentAction.Priority(), opponentAction.Type)
+			}
+		}
+		move := opponentAction.Move
+		defenseBoost := false
+		if opponentAction.Type == ActionAttack {
+			if opponentAction.AttackBoost {
+				boostsLeft := opponentPlayer.AttackBoostsLeft(move.DamageCategory)
+				if *boostsLeft <= 0 || !bc.Game.Rules.CanBoost(move.DamageCategory) {
+					return fmt.Errorf("opponent used an attack boost it does not have")
+				}
+				*boostsLeft--
+			}
+			defenseBoost = bc.askDefenseBoost(opponentPlayer, move, opponentAction.AttackBoost)
+		}
+
 		// Send DEFENSE_ANNOUNCE
 		seqNum := bc.ReliableConn.GetNextSequenceNumber()
-		defenseMsg := messages.MakeDefenseAnnounce(seqNum)
+		defenseMsg := messages.MakeDefenseAnnounce(defenseBoost, seqNum)
 		opponentPeer = peer.PeerDescriptor{Addr: bc.OpponentAddr}
 		bc.sendMessage(defenseMsg.SerializeMessage(), opponentPeer)
 
@@ -196,23 +296,22 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 		damage := (*calcMsg.MessageParams)["damage_dealt"].(int)
 		defenderHPRemaining := (*calcMsg.MessageParams)["defender_hp_remaining"].(int)
 
-		// Verify calculation by doing our own calculation
-		moveName = (*attackMsg.MessageParams)["move_name"].(string)
-		opponentPokemon := getOpponentPokemon(bc)
-		move := bc.findMoveByName(opponentPokemon, moveName)
-		myDamageCalc := bc.calculateDamage(opponentPokemon, &bc.SelfPlayer.PokemonStruct, move, false, false)
-		myHPCalc := bc.SelfPlayer.PokemonStruct.HP - myDamageCalc
-		if myHPCalc < 0 {
-			myHPCalc = 0
+		// Verify calculation by doing our own calculation with the same boosts
+		outcome, err := bc.Game.ResolveTurn(opponentPlayer, bc.SelfPlayer, opponentAction, defenseBoost)
+		if err != nil {
+			return fmt.Errorf("opponent's %s is invalid: %w", opponentAction.Type, err)
 		}
+		myDamageCalc := outcome.Damage
+		myHPCalc := outcome.DefenderHP
+		_, targetPokemon := bc.Game.TurnPokemon(opponentPlayer, bc.SelfPlayer, opponentAction, outcome)
 
 		// Create our own calculation report for verification
 		myCalcMsg := bc.makeCalculationReport(
-			opponentPokemon.Name,
-			moveName,
-			opponentPokemon.HP,
-			myDamageCalc,
-			myHPCalc,
+			opponentPokemon,
+			targetPokemon,
+			move,
+			outcome,
+			nil,
 			0, // Temporary sequence number for verification
 		)
 
@@ -226,8 +325,13 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 			return bc.handleCalculationDiscrepancy(myCalcMsg)
 		}
 
-		// Apply damage to self
-		bc.SelfPlayer.PokemonStruct.HP = defenderHPRemaining
+		// Apply the action and run end-of-turn field effects
+		attackerName := opponentPokemon.Name
+		targetName := targetPokemon.Name
+		if bc.Game.Rules.Doubles && opponentAction.Type == ActionAttack {
+			targetName = bc.Game.DescribeTarget(opponentAction, targetPokemon)
+		}
+		fieldEvents := bc.Game.FinishTurn(opponentPlayer, bc.SelfPlayer, opponentAction, outcome)
 
 		// Send CALCULATION_CONFIRM
 		seqNum = bc.ReliableConn.GetNextSequenceNumber()
@@ -244,14 +348,20 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 		)
 
 		// Display what happened
-		moveName = (*attackMsg.MessageParams)["move_name"].(string)
-		attackerName := (*calcMsg.MessageParams)["attacker"].(string)
-		fmt.Printf("\n%s used %s! Dealt %d damage.\n", attackerName, moveName, damage)
+		fmt.Printf("\n%s\n", describeAction(attackerName, move, outcome))
+		if bc.Game.Rules.Doubles && opponentAction.Type == ActionAttack {
+			fmt.Printf("It targeted %s!\n", targetName)
+		}
+		bc.announceOutcome(opponentPlayer, attackerName, targetName, outcome)
+		showFieldEvents(fieldEvents)
 
 		// Log the event
 		logEntry := fmt.Sprintf("%s used %s and dealt %d damage to %s (HP: %d/%d)",
-			attackerName, moveName, damage, bc.SelfPlayer.PokemonStruct.Name,
-			bc.SelfPlayer.PokemonStruct.HP, bc.SelfPlayer.PokemonStruct.MaxHP)
+			attackerName, move.Name, damage, targetName,
+			targetPokemon.HP, targetPokemon.MaxHP)
+		if opponentAction.Type != ActionAttack {
+			logEntry = describeAction(attackerName, move, outcome)
+		}
 		bc.Game.BattleLog = append(bc.Game.BattleLog, logEntry)
 
 		// Switch turns
@@ -261,26 +371,268 @@ func (bc *BattleContext) ProcessTurn(selectedMove poke.Move, useAttackBoost bool
 	return nil
 }
 
+// makeActionAnnounce builds the ATTACK_ANNOUNCE for a turn action.
+func makeActionAnnounce(action TurnAction, seqNum int) messages.Message {
+	switch action.Type {
+	case ActionProtect:
+		return messages.MakeProtectAnnounce(seqNum)
+	case ActionItem:
+		return messages.MakeItemAnnounce(action.ItemName, seqNum)
+	case ActionSwitch:
+		return messages.MakeSwitchAnnounce(action.SwitchTo, seqNum)
+	}
+	return messages.MakeAttackAnnounce(action.Move.Name, action.AttackBoost, seqNum)
+}
+
+// actionFromAnnounce reads the opponent's turn action from an ATTACK_ANNOUNCE.
+// Announcements without an action field are attacks.
+func (bc *BattleContext) actionFromAnnounce(params map[string]any, attacker *poke.Pokemon) TurnAction {
+	action := TurnAction{Type: ActionAttack}
+	if actionType, ok := params["action"].(string); ok {
+		action.Type = ActionType(actionType)
+	}
+
+	switch action.Type {
+	case ActionItem:
+		action.ItemName, _ = params["item_name"].(string)
+	case ActionSwitch:
+		action.SwitchTo, _ = params["switch_to"].(int)
+	case ActionAttack:
+		moveName, _ := params["move_name"].(string)
+		action.Move = bc.findMoveByName(attacker, moveName)
+		action.AttackBoost = params["attack_boost"] == "true"
+	}
+
+	// Doubles announcements say which slot acts and what it targets
+	action.Slot, _ = params["user_slot"].(int)
+	action.Target, _ = params["target"].(string)
+	action.TargetSlot, _ = params["target_slot"].(int)
+	return action
+}
+
+// describeAction summarizes a resolved action for display and the battle log.
+func describeAction(user string, move poke.Move, outcome AttackOutcome) string {
+	switch outcome.Action {
+	case ActionItem:
+		return outcome.DescribeItem(user)
+	case ActionProtect:
+		return user + " is protecting itself!"
+	case ActionSwitch:
+		return user + " is switching out!"
+	}
+	if outcome.Protected {
+		return fmt.Sprintf("%s used %s, but it was blocked!", user, move.Name)
+	}
+	if move.IsDamaging() {
+		return fmt.Sprintf("%s used %s! Dealt %d damage.", user, move.Name, outcome.Damage)
+	}
+	return fmt.Sprintf("%s used %s!", user, move.Name)
+}
+
 // Helper functions
 
-func (bc *BattleContext) calculateDamage(
-	attacker *poke.Pokemon,
-	defender *poke.Pokemon,
-	move poke.Move,
-	attackBoost bool,
-	defenseBoost bool,
-) int {
-	damage := CalculateDamage(attacker, defender, move, attackBoost, defenseBoost, bc.Game.RNG)
-	return damage
+// askDefenseBoost asks the local player whether to spend a defense boost
+// against an announced move whose category the ruleset lets be boosted.
+// No answer within DefenseBoostTimeout counts as "no". A decider answers
+// for itself. Spent boosts are deducted here.
+func (bc *BattleContext) askDefenseBoost(attacker *player.Player, move poke.Move, attackBoost bool) bool {
+	boostsLeft := bc.SelfPlayer.DefenseBoostsLeft(move.DamageCategory)
+	if !move.IsDamaging() || !bc.Game.Rules.CanBoost(move.DamageCategory) || *boostsLeft <= 0 {
+		return false
+	}
+
+	category := categoryName(move.DamageCategory)
+	if bc.Decider != nil {
+		if !bc.Decider.UseDefenseBoost(bc.Game, bc.SelfPlayer, attacker, move, attackBoost) {
+			return false
+		}
+		*boostsLeft--
+		fmt.Printf("[%s] %s Defense boost used! (%d left)\n", bc.Decider.Name(), category, *boostsLeft)
+		return true
+	}
+
+	fmt.Printf("\nOpponent is using %s", move.Name)
+	if attackBoost {
+		fmt.Printf(" with a %s Attack boost", category)
+	}
+	fmt.Printf("! Use a %s Defense boost? (y/n, %d left, %ds to answer): \n",
+		category, *boostsLeft, int(DefenseBoostTimeout.Seconds()))
+
+	answer, ok := bc.awaitAnswer(DefenseBoostTimeout)
+	if !ok {
+		fmt.Println("No answer in time. No boost used.")
+		return false
+	}
+	if strings.ToLower(answer) != "y" {
+		return false
+	}
+
+	*boostsLeft--
+	fmt.Printf("%s Defense boost used! (%d left)\n", category, *boostsLeft)
+	return true
+}
+
+// categoryName capitalizes a damage category for display, e.g. "Special".
+func categoryName(category string) string {
+	if category == "" {
+		return ""
+	}
+	return strings.ToUpper(category[:1]) + category[1:]
+}
+
+// awaitAnswer waits for the next input line routed through DeliverInput.
+// Returns false if nothing arrives before the timeout.
+func (bc *BattleContext) awaitAnswer(timeout time.Duration) (string, bool) {
+	answers := make(chan string, 1)
+	bc.answerMu.Lock()
+	bc.pendingAnswer = answers
+	bc.answerMu.Unlock()
+
+	defer func() {
+		bc.answerMu.Lock()
+		bc.pendingAnswer = nil
+		bc.answerMu.Unlock()
+	}()
+
+	select {
+	case answer := <-answers:
+		return answer, true
+	case <-time.After(timeout):
+		return "", false
+	}
+}
+
+// DeliverInput hands an input line to an open question, if there is one.
+// Returns true if the line was consumed as an answer.
+func (bc *BattleContext) DeliverInput(input string) bool {
+	bc.answerMu.Lock()
+	defer bc.answerMu.Unlock()
+
+	if bc.pendingAnswer == nil {
+		return false
+	}
+	bc.pendingAnswer <- input
+	bc.pendingAnswer = nil
+	return true
+}
+
+// showFieldEvents prints weather, terrain, status and held item events for the local player.
+func showFieldEvents(events []string) {
+	for _, event := range events {
+		fmt.Printf("[Field] %s\n", event)
+	}
+}
+
+// announceOutcome shows friendship effects of an attack to the local player.
+func (bc *BattleContext) announceOutcome(attacker *player.Player, attackerName string, defenderName string, outcome AttackOutcome) {
+	if outcome.Critical {
+		if attacker == bc.SelfPlayer && attacker.Profile != nil && attackerName == attacker.PokemonStruct.Name {
+			poke.ShowCriticalHitMessage(attacker.Profile)
+		} else {
+			fmt.Printf("\nA critical hit from %s!\n", attackerName)
+		}
+	}
+	if effects := outcome.DescribeEffects(attackerName); effects != "" {
+		fmt.Printf("\n%s\n", effects)
+	}
+	if outcome.Endured {
+		fmt.Printf("\n%s endured the hit out of friendship for its trainer!\n", defenderName)
+	}
+	for _, spread := range outcome.Spread {
+		fmt.Printf("   %d damage to slot %d\n", spread.Damage, spread.TargetSlot+1)
+	}
+}
+
+// waitForAnnounce waits for the opponent's ATTACK_ANNOUNCE. When the match
+// is timed it gives up at the clock's deadline and returns
+// "opponent_out_of_time".
+func (bc *BattleContext) waitForAnnounce() (*messages.Message, error) {
+	return bc.waitUntil(messages.AttackAnnounce, bc.Game.Clock.Deadline())
+}
+
+// waitUntil waits for a message of msgType. When the match is timed it
+// gives up at deadline and returns "opponent_out_of_time".
+func (bc *BattleContext) waitUntil(msgType string, deadline time.Time) (*messages.Message, error) {
+	if !bc.Game.Clock.Enabled() {
+		return bc.waitForMessage(msgType)
+	}
+
+	conn := bc.SelfPlayer.Peer.Conn
+	conn.SetReadDeadline(deadline)
+	defer conn.SetReadDeadline(time.Time{})
+
+	msg, err := bc.waitForMessage(msgType)
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return nil, fmt.Errorf("opponent_out_of_time")
+	}
+	return msg, err
+}
+
+// commitRound sends ROUND_COMMIT with the priority bracket of our action
+// for the duel round, waits for the opponent's, and opens the round. Both
+// trainers chose at once, so both thinking times are charged here.
+func (bc *BattleContext) commitRound(action TurnAction) error {
+	game := bc.Game
+	selfSide, foeSide := game.SideOf(bc.SelfPlayer), otherSide(game.SideOf(bc.SelfPlayer))
+	seqNum := bc.ReliableConn.GetNextSequenceNumber()
+	commitMsg := messages.MakeRoundCommit(action.Priority(), seqNum)
+	if game.Clock.Enabled() {
+		messages.AddTimeUsed(commitMsg, int(action.TimeUsed.Milliseconds()))
+		game.Clock.Charge(selfSide, action.TimeUsed)
+	}
+	bc.sendMessage(commitMsg.SerializeMessage(), peer.PeerDescriptor{Addr: bc.OpponentAddr})
+
+	// Verbose logging for ROUND_COMMIT
+	netio.VerboseEventLog(
+		"PokeProtocol: Sent ROUND_COMMIT to opponent",
+		&netio.LogOptions{
+			MessageParams: commitMsg.MessageParams,
+		},
+	)
+
+	foeMsg, err := bc.waitUntil(messages.RoundCommit, game.Clock.DeadlineFor(foeSide))
+	if err != nil {
+		return err
+	}
+	params := *foeMsg.MessageParams
+	foePriority, _ := params["priority"].(int)
+	if game.Clock.Enabled() {
+		timeUsed, _ := params["time_used"].(int)
+		used := time.Duration(timeUsed) * time.Millisecond
+		if err := game.Clock.CheckReportedFor(foeSide, used, bc.arrived); err != nil {
+			return fmt.Errorf("opponent's clock is invalid: %w", err)
+		}
+		game.Clock.Charge(foeSide, used)
+	}
+
+	opponentPlayer := getOpponentPlayer(bc)
+	bc.foeCommit = &roundCommit{priority: foePriority, pokemon: opponentPlayer.PokemonStruct.Name}
+	if selfSide == "host" {
+		game.OpenRound(action.Priority(), foePriority)
+	} else {
+		game.OpenRound(foePriority, action.Priority())
+	}
+	return nil
 }
 
 func (bc *BattleContext) waitForMessage(msgType string) (*messages.Message, error) {
 	buf := make([]byte, 100000) // Increased from 64KB to 100KB for large estickers
 	for {
+		if len(bc.stashed) > 0 {
+			stashed := bc.stashed[0]
+			bc.stashed = bc.stashed[1:]
+			bc.arrived = stashed.arrived
+			if msg, ok, err := bc.handleBattleMessage(stashed.msg, msgType, bc.OpponentAddr.String()); ok {
+				return msg, err
+			}
+			continue
+		}
+
 		n, addr, err := bc.SelfPlayer.Peer.Conn.ReadFromUDP(buf)
 		if err != nil {
 			return nil, err
 		}
+		bc.arrived = time.Now()
 
 		fmt.Printf("DEBUG: Received UDP packet from %s, size: %d bytes\n", addr.String(), n)
 
@@ -381,69 +733,134 @@ func (bc *BattleContext) waitForMessage(msgType string) (*messages.Message, erro
 			continue // Keep waiting for the actual battle message
 		}
 
-		// Check for GAME_OVER message - opponent's pokemon fainted
-		if msg.MessageType == messages.GameOver {
-			// Verbose logging for received GAME_OVER
-			netio.VerboseEventLog(
-				"PokeProtocol: Received GAME_OVER from opponent",
-				&netio.LogOptions{
-					MessageParams: msg.MessageParams,
-					MS:            addr.String(),
-				},
-			)
-
-			bc.Game.State = StateGameOver
-			return msg, fmt.Errorf("opponent_fainted")
+		if msg, ok, err := bc.handleBattleMessage(msg, msgType, addr.String()); ok {
+			return msg, err
 		}
+	}
+}
 
-		if msg.MessageType == msgType {
-			// Verbose logging for received battle message
-			netio.VerboseEventLog(
-				fmt.Sprintf("PokeProtocol: Received %s from opponent", msgType),
-				&netio.LogOptions{
-					MessageParams: msg.MessageParams,
-					MS:            addr.String(),
-				},
-			)
+// handleBattleMessage checks a battle message for waitForMessage: GAME_OVER
+// ends the wait with the reason as the error, and a message of msgType ends
+// it. ok is false for any other message. from is the sender, for logging.
+func (bc *BattleContext) handleBattleMessage(msg *messages.Message, msgType string, from string) (*messages.Message, bool, error) {
+	// Check for GAME_OVER message - opponent's pokemon fainted
+	if msg.MessageType == messages.GameOver {
+		// Verbose logging for received GAME_OVER
+		netio.VerboseEventLog(
+			"PokeProtocol: Received GAME_OVER from opponent",
+			&netio.LogOptions{
+				MessageParams: msg.MessageParams,
+				MS:            from,
+			},
+		)
 
-			return msg, nil
+		bc.Game.State = StateGameOver
+		switch (*msg.MessageParams)["reason"] {
+		case messages.ReasonForfeit:
+			return msg, true, fmt.Errorf("opponent_forfeited")
+		case messages.ReasonTimeout:
+			return msg, true, fmt.Errorf("opponent_timed_out")
 		}
+		return msg, true, fmt.Errorf("opponent_fainted")
+	}
+
+	if msg.MessageType == msgType {
+		// Verbose logging for received battle message
+		netio.VerboseEventLog(
+			fmt.Sprintf("PokeProtocol: Received %s from opponent", msgType),
+			&netio.LogOptions{
+				MessageParams: msg.MessageParams,
+				MS:            from,
+			},
+		)
+
+		return msg, true, nil
 	}
+	return nil, false, nil
 }
 
 func (bc *BattleContext) makeCalculationReport(
-	attackerName string,
-	moveName string,
-	attackerHP int,
-	damage int,
-	defenderHP int,
+	attacker *poke.Pokemon,
+	defender *poke.Pokemon,
+	move poke.Move,
+	outcome AttackOutcome,
+	fieldEvents []string,
 	seqNum int,
 ) messages.Message {
-	typeEff := 1.0 // Calculate type effectiveness
+	// Describe the defender as it will be after the attack
+	defenderAfter := *defender
+	defenderAfter.HP = outcome.DefenderHP
+
 	statusMsg := GetStatusMessage(
-		&bc.SelfPlayer.PokemonStruct,
-		getOpponentPokemon(bc),
-		poke.Move{Name: moveName},
-		damage,
-		typeEff,
+		attacker,
+		&defenderAfter,
+		move,
+		outcome.Damage,
+		outcome.TypeEffectiveness,
 	)
+	if outcome.Action != ActionAttack {
+		statusMsg = describeAction(attacker.Name, move, outcome)
+		move.Name = string(outcome.Action)
+		if outcome.ItemUsed != "" {
+			move.Name = outcome.ItemUsed
+		}
+	}
+	if outcome.Critical {
+		statusMsg = "A critical hit! " + statusMsg
+	}
+	if effects := outcome.DescribeEffects(attacker.Name); effects != "" {
+		statusMsg += " " + effects
+	}
+	if outcome.Endured {
+		statusMsg += " " + defender.Name + " endured the hit!"
+	}
 
-	return messages.MakeCalculationReport(
-		attackerName,
-		moveName,
-		attackerHP,
-		damage,
-		defenderHP,
+	report := messages.MakeCalculationReport(
+		attacker.Name,
+		move.Name,
+		attacker.HP,
+		outcome.Damage,
+		outcome.DefenderHP,
 		statusMsg,
+		defender.HP,
+		bc.Game.Field.String(),
+		strings.Join(fieldEvents, " "),
 		seqNum,
 	)
+	if bc.Game.Rules.HostOrdered() {
+		messages.AddStandings(report, bc.Game.Standings())
+		if bc.Game.Rules.Raid() {
+			boss := &bc.Game.Players[bc.Game.BossSeat()].PokemonStruct
+			messages.AddBossState(report, boss.Name, boss.HP, boss.MaxHP)
+		}
+	} else {
+		host, joiner := &bc.Game.Host.PokemonStruct, &bc.Game.Joiner.PokemonStruct
+		messages.AddActiveState(report, host.Name, host.HP, host.MaxHP, joiner.Name, joiner.HP, joiner.MaxHP)
+	}
+	scripted := make([]string, len(outcome.Effects))
+	for i, effect := range outcome.Effects {
+		scripted[i] = effect.String()
+	}
+	messages.AddEffectBreakdown(report, outcome.Hits, outcome.Recoil, outcome.ItemRecoil, outcome.Drained, ActionPriority(outcome.Action, move), scripted)
+	if bc.Game.Rules.Doubles {
+		target := defender.Name
+		if len(outcome.Spread) > 0 {
+			target = "both foes"
+		}
+		hostPartner, joinerPartner := &bc.Game.Host.Partner, &bc.Game.Joiner.Partner
+		messages.AddDoublesState(report, target, outcome.SpreadDamage(),
+			hostPartner.Name, hostPartner.HP, hostPartner.MaxHP,
+			joinerPartner.Name, joinerPartner.HP, joinerPartner.MaxHP)
+	}
+	return report
 }
 
+// switchTurn passes the turn. A duel round's opener is announced once both
+// trainers have committed to it.
 func (bc *BattleContext) switchTurn() {
-	if bc.Game.CurrentTurn == "host" {
-		bc.Game.CurrentTurn = "joiner"
-	} else {
-		bc.Game.CurrentTurn = "host"
+	bc.Game.AdvanceTurn()
+	if bc.Game.Rules.Doubles && bc.Game.TurnsThisRound == 0 {
+		fmt.Printf("\nNew round! Speed order: %s\n", bc.Game.DescribeOrder())
 	}
 }
 
@@ -465,8 +882,19 @@ func (bc *BattleContext) verifyCalculations(msg1 messages.Message, msg2 *message
 	params1 := *msg1.MessageParams
 	params2 := *msg2.MessageParams
 
-	return params1["damage_dealt"] == params2["damage_dealt"] &&
-		params1["defender_hp_remaining"] == params2["defender_hp_remaining"]
+	if params1["damage_dealt"] != params2["damage_dealt"] ||
+		params1["defender_hp_remaining"] != params2["defender_hp_remaining"] {
+		return false
+	}
+
+	// Effect breakdowns are compared as text, since a single hit
+	// deserializes as a number while several arrive as a string
+	for _, key := range []string{"hit_damage", "recoil", "item_recoil", "drained", "scripted_effects", "spread_damage"} {
+		if fmt.Sprint(params1[key]) != fmt.Sprint(params2[key]) {
+			return false
+		}
+	}
+	return true
 }
 
 // handleCalculationDiscrepancy sends a resolution request when calculations don't match
@@ -506,11 +934,11 @@ func (bc *BattleContext) findMoveByName(pokemon *poke.Pokemon, moveName string)
 	return poke.Move{Name: moveName, BasePower: 50, Type: "normal", DamageCategory: poke.Physical}
 }
 
-func getOpponentPokemon(bc *BattleContext) *poke.Pokemon {
+func getOpponentPlayer(bc *BattleContext) *player.Player {
 	if bc.IsHost {
 		// Opponent is joiner - we need to track their pokemon separately
 		// This is a simplified version; in a real implementation, you'd track opponent state
-		return &bc.Game.Joiner.PokemonStruct
+		return bc.Game.Joiner
 	}
-	return &bc.Game.Host.PokemonStruct
+	return bc.Game.Host
 }
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"net"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
 	"github.com/zrygan/pokemonbattler/messages"
 	"github.com/zrygan/pokemonbattler/netio"
 	"github.com/zrygan/pokemonbattler/peer"
@@ -44,17 +46,21 @@ var Stickers = map[string]string{
 	"/hit":        "[HIT!]",
 }
 
-// RunBattle starts and manages the complete battle loop.
+// RunBattle starts and manages the complete battle loop. With a decider,
+// such as a bot, it plays selfPlayer and nothing is read from the terminal
+// but chat.
 func RunBattle(
 	selfPlayer *player.Player,
 	opponentPlayer *player.Player,
 	seed int,
 	commMode string,
+	r rules.Ruleset,
 	isHost bool,
 	spectators []peer.PeerDescriptor,
+	decider Decider,
 ) {
 	// Initialize game
-	game := NewGame(seed, commMode)
+	game := NewGame(seed, commMode, r)
 	if isHost {
 		game.Host = selfPlayer
 		game.Joiner = opponentPlayer
@@ -82,6 +88,7 @@ func RunBattle(
 		OpponentAddr: opponentPlayer.Peer.Addr,
 		ReliableConn: reliableConn,
 		IsHost:       isHost,
+		Decider:      decider,
 	}
 
 	// Set initial state
@@ -94,72 +101,196 @@ func RunBattle(
 		poke.ShowPreBattleMessage(selfPlayer.Profile)
 	}
 
-	fmt.Printf("Your Pokemon: %s (HP: %d/%d)\n",
-		selfPlayer.PokemonStruct.Name,
-		selfPlayer.PokemonStruct.HP,
-		selfPlayer.PokemonStruct.MaxHP)
-	fmt.Println("\nAvailable Moves:")
-	for i, move := range selfPlayer.PokemonStruct.Moves {
-		fmt.Printf("%d. %s (Power: %.0f, Type: %s, Category: %s)\n",
-			i+1, move.Name, move.BasePower, move.Type, move.DamageCategory)
+	showBattlers(selfPlayer, opponentPlayer)
+
+	// Entry abilities set weather or terrain, host's Pokemon first
+	showFieldEvents(game.Field.ApplyEntryAbility(&game.Host.PokemonStruct))
+	showFieldEvents(game.Field.ApplyEntryAbility(&game.Joiner.PokemonStruct))
+
+	// Doubles sends out each side's first reserve as a partner
+	if r.Doubles {
+		showFieldEvents(game.StartDoubles())
+		if selfPlayer.HasPartner {
+			fmt.Printf("Your partner: %s Lv. %d (HP: %d/%d)\n",
+				selfPlayer.Partner.Name, selfPlayer.Partner.Level, selfPlayer.Partner.HP, selfPlayer.Partner.MaxHP)
+		}
+		if opponentPlayer.HasPartner {
+			fmt.Printf("Opponent's partner: %s Lv. %d (HP: %d/%d)\n",
+				opponentPlayer.Partner.Name, opponentPlayer.Partner.Level, opponentPlayer.Partner.HP, opponentPlayer.Partner.MaxHP)
+		}
+		fmt.Printf("Speed order: %s\n", game.DescribeOrder())
 	}
-	fmt.Printf("Special Attack Boosts: %d\n", selfPlayer.SpecialAttackUsesLeft)
-	fmt.Printf("Special Defense Boosts: %d\n", selfPlayer.SpecialDefenseUsesLeft)
+
+	showBattleRules(game, selfPlayer, opponentPlayer)
 	fmt.Println("\nTip: Type 'chat <message>', use stickers like '/gg', or send image files with 'esticker <filepath>'!")
 	fmt.Println("Stickers: /smile /laugh /cool /angry /sad /love /fire /star /thumbsup /hi /gg /nice /wow /ouch /lucky /attack /defend /heal /critical /miss /hit")
 	fmt.Println("You can chat anytime during the battle, even during opponent's turn!")
 	fmt.Println()
 
-	// Start input listener for non-blocking input
+	// Start input listener for non-blocking input; a decider may chat too
 	inputChan := netio.StartInputListener()
+	observer := observerOf(decider)
+	deciderChat := chatOf(decider)
+
+	// Keep spectators up to date on the clock
+	clockDone := make(chan struct{})
+	if isHost && game.Clock.Enabled() {
+		go game.broadcastClock(clockDone)
+	}
 
 	// Battle loop
 	turnNumber := 1
 	winner := ""
 	loser := ""
+	draw := false
+	var plan plannedAction // Our action for the duel round, chosen as it opened
+
+	// chooseAction gets our action from the decider or the terminal, with a
+	// target in doubles and whether to spend an attack boost, which is spent
+	// when the action is played. planning is set as a duel round opens,
+	// before the order is known. ok is false if the battle ended instead,
+	// by forfeit or by running out of time.
+	chooseAction := func(planning bool) (action TurnAction, ok bool) {
+		// In doubles the menu belongs to the Pokemon whose turn it is
+		actor := selfPlayer
+		if r.Doubles {
+			actor = SlotView(selfPlayer, game.ActingSlot)
+			fmt.Printf("Your turn! (slot %d: %s)\n", game.ActingSlot+1, actor.PokemonStruct.Name)
+		} else {
+			fmt.Println("Your turn!")
+		}
+		showActionMenu(actor, true)
+		if game.Clock.Enabled() {
+			fmt.Printf("Time: %s\n", game.Clock.Describe())
+		}
 
-	for game.State != StateGameOver {
-		fmt.Printf("\n--- Turn %d ---\n", turnNumber)
+		// Get the turn action using non-blocking input, unless a decider plays
+		chosen := false
+		timedOut := false
+		expired := game.Clock.Expired()
+		if decider != nil {
+			foe := opponentPlayer
+			if r.Doubles {
+				foe = SlotView(opponentPlayer, foeTarget(opponentPlayer, 0))
+			}
+			if planning {
+				var err error
+				if action, err = planAction(game, decider, actor, foe); err != nil {
+					fmt.Printf("[%s] %v. Using the default action.\n", decider.Name(), err)
+				}
+			} else {
+				action = decider.ChooseAction(game, actor, foe)
+			}
+			fmt.Printf("[%s] %s\n", decider.Name(), action.Describe(actor.PokemonStruct.Name))
+			chosen = true
+		}
+		for !chosen {
+			select {
+			case <-expired:
+				timedOut = true
+				chosen = true
+			case input := <-inputChan:
+				// Check if it's a chat command
+				if len(input) > 5 && input[:5] == "chat " {
+					chatText := input[5:]
+					sendChatMessage(battleCtx, chatText)
+					continue
+				}
 
-		isMyTurn := (isHost && game.CurrentTurn == "host") ||
-			(!isHost && game.CurrentTurn == "joiner")
+				// Check if it's an esticker command
+				if strings.HasPrefix(input, "esticker ") {
+					sendChatMessage(battleCtx, input)
+					continue
+				}
 
-		if isMyTurn {
-			fmt.Println("Your turn!")
-			fmt.Println("Select a move (enter number), 'chat <message>', stickers (/gg), or 'esticker <filepath>': ")
+				// Check if it's a sticker
+				if strings.HasPrefix(input, "/") {
+					sendChatMessage(battleCtx, input)
+					continue
+				}
 
-			// Get move selection using non-blocking input
-			moveIndex := -1
-			for moveIndex < 0 || moveIndex >= len(selfPlayer.PokemonStruct.Moves) {
-				select {
-				case input := <-inputChan:
-					// Check if it's a chat command
-					if len(input) > 5 && input[:5] == "chat " {
-						chatText := input[5:]
-						sendChatMessage(battleCtx, chatText)
-						continue
+				var problem string
+				action, problem = parseTurnAction(input, actor)
+				if problem != "" {
+					fmt.Println(problem)
+					continue
+				}
+				chosen = true
+			default:
+				// Check for incoming network messages (chat from opponent)
+				buf := make([]byte, 100000) // Increased for large estickers
+				selfPlayer.Peer.Conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+				n, addr, err := selfPlayer.Peer.Conn.ReadFromUDP(buf)
+				if err == nil {
+					msg := messages.DeserializeMessage(buf[:n])
+					if msg.MessageType == messages.ChatMessage {
+						processIncomingChat(msg, isHost, battleCtx, buf[:n], addr)
+					} else {
+						battleCtx.stash(msg)
 					}
+				}
+				selfPlayer.Peer.Conn.SetReadDeadline(time.Time{}) // Clear deadline
+				time.Sleep(10 * time.Millisecond)                 // Small delay to prevent busy waiting
+			}
+		}
 
-					// Check if it's an esticker command
-					if strings.HasPrefix(input, "esticker ") {
-						sendChatMessage(battleCtx, input)
-						continue
-					}
+		// Running out of time forfeits or plays the default action
+		if timedOut {
+			if r.TimeoutAction == rules.TimeoutForfeit {
+				game.State = StateGameOver
+				fmt.Println("\nTime's up! You ran out of time and lose the match.")
+				winner = opponentPlayer.Peer.Name
+				loser = selfPlayer.Peer.Name
+				game.BattleLog = append(game.BattleLog, fmt.Sprintf("%s ran out of time!", selfPlayer.Peer.Name))
+				game.BattleLog = append(game.BattleLog, fmt.Sprintf("Winner: %s", winner))
+				sendGameOver(battleCtx, opponentPlayer, winner, loser, messages.ReasonTimeout)
+				return action, false
+			}
+			action = DefaultAction(actor)
+			fmt.Printf("\nTime's up! %s.\n", action.Describe(actor.PokemonStruct.Name))
+		}
 
-					// Check if it's a sticker
-					if strings.HasPrefix(input, "/") {
-						sendChatMessage(battleCtx, input)
-						continue
-					}
+		// Forfeiting ends the match without a turn exchange
+		if action.Type == ActionForfeit {
+			game.State = StateGameOver
+			fmt.Println("\nYou forfeited the match.")
+			winner = opponentPlayer.Peer.Name
+			loser = selfPlayer.Peer.Name
+			game.BattleLog = append(game.BattleLog, fmt.Sprintf("%s forfeited!", selfPlayer.Peer.Name))
+			game.BattleLog = append(game.BattleLog, fmt.Sprintf("Winner: %s", winner))
+			sendGameOver(battleCtx, opponentPlayer, winner, loser, messages.ReasonForfeit)
+			return action, false
+		}
 
-					idx, err := strconv.Atoi(input)
-					if err == nil && idx > 0 && idx <= len(selfPlayer.PokemonStruct.Moves) {
-						moveIndex = idx - 1
-					} else {
-						fmt.Println("Invalid selection. Please try again.")
+		// Doubles moves pick a target; a timeout aims at the first foe
+		if r.Doubles {
+			action.Slot = game.ActingSlot
+			if action.Type == ActionAttack {
+				action.Target, action.TargetSlot = TargetFoe, foeTarget(opponentPlayer, 0)
+				if !timedOut && decider == nil {
+					action.Target, action.TargetSlot = chooseTarget(battleCtx, opponentPlayer, inputChan, expired)
+				}
+			}
+		}
+
+		// Ask if they want to use a boost
+		selectedMove := action.Move
+		boostsLeft := selfPlayer.AttackBoostsLeft(selectedMove.DamageCategory)
+		if !timedOut && decider == nil && action.Type == ActionAttack && selectedMove.IsDamaging() && r.CanBoost(selectedMove.DamageCategory) && *boostsLeft > 0 {
+			fmt.Printf("Use a %s Attack boost? (y/n, %d left): \n", categoryName(selectedMove.DamageCategory), *boostsLeft)
+			boostSelected := false
+			for !boostSelected {
+				select {
+				case <-expired:
+					fmt.Println("Time's up! No boost used.")
+					boostSelected = true
+				case boostInput := <-inputChan:
+					if boostInput == "y" || boostInput == "Y" {
+						action.AttackBoost = true
 					}
+					boostSelected = true
 				default:
-					// Check for incoming network messages (chat from opponent)
+					// Check for incoming network messages
 					buf := make([]byte, 100000) // Increased for large estickers
 					selfPlayer.Peer.Conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
 					n, addr, err := selfPlayer.Peer.Conn.ReadFromUDP(buf)
@@ -167,51 +298,91 @@ func RunBattle(
 						msg := messages.DeserializeMessage(buf[:n])
 						if msg.MessageType == messages.ChatMessage {
 							processIncomingChat(msg, isHost, battleCtx, buf[:n], addr)
+						} else {
+							battleCtx.stash(msg)
 						}
 					}
-					selfPlayer.Peer.Conn.SetReadDeadline(time.Time{}) // Clear deadline
-					time.Sleep(10 * time.Millisecond)                 // Small delay to prevent busy waiting
+					selfPlayer.Peer.Conn.SetReadDeadline(time.Time{})
+					time.Sleep(10 * time.Millisecond)
 				}
 			}
-			selectedMove := selfPlayer.PokemonStruct.Moves[moveIndex]
-
-			// Ask if they want to use a boost
-			useBoost := false
-			if selectedMove.DamageCategory == poke.Special && selfPlayer.SpecialAttackUsesLeft > 0 {
-				fmt.Println("Use a Special Attack boost? (y/n): ")
-				boostSelected := false
-				for !boostSelected {
-					select {
-					case boostInput := <-inputChan:
-						if boostInput == "y" || boostInput == "Y" {
-							useBoost = true
-							selfPlayer.SpecialAttackUsesLeft--
-						}
-						boostSelected = true
-					default:
-						// Check for incoming network messages
-						buf := make([]byte, 100000) // Increased for large estickers
-						selfPlayer.Peer.Conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
-						n, addr, err := selfPlayer.Peer.Conn.ReadFromUDP(buf)
-						if err == nil {
-							msg := messages.DeserializeMessage(buf[:n])
-							if msg.MessageType == messages.ChatMessage {
-								processIncomingChat(msg, isHost, battleCtx, buf[:n], addr)
-							}
-						}
-						selfPlayer.Peer.Conn.SetReadDeadline(time.Time{})
-						time.Sleep(10 * time.Millisecond)
-					}
+		}
+
+		// Charge the thinking time, never more than the turn allowed
+		action.TimeUsed = min(game.Clock.Elapsed(), game.Clock.Allowance())
+		return action, true
+	}
+
+	for game.State != StateGameOver {
+		fmt.Printf("\n--- Turn %d ---\n", turnNumber)
+		fmt.Printf("Field: %s\n", game.Field)
+		game.Clock.StartTurn(game.CurrentTurn)
+		if observer != nil {
+			observer.ObserveTurn(game, selfPlayer, opponentPlayer, turnNumber)
+		}
+
+		// A duel round opens with both trainers choosing their action at once.
+		// Only its priority bracket is sent, and the higher bracket goes first
+		if !r.Doubles && game.TurnsThisRound == 0 {
+			game.Clock.StartTurn(game.SideOf(selfPlayer))
+			action, ok := chooseAction(true)
+			if !ok {
+				break
+			}
+			plan = plannedAction{action: action, pokemon: selfPlayer.PokemonStruct.Name}
+			if err := battleCtx.commitRound(action); err != nil {
+				switch err.Error() {
+				case "opponent_forfeited":
+					opponentForfeited(game, selfPlayer, opponentPlayer, &winner, &loser)
+				case "opponent_timed_out":
+					opponentTimedOut(game, selfPlayer, opponentPlayer, &winner, &loser)
+				case "opponent_out_of_time":
+					// The opponent never committed; declare the timeout ourselves
+					opponentTimedOut(game, selfPlayer, opponentPlayer, &winner, &loser)
+					sendGameOver(battleCtx, opponentPlayer, winner, loser, messages.ReasonTimeout)
+				default:
+					fmt.Printf("Error while opening the round: %v\n", err)
+				}
+				break
+			}
+			game.Clock.StartTurn(game.CurrentTurn)
+			showOpener(game.Side(game.CurrentTurn))
+		}
+
+		isMyTurn := (isHost && game.CurrentTurn == "host") ||
+			(!isHost && game.CurrentTurn == "joiner")
+
+		if isMyTurn {
+			var action TurnAction
+			if !r.Doubles && plan.pokemon == selfPlayer.PokemonStruct.Name &&
+				checkAction(game, selfPlayer, opponentPlayer, plan.action) == nil {
+				action = plan.action
+				action.TimeUsed = 0 // Charged with ROUND_COMMIT
+			} else {
+				// The planned action no longer fits, as after a faint, so choose again
+				var ok bool
+				if action, ok = chooseAction(false); !ok {
+					break
 				}
 			}
+			if action.AttackBoost {
+				*selfPlayer.AttackBoostsLeft(action.Move.DamageCategory)--
+			}
 
-			// Log the attack
-			logEntry := fmt.Sprintf("%s used %s", selfPlayer.PokemonStruct.Name, selectedMove.Name)
-			game.BattleLog = append(game.BattleLog, logEntry)
+			// Log the action
+			actor := selfPlayer
+			if r.Doubles {
+				actor = SlotView(selfPlayer, game.ActingSlot)
+			}
+			game.BattleLog = append(game.BattleLog, action.Describe(actor.PokemonStruct.Name))
 
 			// Process the turn
-			err := battleCtx.ProcessTurn(selectedMove, useBoost)
+			err := battleCtx.ProcessTurn(action)
 			if err != nil {
+				if err.Error() == "opponent_forfeited" {
+					opponentForfeited(game, selfPlayer, opponentPlayer, &winner, &loser)
+					break
+				}
 				if err.Error() == "opponent_fainted" {
 					// Opponent's Pokemon fainted - we won!
 					game.State = StateGameOver
@@ -226,6 +397,7 @@ func RunBattle(
 					gameOverMsg := messages.MakeGameOver(
 						winner,
 						loser,
+						messages.ReasonFainted,
 						seqNum,
 					)
 					gameOverBytes := gameOverMsg.SerializeMessage()
@@ -254,6 +426,9 @@ func RunBattle(
 			}
 		} else {
 			fmt.Println("Opponent's turn... waiting...")
+			if game.Clock.Enabled() {
+				fmt.Printf("Opponent's time: %s\n", game.Clock.Describe())
+			}
 			fmt.Println("(You can still type messages and they'll be sent)")
 
 			// Create done channel to signal when turn is complete
@@ -261,7 +436,7 @@ func RunBattle(
 
 			// Start opponent's turn processing in goroutine
 			go func() {
-				err := battleCtx.ProcessTurn(poke.Move{}, false)
+				err := battleCtx.ProcessTurn(TurnAction{})
 				turnDone <- err
 			}()
 
@@ -271,6 +446,20 @@ func RunBattle(
 				case err := <-turnDone:
 					// Turn is complete
 					if err != nil {
+						if err.Error() == "opponent_forfeited" {
+							opponentForfeited(game, selfPlayer, opponentPlayer, &winner, &loser)
+							goto exitBattle
+						}
+						if err.Error() == "opponent_timed_out" {
+							opponentTimedOut(game, selfPlayer, opponentPlayer, &winner, &loser)
+							goto exitBattle
+						}
+						if err.Error() == "opponent_out_of_time" {
+							// The opponent never announced; declare the timeout ourselves
+							opponentTimedOut(game, selfPlayer, opponentPlayer, &winner, &loser)
+							sendGameOver(battleCtx, opponentPlayer, winner, loser, messages.ReasonTimeout)
+							goto exitBattle
+						}
 						if err.Error() == "opponent_fainted" {
 							// This shouldn't happen on defender's turn, but handle it
 							game.State = StateGameOver
@@ -285,6 +474,7 @@ func RunBattle(
 							gameOverMsg := messages.MakeGameOver(
 								winner,
 								loser,
+								messages.ReasonFainted,
 								seqNum,
 							)
 							gameOverBytes := gameOverMsg.SerializeMessage()
@@ -313,7 +503,15 @@ func RunBattle(
 					}
 					goto turnComplete
 
+				case text := <-deciderChat:
+					sendChatMessage(battleCtx, text)
+
 				case input := <-inputChan:
+					// Answer an open question (e.g. a Special Defense boost) first
+					if battleCtx.DeliverInput(input) {
+						continue
+					}
+
 					// User typed something during opponent's turn
 					if len(input) == 0 {
 						continue
@@ -339,10 +537,20 @@ func RunBattle(
 		}
 
 		// Display current status
-		fmt.Printf("\nYour Pokemon: %s (HP: %d/%d)\n",
+		fmt.Printf("\nYour Pokemon: %s (HP: %d/%d, %s)\n",
 			selfPlayer.PokemonStruct.Name,
 			selfPlayer.PokemonStruct.HP,
-			selfPlayer.PokemonStruct.MaxHP)
+			selfPlayer.PokemonStruct.MaxHP,
+			poke.StatusName(selfPlayer.PokemonStruct.Status))
+		fmt.Printf("Opponent's Pokemon: %s (HP: %d/%d, %s)\n",
+			opponentPlayer.PokemonStruct.Name,
+			opponentPlayer.PokemonStruct.HP,
+			opponentPlayer.PokemonStruct.MaxHP,
+			poke.StatusName(opponentPlayer.PokemonStruct.Status))
+		if r.Doubles {
+			showPartner("Your partner", selfPlayer)
+			showPartner("Opponent's partner", opponentPlayer)
+		}
 
 		// Show low HP warning if HP is below 30%
 		hpPercent := float64(selfPlayer.PokemonStruct.HP) / float64(selfPlayer.PokemonStruct.MaxHP)
@@ -365,6 +573,7 @@ func RunBattle(
 			gameOverMsg := messages.MakeGameOver(
 				winner,
 				loser,
+				messages.ReasonFainted,
 				seqNum,
 			)
 			gameOverBytes := gameOverMsg.SerializeMessage()
@@ -400,6 +609,7 @@ func RunBattle(
 			gameOverMsg := messages.MakeGameOver(
 				winner,
 				loser,
+				messages.ReasonFainted,
 				seqNum,
 			)
 			gameOverBytes := gameOverMsg.SerializeMessage()
@@ -412,10 +622,50 @@ func RunBattle(
 			break
 		}
 
+		// Stop at the turn limit and settle the battle by tiebreakers. Both
+		// peers decide the same result; the host announces it.
+		if game.TurnLimitReached(turnNumber) {
+			game.State = StateGameOver
+			fmt.Printf("\nTurn limit of %d reached!\n", r.TurnLimit)
+			fmt.Printf("Team HP left: you %.1f%%, opponent %.1f%%\n", HPPercent(selfPlayer), HPPercent(opponentPlayer))
+			fmt.Printf("Damage dealt: you %d, opponent %d\n", selfPlayer.DamageDealt, opponentPlayer.DamageDealt)
+			game.BattleLog = append(game.BattleLog, fmt.Sprintf("Turn limit of %d reached", r.TurnLimit))
+
+			seqNum := reliableConn.GetNextSequenceNumber()
+			var gameOverMsg messages.Message
+			tiebreakWinner, tiebreak := game.Tiebreak()
+			if tiebreakWinner == nil {
+				draw = true
+				fmt.Println("The battle is a draw!")
+				game.BattleLog = append(game.BattleLog, "Draw")
+				gameOverMsg = messages.MakeDrawGameOver(messages.ReasonTurnLimit, seqNum)
+			} else {
+				winner = tiebreakWinner.Peer.Name
+				loser = opponentPlayer.Peer.Name
+				if tiebreakWinner == opponentPlayer {
+					loser = selfPlayer.Peer.Name
+				}
+				if winner == selfPlayer.Peer.Name {
+					fmt.Printf("You win on the %s tiebreaker!\n", tiebreak)
+				} else {
+					fmt.Printf("You lose on the %s tiebreaker!\n", tiebreak)
+				}
+				game.BattleLog = append(game.BattleLog, fmt.Sprintf("Winner: %s (%s tiebreaker)", winner, tiebreak))
+				gameOverMsg = messages.MakeGameOver(winner, loser, messages.ReasonTurnLimit, seqNum)
+				messages.AddTiebreak(gameOverMsg, tiebreak)
+			}
+			if isHost {
+				sendGameOverMessage(battleCtx, opponentPlayer, gameOverMsg)
+			}
+			break
+		}
+
 		turnNumber++
 	}
 
 exitBattle:
+	close(clockDone)
+
 	// Clear spectators list to prevent stale connections
 	game.Spectators = make([]peer.PeerDescriptor, 0)
 
@@ -425,21 +675,277 @@ exitBattle:
 	for i, entry := range game.BattleLog {
 		fmt.Printf("%d. %s\n", i+1, entry)
 	}
-	fmt.Println() // Update Pokemon profiles after battle
-	if selfPlayer.Profile != nil {
-		won := winner == selfPlayer.Peer.Name
+	fmt.Println()
 
+	result := poke.ResultLoss
+	if draw {
+		result = poke.ResultDraw
+	} else if winner == selfPlayer.Peer.Name {
+		result = poke.ResultWin
+	}
+	if observer != nil {
+		observer.ObserveEnd(result)
+	}
+
+	// Update Pokemon profiles after battle
+	if selfPlayer.Profile != nil {
 		// Use the original trainer name to ensure profile continuity
 		teamManager := poke.NewTeamManager(selfPlayer.TrainerName)
 
 		// Update profile with battle results
-		err := teamManager.UpdateProfileAfterBattle(selfPlayer.Profile, won)
+		err := teamManager.UpdateProfileAfterBattle(selfPlayer.Profile, result)
 		if err != nil {
 			fmt.Printf("Warning: Could not save profile: %v\n", err)
 		}
 	}
 }
 
+// showBattlers prints both Pokemon at the start of a battle and the moves
+// of selfPlayer's.
+func showBattlers(selfPlayer *player.Player, opponentPlayer *player.Player) {
+	fmt.Printf("Your Pokemon: %s Lv. %d (HP: %d/%d)\n",
+		selfPlayer.PokemonStruct.Name,
+		selfPlayer.PokemonStruct.Level,
+		selfPlayer.PokemonStruct.HP,
+		selfPlayer.PokemonStruct.MaxHP)
+	fmt.Printf("Opponent's Pokemon: %s Lv. %d (HP: %d/%d)\n",
+		opponentPlayer.PokemonStruct.Name,
+		opponentPlayer.PokemonStruct.Level,
+		opponentPlayer.PokemonStruct.HP,
+		opponentPlayer.PokemonStruct.MaxHP)
+	fmt.Println("\nAvailable Moves:")
+	for i, move := range selfPlayer.PokemonStruct.Moves {
+		fmt.Printf("%d. %s (Power: %.0f, Type: %s, Category: %s)\n",
+			i+1, move.Name, move.BasePower, move.Type, move.DamageCategory)
+	}
+}
+
+// showBattleRules prints the held items, the bag, the rules in play and
+// selfPlayer's boosts at the start of a battle.
+func showBattleRules(game *Game, selfPlayer *player.Player, opponentPlayer *player.Player) {
+	r := game.Rules
+	if selfPlayer.PokemonStruct.HeldItem != "" {
+		fmt.Printf("Held item: %s\n", selfPlayer.PokemonStruct.HeldItem)
+	}
+	if opponentPlayer.PokemonStruct.HeldItem != "" {
+		fmt.Printf("Opponent's held item: %s\n", opponentPlayer.PokemonStruct.HeldItem)
+	}
+	if selfPlayer.Bag.Count() > 0 {
+		fmt.Printf("Bag: %s (type 'item <name>' on your turn to use one)\n", selfPlayer.Bag)
+	}
+
+	fmt.Printf("Format: %s\n", r.DescribeFormat())
+	fmt.Printf("Boost rules: %s\n", r.DescribeBoosts())
+	fmt.Printf("Damage model: %s\n", r.DamageModel)
+	if r.InverseBattle || r.Doubles {
+		fmt.Printf("Mode: %s\n", r.Mode())
+	}
+	if r.InverseBattle {
+		fmt.Println("Inverse battle: type matchups are reversed!")
+	}
+	if game.Clock.Enabled() {
+		fmt.Printf("Timers: %s\n", r.DescribeTimers())
+	}
+	if r.BoostSpecial {
+		fmt.Printf("Special Attack Boosts: %d\n", selfPlayer.SpecialAttackUsesLeft)
+		fmt.Printf("Special Defense Boosts: %d (offered when the opponent uses a special move)\n", selfPlayer.SpecialDefenseUsesLeft)
+	}
+	if r.BoostPhysical {
+		fmt.Printf("Physical Attack Boosts: %d\n", selfPlayer.PhysicalAttackUsesLeft)
+		fmt.Printf("Physical Defense Boosts: %d (offered when the opponent uses a physical move)\n", selfPlayer.PhysicalDefenseUsesLeft)
+	}
+}
+
+// showPartner prints a doubles partner's HP and status.
+func showPartner(label string, p *player.Player) {
+	if !p.HasPartner {
+		return
+	}
+	fmt.Printf("%s: %s (HP: %d/%d, %s)\n",
+		label,
+		p.Partner.Name,
+		p.Partner.HP,
+		p.Partner.MaxHP,
+		poke.StatusName(p.Partner.Status))
+}
+
+// chooseTarget asks which Pokemon a doubles attack aims at: a foe, the
+// partner, or both foes at reduced damage. Running out of time picks the
+// first foe.
+func chooseTarget(battleCtx *BattleContext, opponentPlayer *player.Player, inputChan <-chan string, expired <-chan time.Time) (string, int) {
+	self := battleCtx.SelfPlayer
+	fmt.Println("Choose a target:")
+	for _, slot := range foeSlots(opponentPlayer) {
+		fmt.Printf("%d. %s (foe)\n", slot+1, opponentPlayer.Active(slot).Name)
+	}
+	partnerSlot := 1 - battleCtx.Game.ActingSlot
+	hasAlly := self.HasPartner && !IsFainted(self.Active(partnerSlot))
+	if hasAlly {
+		fmt.Printf("3. %s (ally)\n", self.Active(partnerSlot).Name)
+	}
+	fmt.Printf("4. Both foes (%.0f%% damage each)\n", SpreadMultiplier*100)
+
+	for {
+		select {
+		case <-expired:
+			fmt.Println("Time's up! Aiming at the first foe.")
+			return TargetFoe, foeTarget(opponentPlayer, 0)
+		case input := <-inputChan:
+			switch input {
+			case "1", "2":
+				slot := int(input[0] - '1')
+				if slices.Contains(foeSlots(opponentPlayer), slot) {
+					return TargetFoe, slot
+				}
+			case "3":
+				if hasAlly {
+					return TargetAlly, partnerSlot
+				}
+			case "4":
+				return TargetSpread, 0
+			}
+			fmt.Println("Invalid target. Please try again.")
+		default:
+			// Check for incoming network messages
+			buf := make([]byte, 100000) // Increased for large estickers
+			self.Peer.Conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+			n, addr, err := self.Peer.Conn.ReadFromUDP(buf)
+			if err == nil {
+				msg := messages.DeserializeMessage(buf[:n])
+				if msg.MessageType == messages.ChatMessage {
+					processIncomingChat(msg, battleCtx.IsHost, battleCtx, buf[:n], addr)
+				}
+			}
+			self.Peer.Conn.SetReadDeadline(time.Time{})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+// showActionMenu lists the turn actions available to the player, and the
+// chat commands when the battle has someone to chat with over the network.
+func showActionMenu(self *player.Player, chat bool) {
+	fmt.Println("Choose an action:")
+	for i, move := range self.PokemonStruct.Moves {
+		effects := ""
+		if list := move.EffectSummary(); len(list) > 0 {
+			effects = ", " + strings.Join(list, ", ")
+		}
+		fmt.Printf("  %d. %s (Power: %.0f, Type: %s, Category: %s%s)\n",
+			i+1, move.Name, move.BasePower, move.Type, move.DamageCategory, effects)
+	}
+	if lock := self.PokemonStruct.ChoiceLock; lock != "" {
+		fmt.Printf("  (%s is locked into %s by its %s)\n", self.PokemonStruct.Name, lock, self.PokemonStruct.HeldItem)
+	}
+	if self.LastAction != string(ActionProtect) {
+		fmt.Println("  protect - block the opponent's next attack")
+	}
+	if self.Bag.Count() > 0 {
+		fmt.Printf("  item <name> - use a bag item (%s)\n", self.Bag)
+	}
+	for i, reserve := range self.Reserves {
+		if !IsFainted(&reserve) {
+			fmt.Printf("  switch %d - send out %s (HP: %d/%d)\n", i+1, reserve.Name, reserve.HP, reserve.MaxHP)
+		}
+	}
+	fmt.Println("  forfeit - give up the match")
+	if chat {
+		fmt.Println("Or 'chat <message>', stickers (/gg), or 'esticker <filepath>'.")
+	}
+}
+
+// parseTurnAction turns an input line into a turn action. Returns a message
+// explaining the problem if the input is not a valid action.
+func parseTurnAction(input string, self *player.Player) (TurnAction, string) {
+	fields := strings.Fields(strings.ToLower(input))
+	if len(fields) == 0 {
+		return TurnAction{}, "Invalid selection. Please try again."
+	}
+
+	switch fields[0] {
+	case "protect", "defend":
+		if self.LastAction == string(ActionProtect) {
+			return TurnAction{}, "You cannot protect twice in a row."
+		}
+		return TurnAction{Type: ActionProtect}, ""
+	case "item":
+		item, ok := poke.GetItem(strings.TrimSpace(input[len(fields[0]):]))
+		if !ok || self.Bag[item.Name] <= 0 {
+			return TurnAction{}, fmt.Sprintf("You don't have that item. Bag: %s", self.Bag)
+		}
+		return TurnAction{Type: ActionItem, ItemName: item.Name}, ""
+	case "switch":
+		slot := -1
+		if len(fields) == 2 {
+			if n, err := strconv.Atoi(fields[1]); err == nil {
+				slot = n - 1
+			}
+		}
+		if slot < 0 || slot >= len(self.Reserves) || IsFainted(&self.Reserves[slot]) {
+			return TurnAction{}, "Invalid reserve. Use 'switch <number>' with a healthy reserve."
+		}
+		return TurnAction{Type: ActionSwitch, SwitchTo: slot}, ""
+	case "forfeit":
+		return TurnAction{Type: ActionForfeit}, ""
+	}
+
+	idx, err := strconv.Atoi(fields[0])
+	if err != nil || idx < 1 || idx > len(self.PokemonStruct.Moves) {
+		return TurnAction{}, "Invalid selection. Please try again."
+	}
+	move := self.PokemonStruct.Moves[idx-1]
+	if err := checkChoiceLock(self, move); err != nil {
+		return TurnAction{}, fmt.Sprintf("%s until it switches out.", err)
+	}
+	return TurnAction{Type: ActionAttack, Move: move}, ""
+}
+
+// sendGameOver sends GAME_OVER with the given reason to the opponent and,
+// from the host, to spectators.
+func sendGameOver(battleCtx *BattleContext, opponentPlayer *player.Player, winner string, loser string, reason string) {
+	seqNum := battleCtx.ReliableConn.GetNextSequenceNumber()
+	sendGameOverMessage(battleCtx, opponentPlayer, messages.MakeGameOver(winner, loser, reason, seqNum))
+}
+
+// sendGameOverMessage sends a built GAME_OVER to the opponent and, from the
+// host, to spectators.
+func sendGameOverMessage(battleCtx *BattleContext, opponentPlayer *player.Player, gameOverMsg messages.Message) {
+	gameOverBytes := gameOverMsg.SerializeMessage()
+	battleCtx.SelfPlayer.Peer.Conn.WriteToUDP(gameOverBytes, opponentPlayer.Peer.Addr)
+
+	// Verbose logging for GAME_OVER
+	netio.VerboseEventLog(
+		"PokeProtocol: Sent GAME_OVER message to opponent",
+		&netio.LogOptions{
+			MessageParams: gameOverMsg.MessageParams,
+		},
+	)
+
+	if battleCtx.IsHost {
+		battleCtx.Game.BroadcastToSpectators(gameOverBytes)
+	}
+}
+
+// opponentForfeited records a win after the opponent's forfeit GAME_OVER.
+func opponentForfeited(game *Game, selfPlayer *player.Player, opponentPlayer *player.Player, winner *string, loser *string) {
+	game.State = StateGameOver
+	fmt.Println("\nYour opponent forfeited! You win!")
+	*winner = selfPlayer.Peer.Name
+	*loser = opponentPlayer.Peer.Name
+	game.BattleLog = append(game.BattleLog, fmt.Sprintf("%s forfeited!", opponentPlayer.Peer.Name))
+	game.BattleLog = append(game.BattleLog, fmt.Sprintf("Winner: %s", *winner))
+}
+
+// opponentTimedOut records a win after the opponent ran out of time.
+func opponentTimedOut(game *Game, selfPlayer *player.Player, opponentPlayer *player.Player, winner *string, loser *string) {
+	game.State = StateGameOver
+	fmt.Println("\nYour opponent ran out of time! You win!")
+	*winner = selfPlayer.Peer.Name
+	*loser = opponentPlayer.Peer.Name
+	game.BattleLog = append(game.BattleLog, fmt.Sprintf("%s ran out of time!", opponentPlayer.Peer.Name))
+	game.BattleLog = append(game.BattleLog, fmt.Sprintf("Winner: %s", *winner))
+}
+
 // ListenForMessages is a helper goroutine that can listen for async messages like chat.
 func ListenForMessages(
 	selfPlayer *player.Player,
@@ -575,6 +1081,39 @@ func processIncomingChat(msg *messages.Message, isHost bool, battleCtx *BattleCo
 
 	fmt.Printf("DEBUG: Received chat message from %s, content type: %s\n", senderName, contentType)
 
+	showChat(params)
+	if observer := observerOf(battleCtx.Decider); observer != nil {
+		observer.ObserveChat(senderName, chatText(params))
+	}
+
+	// Host relays chat messages according to communication mode
+	if isHost {
+		// Check if message is from joiner (not from us or spectators)
+		isFromOpponent := senderAddr.IP.Equal(battleCtx.OpponentAddr.IP) && senderAddr.Port == battleCtx.OpponentAddr.Port
+
+		if isFromOpponent {
+			// Message from joiner - always relay to spectators
+			battleCtx.broadcastToSpectators(msgBytes)
+		} else {
+			// Message from spectator - relay according to communication mode
+			switch battleCtx.Game.CommunicationMode {
+			case "P": // P2P mode - spectator messages stay with spectators only
+				battleCtx.broadcastToSpectatorsExcept(msgBytes, senderAddr)
+			case "B": // Broadcast mode - relay to joiner AND other spectators
+				battleCtx.SelfPlayer.Peer.Conn.WriteToUDP(msgBytes, battleCtx.OpponentAddr)
+				battleCtx.broadcastToSpectatorsExcept(msgBytes, senderAddr)
+			default: // Default to P2P behavior
+				battleCtx.broadcastToSpectatorsExcept(msgBytes, senderAddr)
+			}
+		}
+	}
+}
+
+// showChat prints a received chat message, sticker or esticker.
+func showChat(params map[string]any) {
+	senderName, _ := params["sender_name"].(string)
+	contentType, _ := params["content_type"].(string)
+
 	if contentType == "TEXT" {
 		if messageText, ok := params["message_text"].(string); ok && messageText != "" {
 			fmt.Printf("\n[%s]: %s\n", senderName, messageText)
@@ -599,26 +1138,16 @@ func processIncomingChat(msg *messages.Message, isHost bool, battleCtx *BattleCo
 			}
 		}
 	}
+}
 
-	// Host relays chat messages according to communication mode
-	if isHost {
-		// Check if message is from joiner (not from us or spectators)
-		isFromOpponent := senderAddr.IP.Equal(battleCtx.OpponentAddr.IP) && senderAddr.Port == battleCtx.OpponentAddr.Port
-
-		if isFromOpponent {
-			// Message from joiner - always relay to spectators
-			battleCtx.broadcastToSpectators(msgBytes)
-		} else {
-			// Message from spectator - relay according to communication mode
-			switch battleCtx.Game.CommunicationMode {
-			case "P": // P2P mode - spectator messages stay with spectators only
-				battleCtx.broadcastToSpectatorsExcept(msgBytes, senderAddr)
-			case "B": // Broadcast mode - relay to joiner AND other spectators
-				battleCtx.SelfPlayer.Peer.Conn.WriteToUDP(msgBytes, battleCtx.OpponentAddr)
-				battleCtx.broadcastToSpectatorsExcept(msgBytes, senderAddr)
-			default: // Default to P2P behavior
-				battleCtx.broadcastToSpectatorsExcept(msgBytes, senderAddr)
-			}
-		}
+// chatText returns a received chat message's text, or the sticker it sent
+// ("/gg", or "esticker" for an image).
+func chatText(params map[string]any) string {
+	if text, ok := params["message_text"].(string); ok && text != "" {
+		return text
+	}
+	if sticker, ok := params["sticker_data"].(string); ok && strings.HasPrefix(sticker, "/") {
+		return sticker
 	}
+	return "esticker"
 }
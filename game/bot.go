@@ -0,0 +1,305 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/peer"
+	"github.com/zrygan/pokemonbattler/poke"
+	monsters "github.com/zrygan/pokemonbattler/poke/mons"
+)
+
+// Bot difficulties, as given to the -bot flags.
+const (
+	BotEasy   = "easy"   // Picks a random move
+	BotMedium = "medium" // Picks the move with the highest expected damage
+	BotHard   = "hard"   // Looks ahead over the seeded outcomes
+	BotExpert = "expert" // Searches the move tree with chance nodes for the rolls
+)
+
+// BotDifficulties lists the difficulties in the order they are offered.
+var BotDifficulties = []string{BotEasy, BotMedium, BotHard, BotExpert}
+
+// Bot tuning.
+const (
+	BotDamageSamples   = 16 // Rolls averaged for an expected damage
+	BotSearchDepth     = 3  // Actions the hard bot looks ahead, its own included
+	BotSetupCandidates = 8  // Random species a medium or hard bot picks the strongest of
+)
+
+// Bot chooses actions for a trainer no one is playing. Its own random
+// choices use a private RNG, so they never move the battle's RNG.
+type Bot struct {
+	Difficulty string
+	Searcher   *Searcher // Searches for the expert bot; nil otherwise
+	rng        *rand.Rand
+}
+
+// NewBot returns a bot of the given difficulty, one of BotDifficulties. An
+// expert bot thinks for budget per move.
+func NewBot(difficulty string, seed int64, budget time.Duration) (*Bot, error) {
+	if !slices.Contains(BotDifficulties, difficulty) {
+		return nil, fmt.Errorf("unknown bot difficulty %q (choose %s)", difficulty, strings.Join(BotDifficulties, ", "))
+	}
+	b := &Bot{Difficulty: difficulty, rng: rand.New(rand.NewSource(seed))}
+	if difficulty == BotExpert {
+		b.Searcher = NewSearcher(budget, b.rng.Int63())
+	}
+	return b, nil
+}
+
+// Name labels the bot's choices with its difficulty, e.g. "medium bot".
+func (b *Bot) Name() string {
+	return b.Difficulty + " bot"
+}
+
+// ChooseAction returns the bot's action for self against foe, with the
+// attack boost already decided. The caller spends the boost. The hard and
+// expert bots only search duels; elsewhere they play like the medium bot.
+func (b *Bot) ChooseAction(g *Game, self *player.Player, foe *player.Player) TurnAction {
+	switch {
+	case b.Difficulty == BotEasy:
+		return b.randomAction(g, self)
+	case b.Difficulty == BotHard && canFork(g, self, foe):
+		return b.searchAction(g, self, foe)
+	case b.Difficulty == BotExpert && canFork(g, self, foe):
+		return b.Searcher.Search(g, g.SideOf(self)).Action
+	}
+	return b.greedyAction(g, self, foe)
+}
+
+// UseDefenseBoost decides whether self braces against attacker's move. The
+// medium and hard bots brace against a hit that would knock them out or take
+// a third of their HP; the hard bot knows the exact damage in a duel. The
+// expert bot searches whether bracing pays off in a duel.
+func (b *Bot) UseDefenseBoost(g *Game, self *player.Player, attacker *player.Player, move poke.Move, attackBoost bool) bool {
+	category := move.DamageCategory
+	if !move.IsDamaging() || !g.Rules.CanBoost(category) || *self.DefenseBoostsLeft(category) <= 0 {
+		return false
+	}
+	if b.Difficulty == BotEasy {
+		return b.rng.Intn(2) == 0
+	}
+	if b.Difficulty == BotExpert && canFork(g, attacker, self) {
+		return b.Searcher.ShouldBrace(g, g.SideOf(self), TurnAction{Type: ActionAttack, Move: move, AttackBoost: attackBoost})
+	}
+
+	damage := b.expectedDamage(g, attacker, self, move, attackBoost)
+	if b.Difficulty == BotHard && canFork(g, attacker, self) {
+		sim := g.Fork()
+		simAttacker, simSelf := sim.Side(g.SideOf(attacker)), sim.Side(g.SideOf(self))
+		damage = float64(sim.ResolveAttack(simAttacker, simSelf, move, attackBoost, false).Damage)
+	}
+	return damage >= float64(self.PokemonStruct.HP) || damage*3 >= float64(self.PokemonStruct.MaxHP)
+}
+
+// randomAction picks any move, and spends a boost on it half the time.
+func (b *Bot) randomAction(g *Game, self *player.Player) TurnAction {
+	moves := self.PokemonStruct.UsableMoves()
+	if len(moves) == 0 {
+		return DefaultAction(self)
+	}
+	action := TurnAction{Type: ActionAttack, Move: moves[b.rng.Intn(len(moves))]}
+	action.AttackBoost = canAttackBoost(g, self, action.Move) && b.rng.Intn(2) == 0
+	return action
+}
+
+// greedyAction picks the move with the highest expected damage against foe,
+// counting the attack boost where one is left. The boost is only spent if
+// the move would not knock the foe out without it.
+func (b *Bot) greedyAction(g *Game, self *player.Player, foe *player.Player) TurnAction {
+	action := DefaultAction(self)
+	best := 0.0
+	for _, move := range self.PokemonStruct.UsableMoves() {
+		if GetMoveEffectiveness(g.TypeChart, move, &foe.PokemonStruct) == 0 {
+			continue
+		}
+		if damage := b.expectedDamage(g, self, foe, move, canAttackBoost(g, self, move)); damage > best {
+			action, best = TurnAction{Type: ActionAttack, Move: move}, damage
+		}
+	}
+
+	if canAttackBoost(g, self, action.Move) {
+		action.AttackBoost = b.expectedDamage(g, self, foe, action.Move, false) < float64(foe.PokemonStruct.HP)
+	}
+	return action
+}
+
+// expectedDamage averages the damage of BotDamageSamples rolls of a move.
+// The rolls use the bot's RNG, so the battle's RNG is not touched.
+func (b *Bot) expectedDamage(g *Game, attacker *player.Player, defender *player.Player, move poke.Move, attackBoost bool) float64 {
+	if !move.IsDamaging() {
+		return 0
+	}
+	sim := &Game{Rules: g.Rules, TypeChart: g.TypeChart, Field: g.Field, RNG: b.rng}
+	total := 0
+	for range BotDamageSamples {
+		total += sim.ResolveAttack(attacker, defender, move, attackBoost, false).Damage
+	}
+	return float64(total) / BotDamageSamples
+}
+
+// searchAction looks BotSearchDepth actions ahead on forks of the battle.
+// The battle's RNG is seeded and shared, so every fork plays out exactly as
+// the battle would. The bot assumes the foe answers with its best reply and
+// never braces with a defense boost.
+func (b *Bot) searchAction(g *Game, self *player.Player, foe *player.Player) TurnAction {
+	side := g.SideOf(self)
+	best, bestScore := b.greedyAction(g, self, foe), math.Inf(-1)
+	for _, action := range candidateActions(g, self) {
+		if score := searchScore(g, side, action, BotSearchDepth); score > bestScore {
+			best, bestScore = action, score
+		}
+	}
+	return best
+}
+
+// searchScore plays action for the side whose turn it is on a fork of g and
+// scores the result for side, looking depth actions ahead in total.
+func searchScore(g *Game, side string, action TurnAction, depth int) float64 {
+	sim := g.Fork()
+	actor, target := sim.Side(sim.CurrentTurn), sim.Side(otherSide(sim.CurrentTurn))
+	if action.AttackBoost {
+		*actor.AttackBoostsLeft(action.Move.DamageCategory)--
+	}
+	outcome, err := sim.ResolveAction(actor, target, action, false)
+	if err != nil {
+		return math.Inf(-1)
+	}
+	sim.FinishAttack(actor, target, action.Move, outcome)
+	sim.AdvanceTurn()
+
+	score := scoreSide(sim, side)
+	if depth <= 1 || math.IsInf(score, 0) {
+		return score
+	}
+
+	mover := sim.CurrentTurn
+	best := math.Inf(1)
+	if mover == side {
+		best = math.Inf(-1)
+	}
+	for _, next := range candidateActions(sim, sim.Side(mover)) {
+		score := searchScore(sim, side, next, depth-1)
+		if mover == side {
+			best = max(best, score)
+		} else {
+			best = min(best, score)
+		}
+	}
+	return best
+}
+
+// scoreSide scores a battle for side: the difference of the two teams' HP
+// shares, or an infinity once one team has nothing left.
+func scoreSide(g *Game, side string) float64 {
+	self, foe := g.Side(side), g.Side(otherSide(side))
+	switch {
+	case IsFainted(&foe.PokemonStruct):
+		return math.Inf(1)
+	case IsFainted(&self.PokemonStruct):
+		return math.Inf(-1)
+	}
+	return HPPercent(self) - HPPercent(foe)
+}
+
+// candidateActions lists the attacks a player can make, each move once
+// without and, where a boost is left, once with an attack boost.
+func candidateActions(g *Game, p *player.Player) []TurnAction {
+	var actions []TurnAction
+	for _, move := range p.PokemonStruct.UsableMoves() {
+		actions = append(actions, TurnAction{Type: ActionAttack, Move: move})
+		if canAttackBoost(g, p, move) {
+			actions = append(actions, TurnAction{Type: ActionAttack, Move: move, AttackBoost: true})
+		}
+	}
+	if len(actions) == 0 {
+		actions = append(actions, DefaultAction(p))
+	}
+	return actions
+}
+
+// canAttackBoost reports whether p may spend an attack boost on move.
+func canAttackBoost(g *Game, p *player.Player, move poke.Move) bool {
+	return move.IsDamaging() && g.Rules.CanBoost(move.DamageCategory) && *p.AttackBoostsLeft(move.DamageCategory) > 0
+}
+
+// canFork reports whether a and b are the two sides of a duel, which is
+// what Fork copies.
+func canFork(g *Game, a *player.Player, b *player.Player) bool {
+	if g.Rules.Doubles || g.Rules.HostOrdered() || g.Host == nil || g.Joiner == nil {
+		return false
+	}
+	return (a == g.Host && b == g.Joiner) || (a == g.Joiner && b == g.Host)
+}
+
+// otherSide returns "joiner" for "host" and "host" for "joiner".
+func otherSide(side string) string {
+	if side == "host" {
+		return "joiner"
+	}
+	return "host"
+}
+
+// NewBotPlayer builds the bot's trainer under a ruleset: legal species at
+// the default level (capped by the ruleset) with random IVs and a neutral
+// nature, no items, and the boost budget split evenly between attack and
+// defense in every category the ruleset allows. The easy bot picks species
+// at random; the others take the strongest of BotSetupCandidates random
+// picks. Returns an error if the ruleset allows no species at all.
+func (b *Bot) NewBotPlayer(self peer.PeerDescriptor, r rules.Ruleset) (player.Player, error) {
+	var party []poke.Pokemon
+	for len(party) < r.PartySize {
+		legal := legalSpecies(r, party)
+		if len(legal) == 0 {
+			break
+		}
+		mon := monsters.MONSTERS[legal[b.rng.Intn(len(legal))]]
+		if b.Difficulty != BotEasy {
+			for range BotSetupCandidates - 1 {
+				candidate := monsters.MONSTERS[legal[b.rng.Intn(len(legal))]]
+				if candidate.BaseStats.Total() > mon.BaseStats.Total() {
+					mon = candidate
+				}
+			}
+		}
+		mon.ApplyStats(r.CapLevel(poke.DefaultLevel), poke.RandomIVs(), poke.StatSet{}, poke.Natures[poke.NeutralNature])
+		party = append(party, mon)
+	}
+	if len(party) == 0 {
+		return player.Player{}, fmt.Errorf("no Pokemon are legal under this ruleset")
+	}
+
+	return newTrainer(self, r, party), nil
+}
+
+// newTrainer builds a trainer with no items whose boost budget is split
+// evenly between attack and defense in every category the ruleset allows.
+func newTrainer(self peer.PeerDescriptor, r rules.Ruleset, party []poke.Pokemon) player.Player {
+	var categories []string
+	if r.BoostSpecial {
+		categories = append(categories, poke.Special)
+	}
+	if r.BoostPhysical {
+		categories = append(categories, poke.Physical)
+	}
+	p := player.Player{
+		Peer:          self,
+		PokemonStruct: party[0],
+		TrainerName:   self.Name,
+		Bag:           poke.Bag{},
+		Reserves:      party[1:],
+	}
+	for _, category := range categories {
+		share := r.BoostBudget / (2 * len(categories))
+		*p.AttackBoostsLeft(category) = share
+		*p.DefenseBoostsLeft(category) = share
+	}
+	return p
+}
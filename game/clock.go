@@ -0,0 +1,252 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/messages"
+)
+
+// ClockGrace is how long past the acting player's allowance the waiting
+// peer waits for ATTACK_ANNOUNCE before declaring a timeout, and how far a
+// reported thinking time may fall short of the waiting peer's own measurement.
+const ClockGrace = 3 * time.Second
+
+// ClockBroadcastInterval is how often the host sends CLOCK to spectators.
+const ClockBroadcastInterval = 5 * time.Second
+
+// Clock enforces the per-turn timer and the total game clock. Both peers
+// keep one and charge each turn the thinking time reported in
+// ATTACK_ANNOUNCE, so their clocks stay identical.
+type Clock struct {
+	mu        sync.Mutex
+	turnLimit time.Duration            // Time allowed per turn (0 for no limit)
+	gameClock bool                     // Whether the total game clock is on
+	remaining map[string]time.Duration // Game clock left for "host" and "joiner"
+	turn      string                   // Whose turn is being timed
+	turnStart time.Time                // When the current turn started
+}
+
+// NewClock creates the clock for a ruleset.
+func NewClock(r rules.Ruleset) *Clock {
+	total := time.Duration(r.GameClock) * time.Second
+	return &Clock{
+		turnLimit: time.Duration(r.TurnTimer) * time.Second,
+		gameClock: r.GameClock > 0,
+		remaining: map[string]time.Duration{"host": total, "joiner": total},
+	}
+}
+
+// Enabled reports whether either timer is on. A nil clock is disabled.
+func (c *Clock) Enabled() bool {
+	return c != nil && (c.turnLimit > 0 || c.gameClock)
+}
+
+// StartTurn starts timing a turn for "host" or "joiner".
+func (c *Clock) StartTurn(turn string) {
+	if !c.Enabled() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.turn = turn
+	c.turnStart = time.Now()
+}
+
+// Allowance returns the total time the current turn may take: the turn
+// timer, cut short by whatever is left on the player's game clock.
+func (c *Clock) Allowance() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.allowance()
+}
+
+func (c *Clock) allowance() time.Duration {
+	return c.allowanceFor(c.turn)
+}
+
+// allowanceFor returns the time a turn of "host" or "joiner" may take.
+func (c *Clock) allowanceFor(turn string) time.Duration {
+	allowance := c.turnLimit
+	if c.gameClock {
+		left := max(c.remaining[turn], 0)
+		if allowance == 0 || left < allowance {
+			allowance = left
+		}
+	}
+	return allowance
+}
+
+// Elapsed returns how long the current turn has taken so far.
+func (c *Clock) Elapsed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Since(c.turnStart)
+}
+
+// TimeLeft returns how much of the current turn's allowance is left.
+func (c *Clock) TimeLeft() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return max(c.allowance()-time.Since(c.turnStart), 0)
+}
+
+// Expired returns a channel that fires when the current turn's allowance
+// runs out, or nil (which never fires) when the clock is disabled.
+func (c *Clock) Expired() <-chan time.Time {
+	if !c.Enabled() {
+		return nil
+	}
+	return time.After(c.TimeLeft())
+}
+
+// Deadline returns when the waiting peer gives up on the acting player:
+// the end of the allowance plus ClockGrace. Zero when the clock is disabled.
+func (c *Clock) Deadline() time.Time {
+	if !c.Enabled() {
+		return time.Time{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.turnStart.Add(c.allowance() + ClockGrace)
+}
+
+// Charge takes a turn's thinking time off a player's game clock.
+func (c *Clock) Charge(turn string, used time.Duration) {
+	if !c.Enabled() || !c.gameClock {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remaining[turn] = max(c.remaining[turn]-used, 0)
+}
+
+// CheckReported returns an error if a reported thinking time is impossible:
+// longer than the allowance, or well under the time the turn visibly took.
+func (c *Clock) CheckReported(used time.Duration) error {
+	c.mu.Lock()
+	turn := c.turn
+	c.mu.Unlock()
+	return c.CheckReportedFor(turn, used, time.Now())
+}
+
+// CheckReportedFor is CheckReported for the thinking time of "host" or
+// "joiner" while the clock times another turn, as when both trainers choose
+// at once as a duel round opens. arrived is when the report was received.
+func (c *Clock) CheckReportedFor(turn string, used time.Duration, arrived time.Time) error {
+	if !c.Enabled() {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if allowance := c.allowanceFor(turn); used < 0 || used > allowance {
+		return fmt.Errorf("reported %v of thinking time, the allowance is %v", used, allowance)
+	}
+	if measured := arrived.Sub(c.turnStart); used < measured-ClockGrace {
+		return fmt.Errorf("reported %v of thinking time, but the turn took %v", used, measured.Round(time.Second))
+	}
+	return nil
+}
+
+// DeadlineFor is Deadline for "host" or "joiner" while the clock times
+// another turn.
+func (c *Clock) DeadlineFor(turn string) time.Time {
+	if !c.Enabled() {
+		return time.Time{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.turnStart.Add(c.allowanceFor(turn) + ClockGrace)
+}
+
+// Remaining returns the game clock left for "host" or "joiner".
+func (c *Clock) Remaining(turn string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remaining[turn]
+}
+
+// HasGameClock reports whether the total game clock is on.
+func (c *Clock) HasGameClock() bool {
+	return c.Enabled() && c.gameClock
+}
+
+// Describe summarizes the time left, e.g. "12s left this turn, clock 4:31".
+func (c *Clock) Describe() string {
+	turn, turnLeft, host, joiner := c.Snapshot()
+	text := fmt.Sprintf("%ds left this turn", int(turnLeft.Round(time.Second).Seconds()))
+	if c.HasGameClock() {
+		clock := host
+		if turn == "joiner" {
+			clock = joiner
+		}
+		text += ", clock " + FormatClock(clock)
+	}
+	return text
+}
+
+// Snapshot returns whose turn it is, the time left in the turn and both
+// game clocks, with the current turn's thinking time already taken off.
+func (c *Clock) Snapshot() (turn string, turnLeft time.Duration, host time.Duration, joiner time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elapsed := time.Since(c.turnStart)
+	host, joiner = c.remaining["host"], c.remaining["joiner"]
+	if c.turn == "host" {
+		host = max(host-elapsed, 0)
+	} else {
+		joiner = max(joiner-elapsed, 0)
+	}
+	return c.turn, max(c.allowance()-elapsed, 0), host, joiner
+}
+
+// DefaultAction is the action taken for a player who runs out of time under
+// rules.TimeoutDefault: the active Pokemon's first damaging move it may use,
+// without a boost.
+func DefaultAction(self *player.Player) TurnAction {
+	moves := self.PokemonStruct.UsableMoves()
+	for _, move := range moves {
+		if move.IsDamaging() {
+			return TurnAction{Type: ActionAttack, Move: move}
+		}
+	}
+	if len(moves) > 0 {
+		return TurnAction{Type: ActionAttack, Move: moves[0]}
+	}
+	return TurnAction{Type: ActionProtect}
+}
+
+// clockSeconds converts a clock reading to whole seconds for CLOCK.
+func clockSeconds(d time.Duration) int {
+	return int(d.Round(time.Second).Seconds())
+}
+
+// broadcastClock sends CLOCK to spectators every ClockBroadcastInterval
+// until done is closed. Only the host runs it.
+func (g *Game) broadcastClock(done <-chan struct{}) {
+	ticker := time.NewTicker(ClockBroadcastInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			turn, turnLeft, host, joiner := g.Clock.Snapshot()
+			hostClock, joinerClock := -1, -1
+			if g.Clock.HasGameClock() {
+				hostClock, joinerClock = clockSeconds(host), clockSeconds(joiner)
+			}
+			clockMsg := messages.MakeClock(turn, clockSeconds(turnLeft), hostClock, joinerClock)
+			g.BroadcastToSpectators(clockMsg.SerializeMessage())
+		}
+	}
+}
+
+// FormatClock formats a game clock as minutes and seconds, e.g. "4:31".
+func FormatClock(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// DamageModel computes the base damage of one hit, before critical hits,
+// held items, weather and scripted effects are applied. Implementations must
+// consume the RNG the same way every time so both peers stay in sync.
+type DamageModel interface {
+	// Name returns the ruleset name of the model, one of rules.DamageModels.
+	Name() string
+	// Damage returns the damage dealt by move, with type matchups taken from
+	// chart. The boost flags say whether the attacker and defender spend a
+	// boost, which scales the matching stat by boostMultiplier.
+	Damage(
+		attacker *poke.Pokemon,
+		defender *poke.Pokemon,
+		move poke.Move,
+		chart poke.TypeChart,
+		attackerUsesBoost bool,
+		defenderUsesBoost bool,
+		boostMultiplier float64,
+		rng *rand.Rand,
+	) int
+}
+
+// damageModels maps ruleset names to their damage models.
+var damageModels = map[string]DamageModel{
+	rules.DamageProtocol: ProtocolDamage{},
+	rules.DamageOfficial: OfficialDamage{},
+}
+
+// GetDamageModel returns the damage model with the given ruleset name.
+func GetDamageModel(name string) (DamageModel, bool) {
+	model, ok := damageModels[name]
+	return model, ok
+}
+
+// DamageModel returns the damage model selected by the game's ruleset,
+// falling back to the protocol formula.
+func (g *Game) DamageModel() DamageModel {
+	if model, ok := GetDamageModel(g.Rules.DamageModel); ok {
+		return model
+	}
+	return ProtocolDamage{}
+}
+
+// ProtocolDamage is the PokeProtocol formula implemented by CalculateDamage.
+type ProtocolDamage struct{}
+
+// Name returns rules.DamageProtocol.
+func (ProtocolDamage) Name() string {
+	return rules.DamageProtocol
+}
+
+// Damage calls CalculateDamage.
+func (ProtocolDamage) Damage(
+	attacker *poke.Pokemon,
+	defender *poke.Pokemon,
+	move poke.Move,
+	chart poke.TypeChart,
+	attackerUsesBoost bool,
+	defenderUsesBoost bool,
+	boostMultiplier float64,
+	rng *rand.Rand,
+) int {
+	return CalculateDamage(attacker, defender, move, chart, attackerUsesBoost, defenderUsesBoost, boostMultiplier, rng)
+}
+
+// STABMultiplier is the bonus for a move that matches one of the user's types.
+const STABMultiplier = 1.5
+
+// OfficialDamage is the main series formula:
+//
+//	((2 x Level / 5 + 2) x Power x Attack / Defense / 50 + 2) x STAB x Type x Random
+//
+// where Random is a whole percentage from 85 to 100. Like the protocol
+// formula it draws exactly one number from the RNG per hit.
+type OfficialDamage struct{}
+
+// Name returns rules.DamageOfficial.
+func (OfficialDamage) Name() string {
+	return rules.DamageOfficial
+}
+
+// Damage applies the main series formula.
+func (OfficialDamage) Damage(
+	attacker *poke.Pokemon,
+	defender *poke.Pokemon,
+	move poke.Move,
+	chart poke.TypeChart,
+	attackerUsesBoost bool,
+	defenderUsesBoost bool,
+	boostMultiplier float64,
+	rng *rand.Rand,
+) int {
+	attackerStat := float64(attacker.BattleStat(poke.StatSpecialAttack))
+	defenderStat := float64(defender.BattleStat(poke.StatSpecialDefense))
+	if move.DamageCategory == poke.Physical {
+		attackerStat = float64(attacker.BattleStat(poke.StatAttack))
+		defenderStat = float64(defender.BattleStat(poke.StatDefense))
+	}
+	if attackerUsesBoost {
+		attackerStat *= boostMultiplier
+	}
+	if defenderUsesBoost {
+		defenderStat *= boostMultiplier
+	}
+	defenderStat = max(defenderStat, 1)
+
+	level := attacker.Level
+	if level == 0 {
+		level = poke.DefaultLevel
+	}
+	basePower := move.BasePower
+	if basePower == 0 {
+		basePower = 1.0
+	}
+
+	damage := math.Floor(math.Floor(math.Floor(float64(2*level)/5+2)*basePower*attackerStat/defenderStat)/50) + 2
+
+	// Same-type attack bonus
+	if move.Type == attacker.Type1 || (attacker.Type2 != "" && move.Type == attacker.Type2) {
+		damage *= STABMultiplier
+	}
+
+	typeEffectiveness := GetMoveEffectiveness(chart, move, defender)
+	damage *= typeEffectiveness
+
+	// Random roll from 85% to 100% in whole percent steps
+	damage = math.Floor(damage * float64(85+rng.Intn(16)) / 100)
+
+	if damage < 1 && typeEffectiveness > 0 {
+		return 1
+	}
+	return int(damage)
+}
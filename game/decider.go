@@ -0,0 +1,83 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// Decider makes a trainer's choices in place of the terminal. The runners
+// ask it for every action and defense boost of the trainer it plays.
+type Decider interface {
+	// Name labels the decider's choices, e.g. "medium bot".
+	Name() string
+	// ChooseAction returns self's action against foe, with the attack
+	// boost already decided. The caller spends the boost.
+	ChooseAction(g *Game, self *player.Player, foe *player.Player) TurnAction
+	// UseDefenseBoost decides whether self braces against attacker's move.
+	UseDefenseBoost(g *Game, self *player.Player, attacker *player.Player, move poke.Move, attackBoost bool) bool
+}
+
+// BattleObserver is a Decider that follows the battle as it goes: the
+// state at the start of every turn, the chat it receives and the result.
+type BattleObserver interface {
+	ObserveTurn(g *Game, self *player.Player, foe *player.Player, turn int)
+	ObserveChat(sender string, text string)
+	ObserveEnd(result poke.BattleResult)
+}
+
+// Chatter is a Decider that sends chat of its own. Every line it delivers
+// is sent as if the trainer had typed it.
+type Chatter interface {
+	Chat() <-chan string
+}
+
+// observerOf returns the decider's BattleObserver, or nil.
+func observerOf(d Decider) BattleObserver {
+	observer, _ := d.(BattleObserver)
+	return observer
+}
+
+// chatOf returns the decider's chat, or nil (which never delivers).
+func chatOf(d Decider) <-chan string {
+	if chatter, ok := d.(Chatter); ok {
+		return chatter.Chat()
+	}
+	return nil
+}
+
+// chooseChecked asks the decider for self's action in a duel and checks it
+// on a fork of the battle. An action the engine would reject is replaced by
+// DefaultAction, as ExternalBot does with answers it cannot parse, and the
+// problem is returned so the caller can report it.
+func chooseChecked(g *Game, d Decider, self *player.Player, foe *player.Player) (TurnAction, error) {
+	action := d.ChooseAction(g, self, foe)
+	if action.Type == ActionForfeit {
+		return action, nil
+	}
+	if action.AttackBoost && !canAttackBoost(g, self, action.Move) {
+		return DefaultAction(self), fmt.Errorf("%s has no %s Attack boost left", d.Name(), categoryName(action.Move.DamageCategory))
+	}
+	if err := checkAction(g, self, foe, action); err != nil {
+		return DefaultAction(self), fmt.Errorf("%s chose an invalid %s: %w", d.Name(), action.Type, err)
+	}
+	return action, nil
+}
+
+// checkAction resolves self's action against foe on a fork of a duel and
+// returns the engine's objection, if any. The battle is not touched.
+func checkAction(g *Game, self *player.Player, foe *player.Player, action TurnAction) error {
+	sim := g.Fork()
+	_, err := sim.ResolveTurn(sim.Side(g.SideOf(self)), sim.Side(g.SideOf(foe)), action, false)
+	return err
+}
+
+// planAction asks the decider for self's action in a duel round before the
+// order is decided, as if self moved first, and checks it as chooseChecked
+// does. The battle is not touched.
+func planAction(g *Game, d Decider, self *player.Player, foe *player.Player) (TurnAction, error) {
+	sim := g.Fork()
+	sim.CurrentTurn = g.SideOf(self)
+	return chooseChecked(sim, d, sim.Side(g.SideOf(self)), sim.Side(g.SideOf(foe)))
+}
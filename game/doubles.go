@@ -0,0 +1,395 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/messages"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// SpreadMultiplier scales the damage of a doubles move that hits both foes.
+const SpreadMultiplier = 0.75
+
+// Doubles targets. The values match the "target" field of ATTACK_ANNOUNCE.
+const (
+	TargetFoe    = messages.TargetFoe    // One opposing Pokemon
+	TargetAlly   = messages.TargetAlly   // The user's partner
+	TargetSpread = messages.TargetSpread // Both opposing Pokemon at SpreadMultiplier
+)
+
+// Actor is an active Pokemon's place in the doubles turn order.
+type Actor struct {
+	Side string // "host" or "joiner"
+	Slot int    // 0 for PokemonStruct, 1 for the partner
+}
+
+// Side returns the player for "host" or "joiner".
+func (g *Game) Side(side string) *player.Player {
+	if side == "joiner" {
+		return g.Joiner
+	}
+	return g.Host
+}
+
+// StartDoubles sends out each side's first reserve as its partner and
+// orders the first round. Both peers call it once the parties are known, so
+// it needs no message. Returns the partners' entry ability events.
+func (g *Game) StartDoubles() []string {
+	var events []string
+	for _, p := range []*player.Player{g.Host, g.Joiner} {
+		if len(p.Reserves) == 0 {
+			continue
+		}
+		p.Partner = p.Reserves[0]
+		p.Reserves = p.Reserves[1:]
+		p.HasPartner = true
+		events = append(events, g.Field.ApplyEntryAbility(&p.Partner)...)
+	}
+
+	g.RoundOrder = g.speedOrder()
+	g.nextActor()
+	return events
+}
+
+// canAct reports whether an actor's slot holds a Pokemon that can battle.
+func (g *Game) canAct(actor Actor) bool {
+	p := g.Side(actor.Side)
+	if actor.Slot == 1 && !p.HasPartner {
+		return false
+	}
+	return !IsFainted(p.Active(actor.Slot))
+}
+
+// speedOrder returns every active Pokemon that can battle, fastest first.
+// Ties go to the host, then to slot 0.
+func (g *Game) speedOrder() []Actor {
+	var order []Actor
+	for _, side := range []string{"host", "joiner"} {
+		for slot := range 2 {
+			if actor := (Actor{Side: side, Slot: slot}); g.canAct(actor) {
+				order = append(order, actor)
+			}
+		}
+	}
+	slices.SortStableFunc(order, func(a, b Actor) int {
+		return g.Side(b.Side).Active(b.Slot).BattleStat(poke.StatSpeed) - g.Side(a.Side).Active(a.Slot).BattleStat(poke.StatSpeed)
+	})
+	return order
+}
+
+// DescribeOrder lists the active Pokemon in speed order, e.g.
+// "Jolteon > Pikachu > Snorlax".
+func (g *Game) DescribeOrder() string {
+	var names []string
+	for _, actor := range g.speedOrder() {
+		names = append(names, g.Side(actor.Side).Active(actor.Slot).Name)
+	}
+	return strings.Join(names, " > ")
+}
+
+// nextActor hands the turn to the next actor of the round that can still
+// battle, starting a new round when everyone has acted.
+func (g *Game) nextActor() {
+	for {
+		if len(g.RoundOrder) == 0 {
+			g.TurnsThisRound = 0
+			g.RoundOrder = g.speedOrder()
+			g.endProtection()
+			if len(g.RoundOrder) == 0 {
+				return
+			}
+		}
+		next := g.RoundOrder[0]
+		g.RoundOrder = g.RoundOrder[1:]
+		if g.canAct(next) {
+			g.CurrentTurn = next.Side
+			g.ActingSlot = next.Slot
+			return
+		}
+	}
+}
+
+// endProtection ends the protection of every active Pokemon as a doubles
+// round ends.
+func (g *Game) endProtection() {
+	for _, p := range []*player.Player{g.Host, g.Joiner} {
+		p.PokemonStruct.Protecting = false
+		p.Partner.Protecting = false
+	}
+}
+
+// SlotView returns a copy of p whose active Pokemon is the one in slot, for
+// menus and prompts. Changes to the copy's Pokemon are not kept.
+func SlotView(p *player.Player, slot int) *player.Player {
+	view := *p
+	view.PokemonStruct = *p.Active(slot)
+	return &view
+}
+
+// allyView returns a copy of p whose active Pokemon is the partner, so an
+// attack can target it. FinishDoublesAction copies the result back.
+func allyView(p *player.Player) *player.Player {
+	ally := *p
+	ally.PokemonStruct = p.Partner
+	return &ally
+}
+
+// foeSlots returns the opponent's slots that can be hit.
+func foeSlots(opponent *player.Player) []int {
+	var slots []int
+	if !IsFainted(&opponent.PokemonStruct) {
+		slots = append(slots, 0)
+	}
+	if opponent.HasPartner && !IsFainted(&opponent.Partner) {
+		slots = append(slots, 1)
+	}
+	return slots
+}
+
+// foeTarget returns the slot a single-target move hits. A move aimed at a
+// fainted or empty slot is redirected to the other foe.
+func foeTarget(opponent *player.Player, slot int) int {
+	slots := foeSlots(opponent)
+	if len(slots) == 0 || slices.Contains(slots, slot) {
+		return slot
+	}
+	return slots[0]
+}
+
+// withSlot runs fn with the Pokemon in slot moved into p.PokemonStruct.
+func withSlot[T any](p *player.Player, slot int, fn func() T) T {
+	if slot == 1 {
+		p.SwapPartner()
+		defer p.SwapPartner()
+	}
+	return fn()
+}
+
+// ResolveDoublesAction resolves a doubles turn action by the Pokemon in
+// action.Slot without changing any player. Attacks hit the chosen foe, the
+// partner, or both foes at SpreadMultiplier; spread moves roll each foe in
+// slot order, and recoil and drain from the first foe carry into the second.
+func (g *Game) ResolveDoublesAction(
+	user *player.Player,
+	opponent *player.Player,
+	action TurnAction,
+	defenderUsesBoost bool,
+) (AttackOutcome, error) {
+	if action.Slot == 1 {
+		if !user.HasPartner {
+			return AttackOutcome{}, fmt.Errorf("%s has no partner in slot 2", user.TrainerName)
+		}
+		user.SwapPartner()
+		defer user.SwapPartner()
+	}
+	if action.Type != ActionAttack {
+		return g.ResolveAction(user, opponent, action, defenderUsesBoost)
+	}
+	if err := checkChoiceLock(user, action.Move); err != nil {
+		return AttackOutcome{}, err
+	}
+
+	move := action.Move
+	switch action.Target {
+	case TargetAlly:
+		if !user.HasPartner || IsFainted(&user.Partner) {
+			return AttackOutcome{}, fmt.Errorf("%s has no partner to target", user.PokemonStruct.Name)
+		}
+		outcome := g.ResolveAttack(user, allyView(user), move, action.AttackBoost, false)
+		outcome.TargetSlot = 1 - action.Slot
+		return outcome, nil
+
+	case TargetSpread:
+		startHP := user.PokemonStruct.HP
+		defer func() { user.PokemonStruct.HP = startHP }()
+
+		var spread []AttackOutcome
+		for _, slot := range foeSlots(opponent) {
+			outcome := withSlot(opponent, slot, func() AttackOutcome {
+				return g.resolveAttack(user, opponent, move, action.AttackBoost, defenderUsesBoost, SpreadMultiplier)
+			})
+			outcome.TargetSlot = slot
+			user.PokemonStruct.HP = outcome.AttackerHP
+			spread = append(spread, outcome)
+		}
+		if len(spread) == 0 {
+			return AttackOutcome{}, fmt.Errorf("no foe to hit")
+		}
+		return combineSpread(spread), nil
+	}
+
+	slot := foeTarget(opponent, action.TargetSlot)
+	outcome := withSlot(opponent, slot, func() AttackOutcome {
+		return g.ResolveAttack(user, opponent, move, action.AttackBoost, defenderUsesBoost)
+	})
+	outcome.TargetSlot = slot
+	return outcome, nil
+}
+
+// combineSpread sums a spread move's outcomes into one for reports and
+// display. The first foe's fields are kept where there is no sum.
+func combineSpread(spread []AttackOutcome) AttackOutcome {
+	combined := spread[0]
+	combined.Hits, combined.Effects = nil, nil
+	combined.Damage, combined.Recoil, combined.ItemRecoil, combined.Drained = 0, 0, 0, 0
+	for _, outcome := range spread {
+		combined.Damage += outcome.Damage
+		combined.Hits = append(combined.Hits, outcome.Hits...)
+		combined.Recoil += outcome.Recoil
+		combined.ItemRecoil += outcome.ItemRecoil
+		combined.Drained += outcome.Drained
+		combined.Effects = append(combined.Effects, outcome.Effects...)
+		combined.Critical = combined.Critical || outcome.Critical
+		combined.Endured = combined.Endured || outcome.Endured
+		combined.Protected = combined.Protected && outcome.Protected
+	}
+	combined.AttackerHP = spread[len(spread)-1].AttackerHP
+	combined.Spread = spread
+	return combined
+}
+
+// SpreadDamage returns the damage a spread move dealt to each foe, in slot
+// order, or nil for any other outcome.
+func (o AttackOutcome) SpreadDamage() []int {
+	var damage []int
+	for _, outcome := range o.Spread {
+		damage = append(damage, outcome.Damage)
+	}
+	return damage
+}
+
+// FinishDoublesAction applies a doubles outcome from ResolveDoublesAction,
+// then refills fainted slots from the reserves. A side whose first slot
+// faints with nobody left to send out moves its partner into slot 0, so
+// PokemonStruct only stays fainted once the whole side is out.
+func (g *Game) FinishDoublesAction(
+	user *player.Player,
+	opponent *player.Player,
+	action TurnAction,
+	outcome AttackOutcome,
+) []string {
+	if action.Slot == 1 {
+		user.SwapPartner()
+	}
+
+	move := action.Move
+	var events []string
+	switch {
+	case action.Type != ActionAttack:
+		events = g.FinishAttack(user, opponent, move, outcome)
+
+	case action.Target == TargetAlly:
+		ally := allyView(user)
+		hpBefore := ally.PokemonStruct.HP
+		events = g.FinishAttack(user, ally, move, outcome)
+		user.Partner = ally.PokemonStruct
+		// Hitting a partner does not count as damage dealt
+		user.DamageDealt -= max(hpBefore-outcome.DefenderHP, 0)
+
+	case action.Target == TargetSpread:
+		for i, spreadOutcome := range outcome.Spread {
+			last := i == len(outcome.Spread)-1
+			events = append(events, withSlot(opponent, spreadOutcome.TargetSlot, func() []string {
+				return g.finishAttack(user, opponent, move, spreadOutcome, last)
+			})...)
+		}
+
+	default:
+		events = withSlot(opponent, outcome.TargetSlot, func() []string {
+			return g.FinishAttack(user, opponent, move, outcome)
+		})
+	}
+
+	if action.Slot == 1 {
+		user.SwapPartner()
+	}
+	for _, p := range []*player.Player{user, opponent} {
+		events = append(events, g.settleSlots(p)...)
+	}
+	return events
+}
+
+// settleSlots replaces fainted active Pokemon with healthy reserves and
+// moves a lone partner into slot 0.
+func (g *Game) settleSlots(p *player.Player) []string {
+	if !p.HasPartner {
+		return nil
+	}
+
+	var events []string
+	if event := ReplaceFainted(p); event != "" {
+		events = append(events, event)
+		events = append(events, g.Field.ApplyEntryAbility(&p.PokemonStruct)...)
+	}
+	p.SwapPartner()
+	if event := ReplaceFainted(p); event != "" {
+		events = append(events, event)
+		events = append(events, g.Field.ApplyEntryAbility(&p.PokemonStruct)...)
+	}
+	p.SwapPartner()
+
+	if IsFainted(&p.PokemonStruct) && !IsFainted(&p.Partner) {
+		p.SwapPartner()
+		events = append(events, fmt.Sprintf("%s steps up to lead %s's side!", p.PokemonStruct.Name, p.TrainerName))
+	}
+	return events
+}
+
+// ResolveTurn resolves a turn action with ResolveDoublesAction in doubles
+// and ResolveAction otherwise.
+func (g *Game) ResolveTurn(
+	user *player.Player,
+	opponent *player.Player,
+	action TurnAction,
+	defenderUsesBoost bool,
+) (AttackOutcome, error) {
+	if g.Rules.Doubles {
+		return g.ResolveDoublesAction(user, opponent, action, defenderUsesBoost)
+	}
+	return g.ResolveAction(user, opponent, action, defenderUsesBoost)
+}
+
+// FinishTurn applies an outcome from ResolveTurn.
+func (g *Game) FinishTurn(
+	user *player.Player,
+	opponent *player.Player,
+	action TurnAction,
+	outcome AttackOutcome,
+) []string {
+	if g.Rules.Doubles {
+		return g.FinishDoublesAction(user, opponent, action, outcome)
+	}
+	return g.FinishAttack(user, opponent, action.Move, outcome)
+}
+
+// TurnPokemon returns the acting Pokemon of a turn and the Pokemon it
+// targeted, for reports and display.
+func (g *Game) TurnPokemon(
+	user *player.Player,
+	opponent *player.Player,
+	action TurnAction,
+	outcome AttackOutcome,
+) (*poke.Pokemon, *poke.Pokemon) {
+	if !g.Rules.Doubles {
+		return &user.PokemonStruct, &opponent.PokemonStruct
+	}
+	acting := user.Active(action.Slot)
+	switch {
+	case action.Type != ActionAttack:
+		return acting, &opponent.PokemonStruct
+	case action.Target == TargetAlly:
+		return acting, user.Active(outcome.TargetSlot)
+	}
+	return acting, opponent.Active(outcome.TargetSlot)
+}
+
+// DescribeTarget names who a doubles attack was aimed at, e.g. "both foes".
+func (g *Game) DescribeTarget(action TurnAction, target *poke.Pokemon) string {
+	if action.Target == TargetSpread {
+		return "both foes"
+	}
+	return target.Name
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// AppliedEffect is a stat change or status from a move's scripted effects
+// that landed. It is resolved in ResolveAttack and applied in FinishAttack.
+type AppliedEffect struct {
+	OnUser bool      // The effect changes the attacker rather than the defender
+	Stat   poke.Stat // Stat changed ("" for a status)
+	Stages int       // Stat stages added
+	Status string    // Status inflicted ("" for a stat change)
+}
+
+// String encodes the effect for CALCULATION_REPORT, e.g. "target:speed:-1"
+// or "user:status:burn".
+func (e AppliedEffect) String() string {
+	who := poke.EffectTargetTarget
+	if e.OnUser {
+		who = poke.EffectTargetUser
+	}
+	if e.Status != "" {
+		return who + ":status:" + e.Status
+	}
+	return fmt.Sprintf("%s:%s:%+d", who, e.Stat, e.Stages)
+}
+
+// Describe narrates the effect, e.g. "Pikachu's speed rose!".
+func (e AppliedEffect) Describe(name string) string {
+	if e.Status != "" {
+		return fmt.Sprintf("%s is now %s!", name, strings.ToLower(poke.StatusName(e.Status)))
+	}
+	stat := strings.ReplaceAll(string(e.Stat), "_", " ")
+	switch {
+	case e.Stages >= 2:
+		return fmt.Sprintf("%s's %s rose sharply!", name, stat)
+	case e.Stages > 0:
+		return fmt.Sprintf("%s's %s rose!", name, stat)
+	case e.Stages <= -2:
+		return fmt.Sprintf("%s's %s harshly fell!", name, stat)
+	}
+	return fmt.Sprintf("%s's %s fell!", name, stat)
+}
+
+// effectEnv returns the state a move's effect conditions are checked against.
+func (g *Game) effectEnv(attacker *player.Player, defender *player.Player) poke.EffectEnv {
+	return poke.EffectEnv{
+		User:    &attacker.PokemonStruct,
+		Target:  &defender.PokemonStruct,
+		Weather: g.Field.Weather,
+		Terrain: g.Field.Terrain,
+	}
+}
+
+// effectTriggers reports whether an effect applies this time. The condition
+// is checked first, and the RNG is only used for effects with a chance below
+// 100% whose condition holds.
+func (g *Game) effectTriggers(effect poke.MoveEffect, env poke.EffectEnv) bool {
+	if !effect.Holds(env) {
+		return false
+	}
+	if effect.Chance > 0 && effect.Chance < 100 {
+		return g.RNG.Intn(100) < effect.Chance
+	}
+	return true
+}
+
+// scriptedDamageMultiplier multiplies together the damage modifiers of a
+// move's scripted effects, in the order they are listed.
+func (g *Game) scriptedDamageMultiplier(attacker *player.Player, defender *player.Player, move poke.Move) float64 {
+	multiplier := 1.0
+	env := g.effectEnv(attacker, defender)
+	for _, effect := range move.Effects {
+		if effect.DamageMultiplier > 0 && g.effectTriggers(effect, env) {
+			multiplier *= effect.DamageMultiplier
+		}
+	}
+	return multiplier
+}
+
+// resolveSecondaryEffects resolves the stat changes and statuses of a move's
+// scripted effects after its damage, in the order they are listed. Effects
+// on a fainted defender are skipped, as are statuses on a Pokemon that
+// already has one.
+func (g *Game) resolveSecondaryEffects(
+	attacker *player.Player,
+	defender *player.Player,
+	move poke.Move,
+	outcome AttackOutcome,
+) []AppliedEffect {
+	// Conditions see the HP left after the attack
+	user, target := attacker.PokemonStruct, defender.PokemonStruct
+	user.HP, target.HP = outcome.AttackerHP, outcome.DefenderHP
+	env := poke.EffectEnv{User: &user, Target: &target, Weather: g.Field.Weather, Terrain: g.Field.Terrain}
+
+	var applied []AppliedEffect
+	statused := map[bool]bool{true: user.Status != poke.StatusNone, false: target.Status != poke.StatusNone}
+	for _, effect := range move.Effects {
+		if effect.DamageMultiplier > 0 {
+			continue
+		}
+		if !effect.OnUser() && outcome.DefenderHP <= 0 {
+			continue
+		}
+		if effect.Status != "" && statused[effect.OnUser()] {
+			continue
+		}
+		if effect.Status != "" && !effect.OnUser() && target.ImmuneToStatus(effect.Status) {
+			continue
+		}
+		if !g.effectTriggers(effect, env) {
+			continue
+		}
+		if effect.Status != "" {
+			statused[effect.OnUser()] = true
+		}
+		applied = append(applied, AppliedEffect{
+			OnUser: effect.OnUser(),
+			Stat:   effect.Stat,
+			Stages: effect.Stages,
+			Status: effect.Status,
+		})
+	}
+	return applied
+}
+
+// applyEffects applies resolved scripted effects to both Pokemon and returns
+// their narration.
+func applyEffects(attacker *player.Player, defender *player.Player, effects []AppliedEffect) []string {
+	var events []string
+	for _, effect := range effects {
+		mon := &defender.PokemonStruct
+		if effect.OnUser {
+			mon = &attacker.PokemonStruct
+		}
+		if effect.Status != "" {
+			mon.Status = effect.Status
+		} else if effect.Stages = mon.ChangeStat(effect.Stat, effect.Stages); effect.Stages == 0 {
+			events = append(events, fmt.Sprintf("%s's %s won't go any further!", mon.Name, strings.ReplaceAll(string(effect.Stat), "_", " ")))
+			continue
+		}
+		events = append(events, effect.Describe(mon.Name))
+	}
+	return events
+}
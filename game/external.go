@@ -0,0 +1,431 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// ExternalReplyTimeout is how long an external bot has to answer a question
+// when no turn timer is running.
+const ExternalReplyTimeout = 30 * time.Second
+
+// ExternalBotStopTimeout is how long an external bot has to exit after its
+// input is closed before it is killed.
+const ExternalBotStopTimeout = 2 * time.Second
+
+// ExternalBotBacklog is how many lines may wait for an external bot to read
+// them. Lines beyond it are dropped, so a bot that stops reading can never
+// stall the battle.
+const ExternalBotBacklog = 64
+
+// ExternalBot is a Decider backed by a child process speaking line-delimited
+// JSON on stdin and stdout, so bots can be written in any language. Every
+// line to the process is an object with a "type":
+//
+//	state          the battle at the start of a turn: turn, your_turn, field, you, opponent
+//	choose_action  asks for an action; carries an id and the same state
+//	defense_boost  asks whether to brace; carries an id, the move and attack_boost
+//	chat           chat received, from sender, with its text
+//	game_over      the result: "win", "loss" or "draw"
+//
+// Every line from the process is an object answering the latest question,
+// with its id echoed back, and may carry chat at any time:
+//
+//	{"id": 3, "move": 2, "boost": true}  use the second move with an attack boost
+//	{"id": 3, "action": "switch 1"}      anything typed at the prompt: protect, switch, item, forfeit
+//	{"id": 4, "boost": false}            do not brace
+//	{"chat": "/gg"}                      send chat or a sticker
+//
+// Questions that go unanswered in time get the default action, or no boost.
+// The process's stderr is passed through for debugging.
+type ExternalBot struct {
+	Command string // Command line the process was started with
+
+	cmd     *exec.Cmd
+	mu      sync.Mutex  // Guards outbox, closed, nextID and turn
+	outbox  chan []byte // Lines for writeLines to send to the process
+	closed  bool        // Close has been called; outbox is closed
+	nextID  int
+	turn    int // Latest turn observed
+	replies chan externalReply
+	chat    chan string
+	done    chan struct{} // Closed when the process's output ends
+}
+
+// externalReply is a line from an external bot.
+type externalReply struct {
+	ID     int    `json:"id"`     // Question answered; 0 answers whichever is open
+	Move   int    `json:"move"`   // 1-based move to use
+	Action string `json:"action"` // Any other action, as typed at the prompt
+	Boost  *bool  `json:"boost"`  // Spend a boost on the move, or brace
+	Chat   string `json:"chat"`   // Chat to send
+}
+
+// isAnswer reports whether the reply answers a question, not just chats.
+func (r externalReply) isAnswer() bool {
+	return r.Move != 0 || r.Action != "" || r.Boost != nil
+}
+
+// externalMessage is a line to an external bot.
+type externalMessage struct {
+	Type        string           `json:"type"`
+	ID          int              `json:"id,omitempty"`
+	Turn        int              `json:"turn,omitempty"`
+	YourTurn    bool             `json:"your_turn,omitempty"`
+	Field       *externalField   `json:"field,omitempty"`
+	You         *externalTrainer `json:"you,omitempty"`
+	Opponent    *externalTrainer `json:"opponent,omitempty"`
+	Move        *externalMove    `json:"move,omitempty"`
+	AttackBoost bool             `json:"attack_boost,omitempty"`
+	Sender      string           `json:"sender,omitempty"`
+	Text        string           `json:"text,omitempty"`
+	Result      string           `json:"result,omitempty"`
+}
+
+type externalField struct {
+	Weather string `json:"weather"` // "" when clear
+	Terrain string `json:"terrain"` // "" when none
+}
+
+type externalTrainer struct {
+	Name     string            `json:"name"`
+	Active   externalPokemon   `json:"active"`
+	Partner  *externalPokemon  `json:"partner,omitempty"`
+	Reserves []externalPokemon `json:"reserves,omitempty"`
+	Boosts   externalBoosts    `json:"boosts"`
+	Bag      map[string]int    `json:"bag,omitempty"`
+}
+
+type externalBoosts struct {
+	SpecialAttack   int `json:"special_attack"`
+	SpecialDefense  int `json:"special_defense"`
+	PhysicalAttack  int `json:"physical_attack"`
+	PhysicalDefense int `json:"physical_defense"`
+}
+
+type externalPokemon struct {
+	Species  string         `json:"species"`
+	Level    int            `json:"level"`
+	HP       int            `json:"hp"`
+	MaxHP    int            `json:"max_hp"`
+	Status   string         `json:"status"`
+	Types    []string       `json:"types"`
+	HeldItem string         `json:"held_item,omitempty"`
+	Moves    []externalMove `json:"moves,omitempty"`
+}
+
+type externalMove struct {
+	Index    int     `json:"index,omitempty"` // 1-based, as the reply's "move"
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Category string  `json:"category"`
+	Power    float64 `json:"power"`
+	Priority int     `json:"priority,omitempty"`
+}
+
+// StartExternalBot starts command, split on spaces, as an external bot.
+func StartExternalBot(command string) (*ExternalBot, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no command given for the external bot")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start %q: %w", command, err)
+	}
+
+	e := &ExternalBot{
+		Command: command,
+		cmd:     cmd,
+		outbox:  make(chan []byte, ExternalBotBacklog),
+		replies: make(chan externalReply, 16),
+		chat:    make(chan string, 16),
+		done:    make(chan struct{}),
+	}
+	go e.writeLines(stdin)
+	go e.readReplies(stdout)
+	return e, nil
+}
+
+// writeLines writes the queued lines to the process's input until Close,
+// then closes it. Only this goroutine blocks on a bot that stops reading.
+// Lines written after the process has gone are discarded.
+func (e *ExternalBot) writeLines(stdin io.WriteCloser) {
+	defer stdin.Close()
+	for line := range e.outbox {
+		stdin.Write(line)
+	}
+}
+
+// readReplies reads the process's lines until its output ends, passing
+// chat and answers on. Lines that are not JSON objects are reported and
+// skipped.
+func (e *ExternalBot) readReplies(stdout io.Reader) {
+	defer close(e.done)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var reply externalReply
+		if err := json.Unmarshal([]byte(line), &reply); err != nil {
+			fmt.Printf("[external bot] Ignoring %q: %v\n", line, err)
+			continue
+		}
+		if reply.Chat != "" {
+			select {
+			case e.chat <- reply.Chat:
+			default: // Nobody is reading chat fast enough; drop it
+			}
+		}
+		if reply.isAnswer() {
+			select {
+			case e.replies <- reply:
+			default: // Answers nobody asked for; drop them
+			}
+		}
+	}
+}
+
+// Name labels the external bot's choices.
+func (e *ExternalBot) Name() string {
+	return "external bot"
+}
+
+// Chat delivers the chat the process sends.
+func (e *ExternalBot) Chat() <-chan string {
+	return e.chat
+}
+
+// ChooseAction asks the process for self's action. A move index or action
+// the engine rejects, or no answer in time, plays the default action.
+func (e *ExternalBot) ChooseAction(g *Game, self *player.Player, foe *player.Player) TurnAction {
+	e.mu.Lock()
+	turn := e.turn
+	e.mu.Unlock()
+	msg := externalState("choose_action", g, self, foe, turn)
+	msg.YourTurn = true
+
+	reply, ok := e.ask(msg, g.Clock.Expired(), ExternalReplyTimeout)
+	if !ok {
+		action := DefaultAction(self)
+		fmt.Printf("[external bot] No answer. %s.\n", action.Describe(self.PokemonStruct.Name))
+		return action
+	}
+
+	input := reply.Action
+	if input == "" {
+		input = strconv.Itoa(reply.Move)
+	}
+	action, problem := parseTurnAction(input, self)
+	if problem != "" {
+		action = DefaultAction(self)
+		fmt.Printf("[external bot] %s (answered %q). %s.\n", problem, input, action.Describe(self.PokemonStruct.Name))
+		return action
+	}
+	action.AttackBoost = action.Type == ActionAttack && reply.Boost != nil && *reply.Boost && canAttackBoost(g, self, action.Move)
+	return action
+}
+
+// UseDefenseBoost asks the process whether self braces against attacker's
+// move. No answer within DefenseBoostTimeout counts as "no".
+func (e *ExternalBot) UseDefenseBoost(g *Game, self *player.Player, attacker *player.Player, move poke.Move, attackBoost bool) bool {
+	category := move.DamageCategory
+	if !move.IsDamaging() || !g.Rules.CanBoost(category) || *self.DefenseBoostsLeft(category) <= 0 {
+		return false
+	}
+	incoming := newExternalMove(move, 0)
+	reply, ok := e.ask(externalMessage{Type: "defense_boost", Move: &incoming, AttackBoost: attackBoost}, nil, DefenseBoostTimeout)
+	return ok && reply.Boost != nil && *reply.Boost
+}
+
+// ObserveTurn sends the process the state at the start of a turn.
+func (e *ExternalBot) ObserveTurn(g *Game, self *player.Player, foe *player.Player, turn int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.turn = turn
+	msg := externalState("state", g, self, foe, turn)
+	msg.YourTurn = g.Side(g.CurrentTurn) == self
+	e.send(msg)
+}
+
+// ObserveChat passes received chat to the process.
+func (e *ExternalBot) ObserveChat(sender string, text string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.send(externalMessage{Type: "chat", Sender: sender, Text: text})
+}
+
+// ObserveEnd tells the process how the battle ended.
+func (e *ExternalBot) ObserveEnd(result poke.BattleResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.send(externalMessage{Type: "game_over", Result: string(result)})
+}
+
+// Close closes the process's input once the queued lines are written and
+// waits for it to exit, killing it if it takes longer than
+// ExternalBotStopTimeout.
+func (e *ExternalBot) Close() error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.outbox)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+	case <-time.After(ExternalBotStopTimeout):
+		e.cmd.Process.Kill()
+	}
+	return e.cmd.Wait()
+}
+
+// ask sends a question and waits for its answer until expired fires or
+// timeout passes. Answers to earlier questions are skipped.
+func (e *ExternalBot) ask(msg externalMessage, expired <-chan time.Time, timeout time.Duration) (externalReply, bool) {
+	e.mu.Lock()
+	e.nextID++
+	msg.ID = e.nextID
+	err := e.send(msg)
+	e.mu.Unlock()
+	if err != nil {
+		return externalReply{}, false
+	}
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case reply := <-e.replies:
+			if reply.ID != 0 && reply.ID != msg.ID {
+				continue
+			}
+			return reply, true
+		case <-e.done:
+			return externalReply{}, false
+		case <-expired:
+			return externalReply{}, false
+		case <-deadline:
+			return externalReply{}, false
+		}
+	}
+}
+
+// send queues a line for the process without waiting for it to be read.
+// A full backlog drops the line. The caller holds mu.
+func (e *ExternalBot) send(msg externalMessage) error {
+	if e.closed {
+		return fmt.Errorf("external bot is closed")
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	select {
+	case e.outbox <- append(line, '\n'):
+		return nil
+	default:
+		return fmt.Errorf("external bot is not reading its input")
+	}
+}
+
+// externalState builds a message carrying the battle's state as self sees
+// it. The foe's moves, reserves and bag are left out.
+func externalState(msgType string, g *Game, self *player.Player, foe *player.Player, turn int) externalMessage {
+	you := newExternalTrainer(self, true)
+	opponent := newExternalTrainer(foe, false)
+	return externalMessage{
+		Type:     msgType,
+		Turn:     turn,
+		Field:    &externalField{Weather: g.Field.Weather, Terrain: g.Field.Terrain},
+		You:      &you,
+		Opponent: &opponent,
+	}
+}
+
+// newExternalTrainer describes a trainer. Only our own trainer's moves,
+// reserves and bag are included.
+func newExternalTrainer(p *player.Player, own bool) externalTrainer {
+	t := externalTrainer{
+		Name:   p.Peer.Name,
+		Active: newExternalPokemon(&p.PokemonStruct, own),
+		Boosts: externalBoosts{
+			SpecialAttack:   p.SpecialAttackUsesLeft,
+			SpecialDefense:  p.SpecialDefenseUsesLeft,
+			PhysicalAttack:  p.PhysicalAttackUsesLeft,
+			PhysicalDefense: p.PhysicalDefenseUsesLeft,
+		},
+	}
+	if p.HasPartner {
+		partner := newExternalPokemon(&p.Partner, own)
+		t.Partner = &partner
+	}
+	if own {
+		for i := range p.Reserves {
+			t.Reserves = append(t.Reserves, newExternalPokemon(&p.Reserves[i], true))
+		}
+		if p.Bag.Count() > 0 {
+			t.Bag = p.Bag
+		}
+	}
+	return t
+}
+
+// newExternalPokemon describes a Pokemon, with its moves if they are ours.
+func newExternalPokemon(mon *poke.Pokemon, withMoves bool) externalPokemon {
+	desc := externalPokemon{
+		Species:  mon.Name,
+		Level:    mon.Level,
+		HP:       mon.HP,
+		MaxHP:    mon.MaxHP,
+		Status:   poke.StatusName(mon.Status),
+		Types:    []string{mon.Type1},
+		HeldItem: mon.HeldItem,
+	}
+	if mon.Type2 != "" {
+		desc.Types = append(desc.Types, mon.Type2)
+	}
+	if withMoves {
+		for i, move := range mon.Moves {
+			desc.Moves = append(desc.Moves, newExternalMove(move, i+1))
+		}
+	}
+	return desc
+}
+
+// newExternalMove describes a move; index is its 1-based place in the
+// moveset, or 0 for the foe's.
+func newExternalMove(move poke.Move, index int) externalMove {
+	return externalMove{
+		Index:    index,
+		Name:     move.Name,
+		Type:     move.Type,
+		Category: move.DamageCategory,
+		Power:    move.BasePower,
+		Priority: move.Priority,
+	}
+}
@@ -0,0 +1,210 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// Standing reports whether the trainer in a free-for-all seat can still
+// battle. Fainted Pokemon are replaced as soon as they faint, so a trainer
+// whose active Pokemon is down has nothing left.
+func (g *Game) Standing(seat int) bool {
+	return !IsFainted(&g.Players[seat].PokemonStruct)
+}
+
+// StandingSeats returns the seats still in the free-for-all, lowest first.
+func (g *Game) StandingSeats() []int {
+	var seats []int
+	for seat := range g.Players {
+		if g.Standing(seat) {
+			seats = append(seats, seat)
+		}
+	}
+	return seats
+}
+
+// seatOrder returns the standing seats, fastest active Pokemon first. Ties
+// go to the lower seat.
+func (g *Game) seatOrder() []int {
+	order := g.StandingSeats()
+	slices.SortStableFunc(order, func(a, b int) int {
+		return g.Players[b].PokemonStruct.BattleStat(poke.StatSpeed) - g.Players[a].PokemonStruct.BattleStat(poke.StatSpeed)
+	})
+	return order
+}
+
+// DescribeSeatOrder lists the trainers in speed order, e.g.
+// "Ash (Jolteon) > Misty (Starmie) > Brock (Onix)".
+func (g *Game) DescribeSeatOrder() string {
+	var names []string
+	for _, seat := range g.seatOrder() {
+		p := g.Players[seat]
+		names = append(names, fmt.Sprintf("%s (%s)", p.Peer.Name, p.PokemonStruct.Name))
+	}
+	return strings.Join(names, " > ")
+}
+
+// NextSeat returns the seat that acts next in a free-for-all and whether it
+// opens a new round. Every standing trainer acts once per round, fastest
+// first; trainers knocked out mid-round are skipped. A raid boss takes its
+// extra actions at the end of the round. Only the host calls it, since it
+// decides the order for everyone.
+func (g *Game) NextSeat() (int, bool) {
+	newRound := false
+	for {
+		if len(g.SeatOrder) == 0 {
+			g.SeatOrder = g.seatOrder()
+			newRound = true
+			if g.Rules.Raid() && len(g.SeatOrder) > 0 {
+				for range g.Rules.RaidActions - 1 {
+					g.SeatOrder = append(g.SeatOrder, g.BossSeat())
+				}
+			}
+			if len(g.SeatOrder) == 0 {
+				return 0, newRound
+			}
+		}
+		seat := g.SeatOrder[0]
+		g.SeatOrder = g.SeatOrder[1:]
+		if g.Standing(seat) {
+			return seat, newRound
+		}
+	}
+}
+
+// FirstFoe returns the first standing seat seat may attack, which is where
+// actions without a target are aimed.
+func (g *Game) FirstFoe(seat int) int {
+	for _, other := range g.StandingSeats() {
+		if g.ValidTarget(seat, other) {
+			return other
+		}
+	}
+	return seat
+}
+
+// ValidTarget reports whether seat may aim an attack at target. In a raid
+// the trainers may only attack the boss, and the boss only the trainers.
+func (g *Game) ValidTarget(seat int, target int) bool {
+	if target == seat || target < 0 || target >= len(g.Players) || !g.Standing(target) {
+		return false
+	}
+	if g.Rules.Raid() {
+		return (seat == g.BossSeat()) != (target == g.BossSeat())
+	}
+	return true
+}
+
+// ResolveSeatAction resolves a free-for-all action of one seat against
+// another. It consumes the RNG exactly as ResolveAction does.
+func (g *Game) ResolveSeatAction(seat int, target int, action TurnAction) (AttackOutcome, error) {
+	return g.ResolveAction(g.Players[seat], g.Players[target], action, false)
+}
+
+// FinishSeatAction applies a resolved free-for-all action and records any
+// trainers it knocked out. Returns the field events and the seats that were
+// eliminated by the action.
+func (g *Game) FinishSeatAction(seat int, target int, action TurnAction, outcome AttackOutcome) ([]string, []int) {
+	events := g.FinishAttack(g.Players[seat], g.Players[target], action.Move, outcome)
+	return events, g.eliminate()
+}
+
+// Forfeit knocks out every Pokemon of a free-for-all seat. Returns the seats
+// eliminated by it.
+func (g *Game) Forfeit(seat int) []int {
+	p := g.Players[seat]
+	p.PokemonStruct.HP = 0
+	for i := range p.Reserves {
+		p.Reserves[i].HP = 0
+	}
+	return g.eliminate()
+}
+
+// eliminate records the trainers that can no longer battle, lowest seat
+// first when several go down together.
+func (g *Game) eliminate() []int {
+	var out []int
+	for seat := range g.Players {
+		if !g.Standing(seat) && !slices.Contains(g.Eliminated, seat) {
+			out = append(out, seat)
+		}
+	}
+	g.Eliminated = append(g.Eliminated, out...)
+	return out
+}
+
+// FreeForAllOver reports whether at most one trainer is left standing.
+func (g *Game) FreeForAllOver() bool {
+	return len(g.StandingSeats()) <= 1
+}
+
+// Placements ranks every trainer from first to last. Trainers still standing
+// come first, ordered by the share of their team's HP left (a battle stopped
+// at the turn limit may leave several); the eliminated follow, last out
+// placing highest.
+func (g *Game) Placements() []*player.Player {
+	standing := g.StandingSeats()
+	slices.SortStableFunc(standing, func(a, b int) int {
+		aHP, aMax := g.Players[a].TeamHP()
+		bHP, bMax := g.Players[b].TeamHP()
+		return bHP*max(aMax, 1) - aHP*max(bMax, 1)
+	})
+
+	var placements []*player.Player
+	for _, seat := range standing {
+		placements = append(placements, g.Players[seat])
+	}
+	for i := len(g.Eliminated) - 1; i >= 0; i-- {
+		placements = append(placements, g.Players[g.Eliminated[i]])
+	}
+	return placements
+}
+
+// Standings lists every trainer's active Pokemon and HP, e.g.
+// "Ash: Pikachu 35/35; Misty: Starmie out".
+func (g *Game) Standings() string {
+	parts := make([]string, len(g.Players))
+	for seat, p := range g.Players {
+		parts[seat] = p.Peer.Name + ": " + standingOf(&p.PokemonStruct)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// standingOf describes one trainer's active Pokemon for Standings.
+func standingOf(mon *poke.Pokemon) string {
+	if IsFainted(mon) {
+		return mon.Name + " out"
+	}
+	return fmt.Sprintf("%s %d/%d", mon.Name, mon.HP, mon.MaxHP)
+}
+
+// HPBar renders a Pokemon's HP as a 20-cell bar, e.g.
+// "Pikachu     [##########----------] 50/100".
+func HPBar(name string, hp int, maxHP int) string {
+	const width = 20
+	filled := 0
+	if maxHP > 0 {
+		filled = max(hp, 0) * width / maxHP
+	}
+	if hp > 0 && filled == 0 {
+		filled = 1 // A Pokemon that can still battle always shows some HP
+	}
+	return fmt.Sprintf("%-12s [%s%s] %d/%d", name, strings.Repeat("#", filled), strings.Repeat("-", width-filled), hp, maxHP)
+}
+
+// Ordinal returns a placement as "1st", "2nd", "3rd" or "4th".
+func Ordinal(place int) string {
+	switch place {
+	case 1:
+		return "1st"
+	case 2:
+		return "2nd"
+	case 3:
+		return "3rd"
+	}
+	return fmt.Sprintf("%dth", place)
+}
@@ -0,0 +1,804 @@
+package game
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/messages"
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/peer"
+	"github.com/zrygan/pokemonbattler/poke"
+	"github.com/zrygan/pokemonbattler/reliability"
+)
+
+// FreeForAllGrace is how much longer than the turn timer the host waits for
+// a joiner's action, to allow for the network.
+const FreeForAllGrace = 2 * time.Second
+
+// ffaContext contains what a peer needs to play a free-for-all or a raid.
+// The host is the ordering authority: it hands out turns with FFA_TURN,
+// resolves every action, plays the raid boss, and relays the ATTACK_ANNOUNCE
+// and CALCULATION_REPORT to everyone. Joiners apply the same actions in the
+// same order and check the reports.
+type ffaContext struct {
+	bc        *BattleContext // Game, SelfPlayer and sequence numbers; OpponentAddr is the host
+	seat      int            // Seat of the local trainer
+	inputChan chan string    // Lines typed by the local trainer
+}
+
+// RunFreeForAll runs a free-for-all for the trainer in seat. players holds
+// every trainer by seat, the host in seat 0. With a decider, such as a bot,
+// it plays the trainer in seat.
+func RunFreeForAll(
+	players []*player.Player,
+	seat int,
+	seed int,
+	commMode string,
+	r rules.Ruleset,
+	spectators []peer.PeerDescriptor,
+	decider Decider,
+) {
+	runHostOrdered(players, seat, seed, commMode, r, nil, spectators, decider)
+}
+
+// RunRaid runs a raid for the trainer in seat. players holds both trainers
+// by seat, the host in seat 0; the boss the ruleset describes takes the
+// last seat. script is the boss's move script, which only the host has.
+// With a decider, it plays the trainer in seat.
+func RunRaid(
+	players []*player.Player,
+	seat int,
+	seed int,
+	commMode string,
+	r rules.Ruleset,
+	script []string,
+	spectators []peer.PeerDescriptor,
+	decider Decider,
+) {
+	boss, err := NewRaidBoss(r)
+	if err != nil {
+		panic(err)
+	}
+	runHostOrdered(append(players, boss), seat, seed, commMode, r, script, spectators, decider)
+}
+
+// runHostOrdered runs a free-for-all or raid for the trainer in seat.
+func runHostOrdered(
+	players []*player.Player,
+	seat int,
+	seed int,
+	commMode string,
+	r rules.Ruleset,
+	script []string,
+	spectators []peer.PeerDescriptor,
+	decider Decider,
+) {
+	game := NewGame(seed, commMode, r)
+	game.Players = players
+	game.BossScript = script
+	game.Host = players[0]
+	for _, spec := range spectators {
+		game.AddSpectator(spec)
+	}
+
+	selfPlayer := players[seat]
+	fc := &ffaContext{
+		bc: &BattleContext{
+			Game:         game,
+			SelfPlayer:   selfPlayer,
+			OpponentAddr: players[0].Peer.Addr,
+			ReliableConn: reliability.NewReliableConnection(selfPlayer.Peer.Conn),
+			IsHost:       seat == 0,
+			Decider:      decider,
+		},
+		seat: seat,
+	}
+	game.State = StateWaitingForMove
+
+	if r.Raid() {
+		fmt.Printf("\n=== RAID START: %s ===\n", r.RaidBoss)
+	} else {
+		fmt.Printf("\n=== FREE-FOR-ALL START (%d trainers) ===\n", len(players))
+	}
+	if len(spectators) > 0 {
+		fmt.Printf("%d spectator(s) watching this battle\n", len(spectators))
+	}
+	if selfPlayer.Profile != nil {
+		poke.ShowPreBattleMessage(selfPlayer.Profile)
+	}
+	for i, p := range players {
+		if r.Raid() && i == game.BossSeat() {
+			fmt.Printf("Boss: %s Lv. %d (HP: %d/%d), %d action(s) per round\n",
+				p.PokemonStruct.Name, p.PokemonStruct.Level, p.PokemonStruct.HP, p.PokemonStruct.MaxHP, r.RaidActions)
+			continue
+		}
+		you := ""
+		if i == seat {
+			you = " (you)"
+		}
+		fmt.Printf("Seat %d: %s%s - %s Lv. %d (HP: %d/%d)\n", i+1, p.Peer.Name, you,
+			p.PokemonStruct.Name, p.PokemonStruct.Level, p.PokemonStruct.HP, p.PokemonStruct.MaxHP)
+	}
+
+	// Entry abilities set weather or terrain in seat order
+	for _, p := range players {
+		showFieldEvents(game.Field.ApplyEntryAbility(&p.PokemonStruct))
+	}
+
+	if selfPlayer.PokemonStruct.HeldItem != "" {
+		fmt.Printf("Held item: %s\n", selfPlayer.PokemonStruct.HeldItem)
+	}
+	if selfPlayer.Bag.Count() > 0 {
+		fmt.Printf("Bag: %s (type 'item <name>' on your turn to use one)\n", selfPlayer.Bag)
+	}
+	fmt.Printf("Format: %s\n", r.DescribeFormat())
+	fmt.Printf("Boost rules: %s (attack boosts only)\n", r.DescribeBoosts())
+	fmt.Printf("Damage model: %s\n", r.DamageModel)
+	fmt.Printf("Mode: %s\n", r.Mode())
+	if r.TurnTimer > 0 {
+		fmt.Printf("Turn timer: %ds\n", r.TurnTimer)
+	}
+	fmt.Println("\nTip: Type 'chat <message>', use stickers like '/gg', or send image files with 'esticker <filepath>'!")
+	fmt.Println()
+
+	fc.inputChan = netio.StartInputListener()
+
+	var gameOver map[string]any
+	if fc.bc.IsHost {
+		gameOver = fc.hostLoop()
+	} else {
+		gameOver = fc.joinerLoop()
+	}
+	game.State = StateGameOver
+
+	var won bool
+	if r.Raid() {
+		won = fc.showRaidResult(gameOver)
+	} else {
+		won = fc.showPlacements(gameOver)
+	}
+
+	fmt.Println("\nBATTLE LOG:")
+	for i, entry := range game.BattleLog {
+		fmt.Printf("%d. %s\n", i+1, entry)
+	}
+	fmt.Println()
+
+	// Only first place, or clearing the raid, counts as a win
+	result := poke.ResultLoss
+	if won {
+		result = poke.ResultWin
+	}
+	if observer := observerOf(decider); observer != nil {
+		observer.ObserveEnd(result)
+	}
+	if selfPlayer.Profile != nil {
+		teamManager := poke.NewTeamManager(selfPlayer.TrainerName)
+		if err := teamManager.UpdateProfileAfterBattle(selfPlayer.Profile, result); err != nil {
+			fmt.Printf("Warning: Could not save profile: %v\n", err)
+		}
+	}
+}
+
+// showPlacements shows the placements of a free-for-all GAME_OVER and logs
+// them. Returns whether the local trainer placed first.
+func (fc *ffaContext) showPlacements(gameOver map[string]any) bool {
+	game := fc.bc.Game
+	placementsParam, _ := gameOver["placements"].(string)
+	placements := strings.Split(placementsParam, ",")
+
+	fmt.Println("\n=== FREE-FOR-ALL END ===")
+	for i, name := range placements {
+		fmt.Printf("%s: %s\n", Ordinal(i+1), name)
+	}
+	game.BattleLog = append(game.BattleLog, "Placements: "+strings.Join(placements, ", "))
+	return placements[0] == fc.bc.SelfPlayer.Peer.Name
+}
+
+// showRaidResult shows whether a raid GAME_OVER cleared the raid and logs
+// it. Returns whether the trainers won.
+func (fc *ffaContext) showRaidResult(gameOver map[string]any) bool {
+	game := fc.bc.Game
+	cleared := fmt.Sprint(gameOver["raid_cleared"]) == "true"
+	boss := game.Players[game.BossSeat()].PokemonStruct
+
+	fmt.Println("\n=== RAID END ===")
+	fmt.Println(HPBar(boss.Name, boss.HP, boss.MaxHP))
+	if cleared {
+		fmt.Printf("Raid cleared! %s defeated %s!\n", game.RaidTrainers(), boss.Name)
+		game.BattleLog = append(game.BattleLog, fmt.Sprintf("Raid cleared: %s defeated %s", game.RaidTrainers(), boss.Name))
+	} else {
+		fmt.Printf("Raid failed! %s held off %s.\n", boss.Name, game.RaidTrainers())
+		game.BattleLog = append(game.BattleLog, fmt.Sprintf("Raid failed: %s held off %s", boss.Name, game.RaidTrainers()))
+	}
+	return cleared
+}
+
+// hostLoop hands out turns until the battle is decided or the turn limit is
+// reached, then broadcasts the GAME_OVER: placements in a free-for-all, the
+// outcome in a raid. Returns the GAME_OVER parameters.
+func (fc *ffaContext) hostLoop() map[string]any {
+	game := fc.bc.Game
+	reason := messages.ReasonFainted
+
+	for turn := 1; ; turn++ {
+		seat, newRound := game.NextSeat()
+		if newRound {
+			fmt.Printf("\nNew round! Speed order: %s\n", game.DescribeSeatOrder())
+		}
+		fmt.Printf("\n--- Turn %d ---\n", turn)
+		fmt.Printf("Field: %s\n", game.Field)
+		fc.broadcast(messages.MakeFFATurn(seat, turn, fc.bc.ReliableConn.GetNextSequenceNumber()))
+
+		var action TurnAction
+		var target int
+		if game.Rules.Raid() && seat == game.BossSeat() {
+			action, target = game.BossAction()
+		} else if seat == fc.seat {
+			action, target = fc.chooseAction()
+		} else {
+			fmt.Printf("%s's turn... waiting...\n", game.Players[seat].Peer.Name)
+			action, target = fc.awaitAction(seat)
+		}
+		fc.hostPlay(seat, target, action)
+
+		if over, _ := game.RaidOver(); game.Rules.Raid() && over {
+			break
+		}
+		if !game.Rules.Raid() && game.FreeForAllOver() {
+			break
+		}
+		if game.TurnLimitReached(turn) {
+			reason = messages.ReasonTurnLimit
+			fmt.Printf("\nTurn limit of %d reached!\n", game.Rules.TurnLimit)
+			game.BattleLog = append(game.BattleLog, fmt.Sprintf("Turn limit of %d reached", game.Rules.TurnLimit))
+			break
+		}
+	}
+
+	var gameOverMsg messages.Message
+	if game.Rules.Raid() {
+		_, cleared := game.RaidOver()
+		boss := game.Players[game.BossSeat()].PokemonStruct.Name
+		gameOverMsg = messages.MakeRaidGameOver(game.RaidTrainers(), boss, cleared, reason, fc.bc.ReliableConn.GetNextSequenceNumber())
+	} else {
+		var placements []string
+		for _, p := range game.Placements() {
+			placements = append(placements, p.Peer.Name)
+		}
+		gameOverMsg = messages.MakePlacementGameOver(placements, reason, fc.bc.ReliableConn.GetNextSequenceNumber())
+	}
+	fc.broadcast(gameOverMsg)
+
+	netio.VerboseEventLog(
+		"PokeProtocol: Sent GAME_OVER message to every trainer",
+		&netio.LogOptions{
+			MessageParams: gameOverMsg.MessageParams,
+		},
+	)
+	return *gameOverMsg.MessageParams
+}
+
+// awaitAction waits for the ATTACK_ANNOUNCE of the joiner in seat. A joiner
+// that does not answer within the turn timer plays its default action.
+// Attacks aimed at an invalid seat are redirected to the first foe.
+func (fc *ffaContext) awaitAction(seat int) (TurnAction, int) {
+	game := fc.bc.Game
+	actor := game.Players[seat]
+	var expired <-chan time.Time
+	if game.Rules.TurnTimer > 0 {
+		expired = time.After(time.Duration(game.Rules.TurnTimer)*time.Second + FreeForAllGrace)
+	}
+
+	var action TurnAction
+	target := game.FirstFoe(seat)
+	answered := fc.wait(expired, nil, func(msg *messages.Message, addr *net.UDPAddr) bool {
+		if msg.MessageType != messages.AttackAnnounce || addr.String() != actor.Peer.Addr.String() {
+			return false
+		}
+
+		netio.VerboseEventLog(
+			"PokeProtocol: Host Peer received ATTACK_ANNOUNCE from '"+actor.Peer.Name+"'",
+			&netio.LogOptions{
+				MessageParams: msg.MessageParams,
+				MS:            addr.String(),
+			},
+		)
+
+		action = fc.bc.actionFromAnnounce(*msg.MessageParams, &actor.PokemonStruct)
+		if targetSeat, ok := (*msg.MessageParams)["target_seat"].(int); ok && action.Type == ActionAttack && game.ValidTarget(seat, targetSeat) {
+			target = targetSeat
+		}
+		return true
+	})
+	if !answered {
+		action = DefaultAction(actor)
+		fmt.Printf("\n%s ran out of time! %s.\n", actor.Peer.Name, action.Describe(actor.PokemonStruct.Name))
+	}
+	return action, target
+}
+
+// hostPlay resolves a seat's action, relays it to everyone and applies it.
+// Actions that do not resolve, such as a boost the trainer does not have,
+// are replaced by the trainer's default action.
+func (fc *ffaContext) hostPlay(seat int, target int, action TurnAction) {
+	game := fc.bc.Game
+	actor := game.Players[seat]
+
+	if action.Type != ActionForfeit {
+		// Only attacks use the RNG, and they always resolve, so checking the
+		// other actions here leaves the RNG in step with the joiners
+		err := fc.checkBoost(actor, action)
+		if err == nil && action.Type != ActionAttack {
+			_, err = game.ResolveSeatAction(seat, target, action)
+		}
+		if err != nil {
+			fmt.Printf("\n%s's action is invalid (%v); using its default action.\n", actor.Peer.Name, err)
+			action = DefaultAction(actor)
+		}
+		if action.Type != ActionAttack {
+			target = game.FirstFoe(seat)
+		}
+	}
+
+	announce := makeActionAnnounce(action, fc.bc.ReliableConn.GetNextSequenceNumber())
+	if action.Type == ActionForfeit {
+		announce = messages.MakeForfeitAnnounce(fc.bc.ReliableConn.GetNextSequenceNumber())
+	}
+	messages.AddSeats(announce, seat, target)
+	fc.broadcast(announce)
+
+	if action.Type == ActionForfeit {
+		fc.forfeit(seat)
+		return
+	}
+
+	report, err := fc.play(seat, target, action)
+	if err != nil {
+		fmt.Printf("Error during turn: %v\n", err)
+		return
+	}
+	messages.AddSeats(report, seat, target)
+	fc.broadcast(report)
+
+	netio.VerboseEventLog(
+		"PokeProtocol: Sent CALCULATION_REPORT to every trainer",
+		&netio.LogOptions{
+			MessageParams: report.MessageParams,
+		},
+	)
+}
+
+// joinerLoop follows the host's turns until GAME_OVER. Returns the
+// GAME_OVER parameters.
+func (fc *ffaContext) joinerLoop() map[string]any {
+	game := fc.bc.Game
+	hostAddr := fc.bc.OpponentAddr.String()
+	var pending *messages.Message
+	var gameOver map[string]any
+
+	fc.wait(nil, nil, func(msg *messages.Message, addr *net.UDPAddr) bool {
+		if addr.String() != hostAddr {
+			return false
+		}
+		params := *msg.MessageParams
+
+		switch msg.MessageType {
+		case messages.FFATurn:
+			seat, _ := params["seat"].(int)
+			turn, _ := params["turn"].(int)
+			if seat < 0 || seat >= len(game.Players) {
+				return false
+			}
+			fmt.Printf("\n--- Turn %d ---\n", turn)
+			fmt.Printf("Field: %s\n", game.Field)
+			if seat != fc.seat {
+				fmt.Printf("%s's turn... waiting...\n", game.Players[seat].Peer.Name)
+				return false
+			}
+
+			action, target := fc.chooseAction()
+			announce := makeActionAnnounce(action, fc.bc.ReliableConn.GetNextSequenceNumber())
+			if action.Type == ActionForfeit {
+				announce = messages.MakeForfeitAnnounce(fc.bc.ReliableConn.GetNextSequenceNumber())
+			}
+			messages.AddSeats(announce, seat, target)
+			fc.bc.SelfPlayer.Peer.Conn.WriteToUDP(announce.SerializeMessage(), fc.bc.OpponentAddr)
+
+			netio.VerboseEventLog(
+				"PokeProtocol: Sent ATTACK_ANNOUNCE to Host Peer",
+				&netio.LogOptions{
+					MessageParams: announce.MessageParams,
+				},
+			)
+
+		case messages.AttackAnnounce:
+			// Forfeits come without a report; everything else waits for one
+			seat, _ := params["seat"].(int)
+			if params["action"] == messages.ActionForfeit && seat >= 0 && seat < len(game.Players) {
+				fc.forfeit(seat)
+				return false
+			}
+			pending = msg
+
+		case messages.CalculationReport:
+			if pending == nil {
+				return false
+			}
+			fc.applyReport(pending, msg)
+			pending = nil
+
+		case messages.GameOver:
+			netio.VerboseEventLog(
+				"PokeProtocol: Received GAME_OVER from Host Peer",
+				&netio.LogOptions{
+					MessageParams: msg.MessageParams,
+				},
+			)
+			if reason, _ := params["reason"].(string); reason == messages.ReasonTurnLimit {
+				fmt.Printf("\nTurn limit of %d reached!\n", game.Rules.TurnLimit)
+			}
+			gameOver = params
+			return true
+		}
+		return false
+	})
+	return gameOver
+}
+
+// applyReport applies a relayed action and checks our calculation against
+// the host's report.
+func (fc *ffaContext) applyReport(announce *messages.Message, hostReport *messages.Message) {
+	game := fc.bc.Game
+	params := *announce.MessageParams
+	seat, _ := params["seat"].(int)
+	target, _ := params["target_seat"].(int)
+	if seat < 0 || seat >= len(game.Players) || target < 0 || target >= len(game.Players) {
+		fmt.Printf("WARNING: Host announced an action for an unknown seat\n")
+		return
+	}
+
+	action := fc.bc.actionFromAnnounce(params, &game.Players[seat].PokemonStruct)
+	myReport, err := fc.play(seat, target, action)
+	if err != nil {
+		fmt.Printf("WARNING: %s's action does not resolve here: %v\n", game.Players[seat].Peer.Name, err)
+		return
+	}
+	if !fc.bc.verifyCalculations(myReport, hostReport) {
+		fmt.Printf("WARNING: Calculation discrepancy with the host!\n")
+		fmt.Printf("Host calc: %v damage, %v HP remaining\n",
+			(*hostReport.MessageParams)["damage_dealt"], (*hostReport.MessageParams)["defender_hp_remaining"])
+		fmt.Printf("My calc: %v damage, %v HP remaining\n",
+			(*myReport.MessageParams)["damage_dealt"], (*myReport.MessageParams)["defender_hp_remaining"])
+	}
+}
+
+// play resolves and applies a seat's action on the local copy of the
+// battle, shows what happened and returns the calculation report for it.
+func (fc *ffaContext) play(seat int, target int, action TurnAction) (messages.Message, error) {
+	game := fc.bc.Game
+	actor, defender := game.Players[seat], game.Players[target]
+
+	if err := fc.checkBoost(actor, action); err != nil {
+		return messages.Message{}, err
+	}
+	outcome, err := game.ResolveSeatAction(seat, target, action)
+	if err != nil {
+		return messages.Message{}, err
+	}
+	if action.AttackBoost {
+		*actor.AttackBoostsLeft(action.Move.DamageCategory)--
+	}
+
+	actingPokemon, targetPokemon := &actor.PokemonStruct, &defender.PokemonStruct
+	actorName, targetName := actingPokemon.Name, targetPokemon.Name
+	fmt.Printf("\n%s\n", describeAction(actorName, action.Move, outcome))
+	if action.Type == ActionAttack {
+		fmt.Printf("%s targeted %s's %s!\n", actor.Peer.Name, defender.Peer.Name, targetName)
+	}
+	fc.bc.announceOutcome(actor, actorName, targetName, outcome)
+
+	events, eliminated := game.FinishSeatAction(seat, target, action, outcome)
+	showFieldEvents(events)
+
+	report := fc.bc.makeCalculationReport(actingPokemon, targetPokemon, action.Move, outcome, events,
+		fc.bc.ReliableConn.GetNextSequenceNumber())
+
+	logEntry := fmt.Sprintf("%s's %s used %s on %s's %s and dealt %d damage",
+		actor.Peer.Name, actorName, action.Move.Name, defender.Peer.Name, targetName, outcome.Damage)
+	if action.Type != ActionAttack {
+		logEntry = actor.Peer.Name + ": " + describeAction(actorName, action.Move, outcome)
+	}
+	game.BattleLog = append(game.BattleLog, logEntry)
+
+	fc.showEliminated(eliminated)
+	fmt.Printf("Standings: %s\n", game.Standings())
+	if game.Rules.Raid() {
+		boss := game.Players[game.BossSeat()].PokemonStruct
+		fmt.Printf("Boss: %s\n", HPBar(boss.Name, boss.HP, boss.MaxHP))
+	}
+	return report, nil
+}
+
+// checkBoost returns an error if an action spends an attack boost the
+// trainer does not have.
+func (fc *ffaContext) checkBoost(actor *player.Player, action TurnAction) error {
+	if !action.AttackBoost {
+		return nil
+	}
+	category := action.Move.DamageCategory
+	if action.Type != ActionAttack || *actor.AttackBoostsLeft(category) <= 0 || !fc.bc.Game.Rules.CanBoost(category) {
+		return fmt.Errorf("%s used an attack boost it does not have", actor.Peer.Name)
+	}
+	return nil
+}
+
+// forfeit knocks a seat out of the free-for-all.
+func (fc *ffaContext) forfeit(seat int) {
+	game := fc.bc.Game
+	name := game.Players[seat].Peer.Name
+	if seat == fc.seat {
+		fmt.Println("\nYou forfeited the match.")
+	} else {
+		fmt.Printf("\n%s forfeited!\n", name)
+	}
+	game.BattleLog = append(game.BattleLog, fmt.Sprintf("%s forfeited!", name))
+	fc.showEliminated(game.Forfeit(seat))
+}
+
+// showEliminated announces trainers that were knocked out.
+func (fc *ffaContext) showEliminated(seats []int) {
+	game := fc.bc.Game
+	for _, seat := range seats {
+		name := game.Players[seat].Peer.Name
+		switch {
+		case game.Rules.Raid() && seat == game.BossSeat():
+			fmt.Printf("\nThe raid boss %s is down!\n", game.Players[seat].PokemonStruct.Name)
+		case game.Rules.Raid() && seat == fc.seat:
+			fmt.Println("\nYou have no Pokemon left! You are out of the raid.")
+		case seat == fc.seat:
+			fmt.Println("\nYou have no Pokemon left! You are out of the free-for-all.")
+		default:
+			fmt.Printf("\n%s has no Pokemon left and is out!\n", name)
+		}
+		game.BattleLog = append(game.BattleLog, fmt.Sprintf("%s was eliminated", name))
+	}
+}
+
+// chooseAction asks the local trainer for an action and, for attacks, a
+// target and an attack boost. Running out of time plays the default action
+// at the first foe. A decider always aims at the first foe.
+func (fc *ffaContext) chooseAction() (TurnAction, int) {
+	game := fc.bc.Game
+	self := fc.bc.SelfPlayer
+
+	if decider := fc.bc.Decider; decider != nil {
+		target := game.FirstFoe(fc.seat)
+		action := decider.ChooseAction(game, self, game.Players[target])
+		fmt.Printf("[%s] %s\n", decider.Name(), action.Describe(self.PokemonStruct.Name))
+		return action, target
+	}
+
+	fmt.Println("Your turn!")
+	showActionMenu(self, true)
+	var expired <-chan time.Time
+	if game.Rules.TurnTimer > 0 {
+		expired = time.After(time.Duration(game.Rules.TurnTimer) * time.Second)
+		fmt.Printf("Time: %ds\n", game.Rules.TurnTimer)
+	}
+
+	var action TurnAction
+	chosen := fc.wait(expired, func(input string) bool {
+		var problem string
+		action, problem = parseTurnAction(input, self)
+		if problem != "" {
+			fmt.Println(problem)
+			return false
+		}
+		return true
+	}, nil)
+	if !chosen {
+		action = DefaultAction(self)
+		fmt.Printf("\nTime's up! %s.\n", action.Describe(self.PokemonStruct.Name))
+		return action, game.FirstFoe(fc.seat)
+	}
+	if action.Type != ActionAttack {
+		return action, game.FirstFoe(fc.seat)
+	}
+
+	target := fc.chooseTarget(expired)
+
+	category := action.Move.DamageCategory
+	if boostsLeft := *self.AttackBoostsLeft(category); action.Move.IsDamaging() && game.Rules.CanBoost(category) && boostsLeft > 0 {
+		fmt.Printf("Use a %s Attack boost? (y/n, %d left): \n", categoryName(category), boostsLeft)
+		fc.wait(expired, func(input string) bool {
+			action.AttackBoost = strings.EqualFold(input, "y")
+			return true
+		}, nil)
+	}
+	return action, target
+}
+
+// chooseTarget asks which standing trainer to attack. With one foe left,
+// as always in a raid, there is nothing to ask.
+func (fc *ffaContext) chooseTarget(expired <-chan time.Time) int {
+	game := fc.bc.Game
+	var foes []int
+	for _, seat := range game.StandingSeats() {
+		if game.ValidTarget(fc.seat, seat) {
+			foes = append(foes, seat)
+		}
+	}
+	if len(foes) == 1 {
+		return foes[0]
+	}
+
+	fmt.Println("Choose a target:")
+	for i, seat := range foes {
+		p := game.Players[seat]
+		fmt.Printf("  %d. %s's %s (HP: %d/%d)\n", i+1, p.Peer.Name, p.PokemonStruct.Name, p.PokemonStruct.HP, p.PokemonStruct.MaxHP)
+	}
+
+	target := foes[0]
+	fc.wait(expired, func(input string) bool {
+		n, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || n < 1 || n > len(foes) {
+			fmt.Println("Invalid target. Please try again.")
+			return false
+		}
+		target = foes[n-1]
+		return true
+	}, nil)
+	return target
+}
+
+// wait reads typed lines and incoming messages until a handler reports that
+// it is done, or until expired fires. Chat is handled here, so it works at
+// any point of the battle. Returns false if the wait expired.
+func (fc *ffaContext) wait(
+	expired <-chan time.Time,
+	onInput func(input string) bool,
+	onMessage func(msg *messages.Message, addr *net.UDPAddr) bool,
+) bool {
+	conn := fc.bc.SelfPlayer.Peer.Conn
+	buf := make([]byte, 100000) // Large enough for estickers
+	deciderChat := chatOf(fc.bc.Decider)
+
+	for {
+		select {
+		case <-expired:
+			return false
+		case text := <-deciderChat:
+			fc.sendChat(text)
+		case input := <-fc.inputChan:
+			if input == "" {
+				continue
+			}
+			if strings.HasPrefix(input, "chat ") || strings.HasPrefix(input, "esticker ") || strings.HasPrefix(input, "/") {
+				fc.sendChat(input)
+				continue
+			}
+			if onInput != nil && onInput(input) {
+				return true
+			}
+		default:
+			conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+			n, addr, err := conn.ReadFromUDP(buf)
+			conn.SetReadDeadline(time.Time{})
+			if err != nil {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+
+			msg := messages.DeserializeMessage(buf[:n])
+			if msg.MessageType == messages.ChatMessage {
+				fc.receiveChat(msg, buf[:n], addr)
+				continue
+			}
+			if onMessage != nil && onMessage(msg, addr) {
+				return true
+			}
+		}
+	}
+}
+
+// sendChat sends a chat message, sticker or esticker. Joiners send to the
+// host, which relays to everyone else.
+func (fc *ffaContext) sendChat(input string) {
+	contentType := "TEXT"
+	messageText := strings.TrimPrefix(input, "chat ")
+	stickerData := ""
+	displayText := messageText
+
+	if isEsticker, filePath := IsEstickerCommand(input); isEsticker {
+		base64Data, err := LoadEsticker(filePath)
+		if err != nil {
+			fmt.Printf("Error loading esticker: %v\n", err)
+			return
+		}
+		contentType, stickerData, messageText = "STICKER", base64Data, ""
+		displayText = fmt.Sprintf("[Encoded Sticker: %s]", filepath.Base(filePath))
+	} else if stickerText, exists := Stickers[strings.ToLower(input)]; exists {
+		contentType, stickerData, messageText = "STICKER", input, ""
+		displayText = stickerText
+	}
+
+	msg := messages.MakeChatMessage(
+		fc.bc.SelfPlayer.Peer.Name,
+		contentType,
+		messageText,
+		stickerData,
+		fc.bc.ReliableConn.GetNextSequenceNumber(),
+	)
+	if fc.bc.IsHost {
+		fc.broadcast(msg)
+	} else {
+		fc.bc.SelfPlayer.Peer.Conn.WriteToUDP(msg.SerializeMessage(), fc.bc.OpponentAddr)
+	}
+
+	netio.VerboseEventLog(
+		"PokeProtocol: Sent CHAT_MESSAGE",
+		&netio.LogOptions{
+			MessageParams: msg.MessageParams,
+		},
+	)
+
+	if contentType == "STICKER" {
+		fmt.Printf("You sent sticker: %s\n", displayText)
+	} else {
+		fmt.Printf("You: %s\n", displayText)
+	}
+}
+
+// receiveChat shows an incoming chat message. The host relays it to every
+// other trainer and spectator.
+func (fc *ffaContext) receiveChat(msg *messages.Message, msgBytes []byte, senderAddr *net.UDPAddr) {
+	netio.VerboseEventLog(
+		"PokeProtocol: Received CHAT_MESSAGE during battle",
+		&netio.LogOptions{
+			MessageParams: msg.MessageParams,
+			MS:            senderAddr.String(),
+		},
+	)
+
+	showChat(*msg.MessageParams)
+	if observer := observerOf(fc.bc.Decider); observer != nil {
+		senderName, _ := (*msg.MessageParams)["sender_name"].(string)
+		observer.ObserveChat(senderName, chatText(*msg.MessageParams))
+	}
+	if fc.bc.IsHost {
+		fc.relay(msgBytes, senderAddr)
+	}
+}
+
+// broadcast sends a message from the host to every joiner and spectator.
+func (fc *ffaContext) broadcast(msg messages.Message) {
+	fc.relay(msg.SerializeMessage(), nil)
+}
+
+// relay sends raw message bytes from the host to every joiner and spectator
+// except the one at exceptAddr, which may be nil. The raid boss has no
+// address and is skipped.
+func (fc *ffaContext) relay(msgBytes []byte, exceptAddr *net.UDPAddr) {
+	conn := fc.bc.SelfPlayer.Peer.Conn
+	except := ""
+	if exceptAddr != nil {
+		except = exceptAddr.String()
+	}
+	for _, p := range fc.bc.Game.Players[1:] {
+		if p.Peer.Addr != nil && p.Peer.Addr.String() != except {
+			conn.WriteToUDP(msgBytes, p.Peer.Addr)
+		}
+	}
+	for _, spectator := range fc.bc.Game.Spectators {
+		if spectator.Addr.String() != except {
+			conn.WriteToUDP(msgBytes, spectator.Addr)
+		}
+	}
+}
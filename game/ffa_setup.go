@@ -0,0 +1,199 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/messages"
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/peer"
+)
+
+// Host_sendRoster tells every joiner of a free-for-all who is battling and
+// which seat is theirs. The host sits in seat 0 and joiners follow in the
+// order they were accepted.
+func Host_sendRoster(host peer.PeerDescriptor, joiners []peer.PeerDescriptor) {
+	trainers := []string{host.Name}
+	for _, join := range joiners {
+		trainers = append(trainers, join.Name)
+	}
+
+	for i, join := range joiners {
+		msg := messages.MakeRoster(trainers, i+1)
+		host.Conn.WriteToUDP(msg.SerializeMessage(), join.Addr)
+
+		netio.VerboseEventLog(
+			"PokeProtocol: Host Peer sent FFA_ROSTER message to Joiner Peer '"+join.Name+"'",
+			&netio.LogOptions{
+				MessageParams: msg.MessageParams,
+			},
+		)
+	}
+}
+
+// Joiner_getRoster waits for the host's FFA_ROSTER and returns the joiner's
+// seat and the trainers in seat order.
+func Joiner_getRoster(p peer.PeerDescriptor) (int, []string) {
+	buf := make([]byte, 65535)
+	for {
+		n, _, err := p.Conn.ReadFromUDP(buf)
+		if err != nil {
+			panic(err)
+		}
+		msg := messages.DeserializeMessage(buf[:n])
+		if msg.MessageType != messages.FFARoster {
+			continue
+		}
+
+		netio.VerboseEventLog(
+			"PokeProtocol: Joiner Peer received FFA_ROSTER message from Host Peer",
+			&netio.LogOptions{
+				MessageParams: msg.MessageParams,
+			},
+		)
+
+		params := *msg.MessageParams
+		seat, _ := params["seat"].(int)
+		trainersParam, _ := params["trainers"].(string)
+		trainers := strings.Split(trainersParam, ",")
+		if seat < 1 || seat >= len(trainers) {
+			panic(fmt.Sprintf("Invalid seat from host: %d", seat))
+		}
+		return seat, trainers
+	}
+}
+
+// Host_FreeForAllSetup sends the host's BATTLE_SETUP to every joiner and
+// spectator, then collects each joiner's BATTLE_SETUP and relays it to the
+// others tagged with the sender's seat and name. Returns every player by seat.
+func Host_FreeForAllSetup(self player.Player, joiners []peer.PeerDescriptor, cmode string, r rules.Ruleset, spectators []peer.PeerDescriptor) []*player.Player {
+	msg := makeSetupMessage(self, cmode)
+	(*msg.MessageParams)["seat"] = 0
+	(*msg.MessageParams)["trainer"] = self.Peer.Name
+	msgBytes := msg.SerializeMessage()
+	for _, join := range joiners {
+		self.Peer.Conn.WriteToUDP(msgBytes, join.Addr)
+	}
+	for _, spectator := range spectators {
+		self.Peer.Conn.WriteToUDP(msgBytes, spectator.Addr)
+	}
+
+	netio.VerboseEventLog(
+		"PokeProtocol: Host Peer sent BATTLE_SETUP message to every Joiner Peer",
+		&netio.LogOptions{
+			MessageParams: msg.MessageParams,
+		},
+	)
+
+	players := make([]*player.Player, len(joiners)+1)
+	players[0] = &self
+
+	buf := make([]byte, 65535)
+	for received := 0; received < len(joiners); {
+		n, addr, err := self.Peer.Conn.ReadFromUDP(buf)
+		if err != nil {
+			panic(err)
+		}
+
+		res := messages.DeserializeMessage(buf[:n])
+		if res.MessageType != messages.BattleSetup {
+			continue
+		}
+		seat := seatOf(joiners, addr.String())
+		if seat < 0 || players[seat] != nil {
+			continue
+		}
+
+		netio.VerboseEventLog(
+			"PokeProtocol: Host Peer received BATTLE_SETUP from '"+joiners[seat-1].Name+"'",
+			&netio.LogOptions{
+				MessageParams: res.MessageParams,
+			},
+		)
+
+		opponent := playerFromSetup(*res.MessageParams, joiners[seat-1], r)
+		opponent.TrainerName = joiners[seat-1].Name
+		players[seat] = &opponent
+		received++
+
+		// Relay the setup to everyone else so all peers track every trainer
+		(*res.MessageParams)["seat"] = seat
+		(*res.MessageParams)["trainer"] = joiners[seat-1].Name
+		relayed := res.SerializeMessage()
+		for i, join := range joiners {
+			if i+1 != seat {
+				self.Peer.Conn.WriteToUDP(relayed, join.Addr)
+			}
+		}
+		for _, spectator := range spectators {
+			self.Peer.Conn.WriteToUDP(relayed, spectator.Addr)
+		}
+	}
+	return players
+}
+
+// Joiner_FreeForAllSetup sends the joiner's BATTLE_SETUP to the host and
+// waits until the host has relayed every other trainer's setup. Returns
+// every player by seat.
+func Joiner_FreeForAllSetup(self player.Player, host peer.PeerDescriptor, seat int, trainers []string, cmode string, r rules.Ruleset) []*player.Player {
+	msg := makeSetupMessage(self, cmode)
+	self.Peer.Conn.WriteToUDP(msg.SerializeMessage(), host.Addr)
+
+	netio.VerboseEventLog(
+		"PokeProtocol: Joiner Peer sent BATTLE_SETUP message to Host Peer",
+		&netio.LogOptions{
+			MessageParams: msg.MessageParams,
+		},
+	)
+
+	players := make([]*player.Player, len(trainers))
+	players[seat] = &self
+
+	buf := make([]byte, 65535)
+	for received := 1; received < len(trainers); {
+		n, _, err := self.Peer.Conn.ReadFromUDP(buf)
+		if err != nil {
+			panic(err)
+		}
+
+		res := messages.DeserializeMessage(buf[:n])
+		if res.MessageType != messages.BattleSetup {
+			continue
+		}
+		from, ok := (*res.MessageParams)["seat"].(int)
+		if !ok || from < 0 || from >= len(trainers) || players[from] != nil {
+			continue
+		}
+
+		netio.VerboseEventLog(
+			"PokeProtocol: Joiner Peer received BATTLE_SETUP for seat "+fmt.Sprint(from),
+			&netio.LogOptions{
+				MessageParams: res.MessageParams,
+			},
+		)
+
+		// Only the host's address is known; everything goes through it
+		other := peer.MakePD(trainers[from], nil, nil)
+		if from == 0 {
+			other = host
+		}
+		opponent := playerFromSetup(*res.MessageParams, other, r)
+		opponent.TrainerName = trainers[from]
+		players[from] = &opponent
+		received++
+	}
+	return players
+}
+
+// seatOf returns the seat of the joiner at addr, or -1 if it is not one of
+// the joiners.
+func seatOf(joiners []peer.PeerDescriptor, addr string) int {
+	for i, join := range joiners {
+		if join.Addr.String() == addr {
+			return i + 1
+		}
+	}
+	return -1
+}
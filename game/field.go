@@ -0,0 +1,267 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// Weather values. WeatherNone means clear skies.
+const (
+	WeatherNone = ""
+	WeatherSun  = "sun"
+	WeatherRain = "rain"
+	WeatherSand = "sand"
+	WeatherHail = "hail"
+)
+
+// Terrain values. TerrainNone means no terrain is active.
+const (
+	TerrainNone     = ""
+	TerrainElectric = "electric"
+	TerrainGrassy   = "grassy"
+	TerrainPsychic  = "psychic"
+	TerrainMisty    = "misty"
+)
+
+// FieldEffectTurns is how many turns weather and terrain last once set.
+const FieldEffectTurns = 5
+
+// fieldAbilities maps abilities that set weather or terrain when the Pokemon enters battle.
+var fieldAbilities = map[string]struct{ Weather, Terrain string }{
+	"Drought":        {Weather: WeatherSun},
+	"Drizzle":        {Weather: WeatherRain},
+	"Sand Stream":    {Weather: WeatherSand},
+	"Snow Warning":   {Weather: WeatherHail},
+	"Electric Surge": {Terrain: TerrainElectric},
+	"Grassy Surge":   {Terrain: TerrainGrassy},
+	"Psychic Surge":  {Terrain: TerrainPsychic},
+	"Misty Surge":    {Terrain: TerrainMisty},
+}
+
+// Field is the shared battlefield state. Both peers update it in the same
+// order, so it stays synchronized without being sent over the network.
+type Field struct {
+	Weather      string // Active weather (WeatherNone when clear)
+	WeatherTurns int    // Turns of weather remaining
+	Terrain      string // Active terrain (TerrainNone when none)
+	TerrainTurns int    // Turns of terrain remaining
+}
+
+// SetWeather starts a weather for FieldEffectTurns turns.
+// Returns false if that weather is already active.
+func (f *Field) SetWeather(weather string) bool {
+	if weather == WeatherNone || f.Weather == weather {
+		return false
+	}
+	f.Weather = weather
+	f.WeatherTurns = FieldEffectTurns
+	return true
+}
+
+// SetTerrain starts a terrain for FieldEffectTurns turns.
+// Returns false if that terrain is already active.
+func (f *Field) SetTerrain(terrain string) bool {
+	if terrain == TerrainNone || f.Terrain == terrain {
+		return false
+	}
+	f.Terrain = terrain
+	f.TerrainTurns = FieldEffectTurns
+	return true
+}
+
+// ApplyMove applies the weather or terrain a move sets and returns what changed.
+func (f *Field) ApplyMove(move poke.Move) []string {
+	var events []string
+	if f.SetWeather(move.Weather) {
+		events = append(events, weatherStartText(move.Weather))
+	}
+	if f.SetTerrain(move.Terrain) {
+		events = append(events, terrainName(move.Terrain)+" covered the battlefield!")
+	}
+	return events
+}
+
+// ApplyEntryAbility sets weather or terrain from a Pokemon's entry ability.
+// The first field-setting ability in the Pokemon's ability list is used.
+func (f *Field) ApplyEntryAbility(pokemon *poke.Pokemon) []string {
+	for _, ability := range pokemon.Abilities {
+		effect, ok := fieldAbilities[ability]
+		if !ok {
+			continue
+		}
+
+		var events []string
+		if f.SetWeather(effect.Weather) {
+			events = append(events, fmt.Sprintf("%s's %s: %s", pokemon.Name, ability, weatherStartText(effect.Weather)))
+		}
+		if f.SetTerrain(effect.Terrain) {
+			events = append(events, fmt.Sprintf("%s's %s: %s covered the battlefield!", pokemon.Name, ability, terrainName(effect.Terrain)))
+		}
+		return events
+	}
+	return nil
+}
+
+// DamageMultiplier returns the weather and terrain multiplier for a move.
+func (f Field) DamageMultiplier(move poke.Move) float64 {
+	multiplier := 1.0
+
+	switch f.Weather {
+	case WeatherSun:
+		if move.Type == "fire" {
+			multiplier *= 1.5
+		} else if move.Type == "water" {
+			multiplier *= 0.5
+		}
+	case WeatherRain:
+		if move.Type == "water" {
+			multiplier *= 1.5
+		} else if move.Type == "fire" {
+			multiplier *= 0.5
+		}
+	}
+
+	switch f.Terrain {
+	case TerrainElectric:
+		if move.Type == "electric" {
+			multiplier *= 1.3
+		}
+	case TerrainGrassy:
+		if move.Type == "grass" {
+			multiplier *= 1.3
+		}
+	case TerrainPsychic:
+		if move.Type == "psychic" {
+			multiplier *= 1.3
+		}
+	case TerrainMisty:
+		if move.Type == "dragon" {
+			multiplier *= 0.5
+		}
+	}
+
+	return multiplier
+}
+
+// EndOfTurn applies residual weather damage and terrain healing to the given
+// Pokemon, then counts down the remaining turns. Returns what happened.
+func (f *Field) EndOfTurn(pokemon ...*poke.Pokemon) []string {
+	var events []string
+
+	for _, mon := range pokemon {
+		if mon.HP <= 0 {
+			continue
+		}
+
+		switch {
+		case f.Weather == WeatherSand && !hasAnyType(mon, "rock", "ground", "steel"):
+			damage := max(mon.MaxHP/16, 1)
+			ApplyDamage(mon, damage)
+			events = append(events, fmt.Sprintf("%s is buffeted by the sandstorm! (-%d HP)", mon.Name, damage))
+		case f.Weather == WeatherHail && !hasAnyType(mon, "ice"):
+			damage := max(mon.MaxHP/16, 1)
+			ApplyDamage(mon, damage)
+			events = append(events, fmt.Sprintf("%s is pelted by hail! (-%d HP)", mon.Name, damage))
+		}
+
+		if f.Terrain == TerrainGrassy && mon.HP > 0 && mon.HP < mon.MaxHP {
+			heal := min(max(mon.MaxHP/16, 1), mon.MaxHP-mon.HP)
+			mon.HP += heal
+			events = append(events, fmt.Sprintf("%s is healed by the grassy terrain! (+%d HP)", mon.Name, heal))
+		}
+	}
+
+	if f.Weather != WeatherNone {
+		f.WeatherTurns--
+		if f.WeatherTurns <= 0 {
+			events = append(events, weatherEndText(f.Weather))
+			f.Weather = WeatherNone
+			f.WeatherTurns = 0
+		}
+	}
+	if f.Terrain != TerrainNone {
+		f.TerrainTurns--
+		if f.TerrainTurns <= 0 {
+			events = append(events, terrainName(f.Terrain)+" faded away.")
+			f.Terrain = TerrainNone
+			f.TerrainTurns = 0
+		}
+	}
+
+	return events
+}
+
+// String describes the active field effects, e.g. "Rain (3 turns), Grassy Terrain (2 turns)".
+func (f Field) String() string {
+	var parts []string
+	if f.Weather != WeatherNone {
+		parts = append(parts, fmt.Sprintf("%s (%d turns)", weatherName(f.Weather), f.WeatherTurns))
+	}
+	if f.Terrain != TerrainNone {
+		parts = append(parts, fmt.Sprintf("%s (%d turns)", terrainName(f.Terrain), f.TerrainTurns))
+	}
+	if len(parts) == 0 {
+		return "Clear"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func hasAnyType(pokemon *poke.Pokemon, types ...string) bool {
+	for _, t := range types {
+		if pokemon.Type1 == t || pokemon.Type2 == t {
+			return true
+		}
+	}
+	return false
+}
+
+func weatherName(weather string) string {
+	switch weather {
+	case WeatherSun:
+		return "Harsh Sunlight"
+	case WeatherRain:
+		return "Rain"
+	case WeatherSand:
+		return "Sandstorm"
+	case WeatherHail:
+		return "Hail"
+	}
+	return "Clear"
+}
+
+func weatherStartText(weather string) string {
+	switch weather {
+	case WeatherSun:
+		return "The sunlight turned harsh!"
+	case WeatherRain:
+		return "It started to rain!"
+	case WeatherSand:
+		return "A sandstorm kicked up!"
+	case WeatherHail:
+		return "It started to hail!"
+	}
+	return ""
+}
+
+func weatherEndText(weather string) string {
+	switch weather {
+	case WeatherSun:
+		return "The sunlight faded."
+	case WeatherRain:
+		return "The rain stopped."
+	case WeatherSand:
+		return "The sandstorm subsided."
+	case WeatherHail:
+		return "The hail stopped."
+	}
+	return ""
+}
+
+func terrainName(terrain string) string {
+	if terrain == TerrainNone {
+		return "No Terrain"
+	}
+	return strings.ToUpper(terrain[:1]) + terrain[1:] + " Terrain"
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+)
+
+// countingSource is the seeded source behind Game.RNG. It counts its draws
+// so a copy of the game can pick up the RNG at the same position.
+type countingSource struct {
+	src    rand.Source64
+	newSrc func(seed int64) rand.Source64 // Makes src, to replay it in a fork
+	seed   int64
+	draws  int
+}
+
+// newCountingSource returns a source seeded like rand.NewSource(seed).
+func newCountingSource(seed int64) *countingSource {
+	return countingFrom(seed, func(seed int64) rand.Source64 {
+		return rand.NewSource(seed).(rand.Source64)
+	})
+}
+
+// countingFrom returns a counting source over the source newSrc makes.
+func countingFrom(seed int64, newSrc func(seed int64) rand.Source64) *countingSource {
+	return &countingSource{src: newSrc(seed), newSrc: newSrc, seed: seed}
+}
+
+// Int63 draws the next number.
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+// Uint64 draws the next number. It advances the source as Int63 does.
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+// Seed reseeds the source and restarts the count.
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.draws = 0
+}
+
+// fork returns a source at the same position as s.
+func (s *countingSource) fork() *countingSource {
+	forked := countingFrom(s.seed, s.newSrc)
+	for range s.draws {
+		forked.Int63()
+	}
+	return forked
+}
+
+// Fork returns a copy of the battle to look ahead in. Both players, the
+// field, the turn order and the RNG position are copied, so resolving and
+// finishing actions on the copy gives exactly the outcomes the real battle
+// would and leaves it untouched. Spectators, the clock and the battle log
+// are not copied; only duels can be forked.
+func (g *Game) Fork() *Game {
+	forked := g.forkState()
+	forked.rngSource = g.rngSource.fork()
+	forked.RNG = rand.New(forked.rngSource)
+	return forked
+}
+
+// forkState copies everything Fork does but the RNG.
+func (g *Game) forkState() *Game {
+	return &Game{
+		Host:              forkPlayer(g.Host),
+		Joiner:            forkPlayer(g.Joiner),
+		Seed:              g.Seed,
+		CommunicationMode: g.CommunicationMode,
+		Rules:             g.Rules,
+		TypeChart:         g.TypeChart,
+		Field:             g.Field,
+		State:             g.State,
+		CurrentTurn:       g.CurrentTurn,
+		TurnsThisRound:    g.TurnsThisRound,
+		Clock:             NewClock(g.Rules),
+	}
+}
+
+// SideOf returns "host" or "joiner" for a player of a duel.
+func (g *Game) SideOf(p *player.Player) string {
+	if p == g.Joiner {
+		return "joiner"
+	}
+	return "host"
+}
+
+// forkPlayer copies a player deeply enough that finishing actions on the
+// copy never changes the original: the bag and the reserves are cloned.
+func forkPlayer(p *player.Player) *player.Player {
+	if p == nil {
+		return nil
+	}
+	forked := *p
+	forked.Bag = maps.Clone(p.Bag)
+	forked.Reserves = slices.Clone(p.Reserves)
+	return &forked
+}
@@ -0,0 +1,48 @@
+package game
+
+// CriticalMultiplier is the damage multiplier applied to critical hits.
+const CriticalMultiplier = 1.5
+
+// Friendship thresholds for battle effects (friendship ranges from 0 to 100).
+const (
+	FriendshipGreat = 70 // "Great Friends" and above
+	FriendshipBest  = 90 // "Best Friends"
+)
+
+// FriendshipCritChance returns the chance that an attack is a critical hit.
+// Every Pokemon has the base 1/24 chance; close friends crit more often.
+func FriendshipCritChance(friendship int) float64 {
+	switch {
+	case friendship >= FriendshipBest:
+		return 1.0 / 8
+	case friendship >= FriendshipGreat:
+		return 1.0 / 12
+	default:
+		return 1.0 / 24
+	}
+}
+
+// FriendshipEndureChance returns the chance that a Pokemon survives a KO hit at 1 HP.
+func FriendshipEndureChance(friendship int) float64 {
+	switch {
+	case friendship >= FriendshipBest:
+		return 0.2
+	case friendship >= FriendshipGreat:
+		return 0.1
+	default:
+		return 0
+	}
+}
+
+// FriendshipShakeOffChance returns the chance that a Pokemon shakes off its
+// status condition at the end of a turn so its trainer won't worry.
+func FriendshipShakeOffChance(friendship int) float64 {
+	switch {
+	case friendship >= FriendshipBest:
+		return 0.2
+	case friendship >= FriendshipGreat:
+		return 0.1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,180 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// PassTerminal hides the screen from the trainer handing the terminal over
+// and waits until name has it.
+func PassTerminal(name string) {
+	netio.PRLine(fmt.Sprintf("\nPass the terminal to %s, then press Enter.", name))
+	netio.ClearScreen()
+	fmt.Printf("=== %s's turn at the terminal ===\n", name)
+}
+
+// RunHotSeatBattle runs a duel between two trainers sharing one terminal,
+// with the same engine and prompts as a practice battle, on PlayDuel. Every
+// choice is made by the trainer holding the terminal, and the screen is
+// cleared whenever it changes hands, so neither sees the other's menu, bag
+// or boosts. Results are shown to both. Afterwards both profiles are
+// updated on their battle records. Returns the winner, or nil for a draw.
+func RunHotSeatBattle(host *player.Player, joiner *player.Player, seed int, r rules.Ruleset) *player.Player {
+	game := NewGame(seed, P2P, LocalRules(r))
+	game.Host = host
+	game.Joiner = joiner
+	game.State = StateWaitingForMove
+
+	fmt.Printf("\n=== HOT-SEAT BATTLE: %s vs %s ===\n", host.Peer.Name, joiner.Peer.Name)
+	for _, p := range []*player.Player{host, joiner} {
+		if p.Profile != nil {
+			poke.ShowPreBattleMessage(p.Profile)
+		}
+		fmt.Printf("%s sends out %s Lv. %d (HP: %d/%d)\n",
+			p.Peer.Name, p.PokemonStruct.Name, p.PokemonStruct.Level, p.PokemonStruct.HP, p.PokemonStruct.MaxHP)
+	}
+	showFieldEvents(game.Field.ApplyEntryAbility(&host.PokemonStruct))
+	showFieldEvents(game.Field.ApplyEntryAbility(&joiner.PokemonStruct))
+
+	view := &hotSeatView{
+		battleCtx: &BattleContext{Game: game, SelfPlayer: host, IsHost: true},
+		briefed:   map[*player.Player]bool{},
+	}
+	end := PlayDuel(game, terminalDecider{}, terminalDecider{}, view, 0)
+
+	// Both trainers were real, so both battle records count it
+	for _, p := range []*player.Player{host, joiner} {
+		if p.Profile == nil {
+			continue
+		}
+		fmt.Printf("\n--- %s ---\n", p.Peer.Name)
+		teamManager := poke.NewTeamManager(p.TrainerName)
+		if err := teamManager.UpdateProfileAfterBattle(p.Profile, duelResult(end, p)); err != nil {
+			fmt.Printf("Warning: Could not save profile: %v\n", err)
+		}
+	}
+	return end.Winner
+}
+
+// hotSeatView shows a duel to two trainers sharing the terminal, handing it
+// to each before they choose.
+type hotSeatView struct {
+	battleCtx *BattleContext // For the personality messages of the holder
+	holder    *player.Player // Has the terminal
+	briefed   map[*player.Player]bool
+	turn      int // The turn being played
+}
+
+// take hands the terminal to p. Each trainer sees the rules and their own
+// boosts the first time they get it, and where the battle stands after.
+func (v *hotSeatView) take(g *Game, p *player.Player) {
+	if v.holder == p {
+		return
+	}
+	PassTerminal(p.Peer.Name)
+	v.holder = p
+	v.battleCtx.SelfPlayer = p
+	foe := g.Side(otherSide(g.SideOf(p)))
+	if !v.briefed[p] {
+		v.briefed[p] = true
+		showBattlers(p, foe)
+		showBattleRules(g, p, foe)
+	} else {
+		showHotSeatStatus(p, foe)
+	}
+	v.showTurn(g)
+}
+
+func (v *hotSeatView) TurnStarted(g *Game, turn int) {
+	v.turn = turn
+	v.showTurn(g)
+}
+
+// showTurn shows the turn header, again after the terminal changes hands.
+func (v *hotSeatView) showTurn(g *Game) {
+	fmt.Printf("\n--- Turn %d ---\n", v.turn)
+	fmt.Printf("Field: %s\n", g.Field)
+}
+
+func (v *hotSeatView) Choosing(g *Game, p *player.Player) {
+	v.take(g, p)
+}
+
+func (v *hotSeatView) RoundOpened(g *Game, opener *player.Player) {
+	showOpener(opener)
+}
+
+func (v *hotSeatView) ActionChosen(g *Game, attacker *player.Player, action TurnAction, rejected error) {
+	if rejected != nil {
+		fmt.Printf("Can't do that: %v. Using the default action.\n", rejected)
+	}
+}
+
+func (v *hotSeatView) DefenseBoostAsked(g *Game, defender *player.Player) {
+	v.take(g, defender)
+}
+
+func (v *hotSeatView) ActionResolved(g *Game, attacker *player.Player, defender *player.Player, action TurnAction, defenseBoost bool, outcome AttackOutcome) {
+	move := action.Move
+	if defenseBoost {
+		fmt.Printf("%s's %s Defense boost used! (%d left)\n",
+			defender.Peer.Name, categoryName(move.DamageCategory), *defender.DefenseBoostsLeft(move.DamageCategory))
+	}
+
+	actorName, targetName := attacker.PokemonStruct.Name, defender.PokemonStruct.Name
+	fmt.Printf("\n%s\n", describeAction(actorName, move, outcome))
+	v.battleCtx.announceOutcome(attacker, actorName, targetName, outcome)
+}
+
+func (v *hotSeatView) TurnFinished(g *Game, attacker *player.Player, defender *player.Player, events []string) {
+	showFieldEvents(events)
+
+	fmt.Println()
+	for _, p := range []*player.Player{g.Host, g.Joiner} {
+		fmt.Printf("%s's Pokemon: %s (HP: %d/%d, %s)\n",
+			p.Peer.Name, p.PokemonStruct.Name, p.PokemonStruct.HP, p.PokemonStruct.MaxHP, poke.StatusName(p.PokemonStruct.Status))
+	}
+
+	// Show low HP warning if HP is below 30%
+	for _, p := range []*player.Player{g.Host, g.Joiner} {
+		hpPercent := float64(p.PokemonStruct.HP) / float64(p.PokemonStruct.MaxHP)
+		if hpPercent < 0.3 && hpPercent > 0 && p.Profile != nil {
+			poke.ShowLowHealthMessage(p.Profile)
+		}
+	}
+}
+
+func (v *hotSeatView) DuelEnded(g *Game, end DuelEnd) {
+	host, joiner := g.Host, g.Joiner
+	switch end.Reason {
+	case DuelForfeit:
+		fmt.Printf("\n%s forfeited the match. %s wins!\n", end.Loser.Peer.Name, end.Winner.Peer.Name)
+	case DuelStuck:
+		fmt.Printf("\n%s cannot act: %v. %s wins!\n", end.Loser.Peer.Name, end.Problem, end.Winner.Peer.Name)
+	case DuelFainted:
+		fmt.Printf("\n%s's Pokemon fainted! %s wins!\n", end.Loser.Peer.Name, end.Winner.Peer.Name)
+	case DuelTurnLimit:
+		fmt.Printf("\nTurn limit of %d reached!\n", g.Rules.TurnLimit)
+		fmt.Printf("Team HP left: %s %.1f%%, %s %.1f%%\n", host.Peer.Name, HPPercent(host), joiner.Peer.Name, HPPercent(joiner))
+		fmt.Printf("Damage dealt: %s %d, %s %d\n", host.Peer.Name, host.DamageDealt, joiner.Peer.Name, joiner.DamageDealt)
+		if end.Winner == nil {
+			fmt.Println("The battle is a draw!")
+		} else {
+			fmt.Printf("%s wins on the %s tiebreaker!\n", end.Winner.Peer.Name, end.Tiebreak)
+		}
+	}
+	showBattleLog(g)
+}
+
+// showHotSeatStatus reminds the trainer who just took the terminal where
+// the battle stands.
+func showHotSeatStatus(self *player.Player, foe *player.Player) {
+	fmt.Printf("Your Pokemon: %s (HP: %d/%d, %s)\n",
+		self.PokemonStruct.Name, self.PokemonStruct.HP, self.PokemonStruct.MaxHP, poke.StatusName(self.PokemonStruct.Status))
+	fmt.Printf("Opponent's Pokemon: %s (HP: %d/%d, %s)\n",
+		foe.PokemonStruct.Name, foe.PokemonStruct.HP, foe.PokemonStruct.MaxHP, poke.StatusName(foe.PokemonStruct.Status))
+}
@@ -0,0 +1,423 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// LocalRules turns a ruleset into one a local battle can play: a singles
+// duel with no raid and no timers.
+func LocalRules(r rules.Ruleset) rules.Ruleset {
+	r.Players = 2
+	r.Doubles = false
+	r.RaidBoss = ""
+	r.TurnTimer = 0
+	r.GameClock = 0
+	return r
+}
+
+// How a local duel ended.
+const (
+	DuelFainted   = "fainted"    // A side's Pokemon fainted
+	DuelForfeit   = "forfeit"    // A side forfeited
+	DuelStuck     = "stuck"      // A side had no action the engine accepts
+	DuelTurnLimit = "turn_limit" // The ruleset's turn limit was reached
+	DuelMaxTurns  = "max_turns"  // PlayDuel's own limit was reached
+)
+
+// DuelEnd is the result of a local duel.
+type DuelEnd struct {
+	Winner   *player.Player // nil for a draw
+	Loser    *player.Player // The side that fainted, forfeited or was stuck; nil otherwise
+	Reason   string         // One of the Duel constants
+	Tiebreak string         // The tiebreaker that settled a turn limit, if any
+	Problem  error          // Why the loser could not act, for DuelStuck
+	Turns    int            // Actions taken, as the turn counter counts them
+}
+
+// DuelView shows a local duel as PlayDuel plays it. Its methods are called
+// in the order listed, and any of them may do nothing.
+type DuelView interface {
+	// TurnStarted runs at the start of every turn.
+	TurnStarted(g *Game, turn int)
+	// Choosing runs before p's decider is asked for an action: for both
+	// trainers as a round opens, and again if a chosen action no longer fits
+	// when its turn comes.
+	Choosing(g *Game, p *player.Player)
+	// RoundOpened runs once both trainers have chosen, with the trainer who
+	// goes first.
+	RoundOpened(g *Game, opener *player.Player)
+	// ActionChosen shows attacker's action. rejected is why the decider's
+	// own choice was replaced by the default action, or nil.
+	ActionChosen(g *Game, attacker *player.Player, action TurnAction, rejected error)
+	// DefenseBoostAsked runs before defender's decider is asked whether to
+	// brace.
+	DefenseBoostAsked(g *Game, defender *player.Player)
+	// ActionResolved shows the resolved action before it is applied, with
+	// the boosts already spent.
+	ActionResolved(g *Game, attacker *player.Player, defender *player.Player, action TurnAction, defenseBoost bool, outcome AttackOutcome)
+	// TurnFinished shows the battle after the turn is applied, with the
+	// events FinishTurn narrated.
+	TurnFinished(g *Game, attacker *player.Player, defender *player.Player, events []string)
+	// DuelEnded shows the result. The battle log is complete.
+	DuelEnded(g *Game, end DuelEnd)
+}
+
+// plannedAction is an action a trainer chose as a round opened.
+type plannedAction struct {
+	action   TurnAction
+	rejected error  // Why the decider's own choice was replaced, or nil
+	pokemon  string // The active Pokemon it was chosen for
+}
+
+// PlayDuel plays the duel between g.Host and g.Joiner to the end in one
+// process, each side's choices made by its decider; a trainer at the
+// terminal plays through a terminalDecider. It has no sockets and no output
+// of its own, so view shows the duel. Both trainers choose their action as
+// a round opens, and the higher priority bracket goes first. An action that
+// no longer fits when its turn comes, as after a faint, is chosen again,
+// and a decider's action the engine rejects is replaced by the default one.
+// The game must be waiting for the first move with the entry abilities
+// applied. maxTurns ends a duel the ruleset does not limit as a draw; 0
+// never does.
+func PlayDuel(g *Game, hostDecider Decider, joinerDecider Decider, view DuelView, maxTurns int) DuelEnd {
+	deciders := map[string]Decider{"host": hostDecider, "joiner": joinerDecider}
+	plans := map[string]plannedAction{}
+	var end DuelEnd
+	for end.Turns = 1; ; end.Turns++ {
+		for _, s := range []string{"host", "joiner"} {
+			if observer := observerOf(deciders[s]); observer != nil {
+				observer.ObserveTurn(g, g.Side(s), g.Side(otherSide(s)), end.Turns)
+			}
+		}
+		view.TurnStarted(g, end.Turns)
+
+		if g.TurnsThisRound == 0 {
+			for _, s := range []string{"host", "joiner"} {
+				self, foe := g.Side(s), g.Side(otherSide(s))
+				view.Choosing(g, self)
+				action, rejected := planAction(g, deciders[s], self, foe)
+				plans[s] = plannedAction{action: action, rejected: rejected, pokemon: self.PokemonStruct.Name}
+				if action.Type == ActionForfeit {
+					view.ActionChosen(g, self, action, rejected)
+					return forfeitDuel(g, deciders, view, self, end)
+				}
+			}
+			g.OpenRound(plans["host"].action.Priority(), plans["joiner"].action.Priority())
+			view.RoundOpened(g, g.Side(g.CurrentTurn))
+		}
+
+		side := g.CurrentTurn
+		attacker, defender := g.Side(side), g.Side(otherSide(side))
+		plan := plans[side]
+		action, rejected := plan.action, plan.rejected
+		if plan.pokemon != attacker.PokemonStruct.Name || checkAction(g, attacker, defender, action) != nil {
+			view.Choosing(g, attacker)
+			action, rejected = chooseChecked(g, deciders[side], attacker, defender)
+		}
+		view.ActionChosen(g, attacker, action, rejected)
+		if action.Type == ActionForfeit {
+			return forfeitDuel(g, deciders, view, attacker, end)
+		}
+
+		move := action.Move
+		defenseBoost := false
+		if canDefenseBoost(g, defender, action) {
+			view.DefenseBoostAsked(g, defender)
+			defenseBoost = deciders[otherSide(side)].UseDefenseBoost(g, defender, attacker, move, action.AttackBoost)
+		}
+		outcome, err := g.ResolveTurn(attacker, defender, action, defenseBoost)
+		if err != nil {
+			// Not even the default action is possible, so the side cannot go on
+			g.BattleLog = append(g.BattleLog, fmt.Sprintf("%s could not act!", attacker.Peer.Name))
+			end.Winner, end.Loser, end.Reason, end.Problem = defender, attacker, DuelStuck, err
+			return endDuel(g, deciders, view, end)
+		}
+		if action.AttackBoost {
+			*attacker.AttackBoostsLeft(move.DamageCategory)--
+		}
+		if defenseBoost {
+			*defender.DefenseBoostsLeft(move.DamageCategory)--
+		}
+
+		actorName := attacker.PokemonStruct.Name
+		view.ActionResolved(g, attacker, defender, action, defenseBoost, outcome)
+		events := g.FinishTurn(attacker, defender, action, outcome)
+		g.BattleLog = append(g.BattleLog, action.Describe(actorName))
+		g.AdvanceTurn()
+		view.TurnFinished(g, attacker, defender, events)
+
+		switch {
+		case IsFainted(&g.Host.PokemonStruct), IsFainted(&g.Joiner.PokemonStruct):
+			// When both faint, as to recoil, the host's faint counts first
+			end.Loser = g.Joiner
+			if IsFainted(&g.Host.PokemonStruct) {
+				end.Loser = g.Host
+			}
+			end.Winner, end.Reason = g.Side(otherSide(g.SideOf(end.Loser))), DuelFainted
+			g.BattleLog = append(g.BattleLog, fmt.Sprintf("%s's Pokemon fainted!", end.Loser.Peer.Name))
+		case g.TurnLimitReached(end.Turns):
+			end.Reason = DuelTurnLimit
+			end.Winner, end.Tiebreak = g.Tiebreak()
+			g.BattleLog = append(g.BattleLog, fmt.Sprintf("Turn limit of %d reached", g.Rules.TurnLimit))
+		case maxTurns > 0 && end.Turns >= maxTurns:
+			end.Reason = DuelMaxTurns
+		default:
+			continue
+		}
+		return endDuel(g, deciders, view, end)
+	}
+}
+
+// forfeitDuel ends the duel with p forfeiting.
+func forfeitDuel(g *Game, deciders map[string]Decider, view DuelView, p *player.Player, end DuelEnd) DuelEnd {
+	g.BattleLog = append(g.BattleLog, fmt.Sprintf("%s forfeited!", p.Peer.Name))
+	end.Winner, end.Loser, end.Reason = g.Side(otherSide(g.SideOf(p))), p, DuelForfeit
+	return endDuel(g, deciders, view, end)
+}
+
+// endDuel ends the game, logs the result and tells the observers and view.
+func endDuel(g *Game, deciders map[string]Decider, view DuelView, end DuelEnd) DuelEnd {
+	g.State = StateGameOver
+	if end.Winner == nil {
+		g.BattleLog = append(g.BattleLog, "Draw")
+	} else {
+		g.BattleLog = append(g.BattleLog, fmt.Sprintf("Winner: %s", end.Winner.Peer.Name))
+	}
+	for side, decider := range deciders {
+		if observer := observerOf(decider); observer != nil {
+			observer.ObserveEnd(duelResult(end, g.Side(side)))
+		}
+	}
+	view.DuelEnded(g, end)
+	return end
+}
+
+// duelResult is how a duel ended for p.
+func duelResult(end DuelEnd, p *player.Player) poke.BattleResult {
+	switch end.Winner {
+	case nil:
+		return poke.ResultDraw
+	case p:
+		return poke.ResultWin
+	}
+	return poke.ResultLoss
+}
+
+// showOpener tells who goes first in the round.
+func showOpener(opener *player.Player) {
+	fmt.Printf("\n%s moves first this round!\n", opener.PokemonStruct.Name)
+}
+
+// showBattleLog prints the end banner and the battle log.
+func showBattleLog(g *Game) {
+	fmt.Println("\n=== BATTLE END ===")
+	fmt.Println("\nBATTLE LOG:")
+	for i, entry := range g.BattleLog {
+		fmt.Printf("%d. %s\n", i+1, entry)
+	}
+	fmt.Println()
+}
+
+// RunLocalBattle runs a practice duel in one process with no sockets, with
+// RunBattle's prompts and personality messages, on PlayDuel. The human
+// plays the host side at the terminal and the decider, such as a bot, plays
+// the joiner side. Afterwards the human's profile is updated on its
+// practice record, apart from networked battles. Returns the winner, or nil
+// for a draw.
+func RunLocalBattle(human *player.Player, opponent *player.Player, decider Decider, seed int, r rules.Ruleset) *player.Player {
+	game := NewGame(seed, P2P, LocalRules(r))
+	game.Host = human
+	game.Joiner = opponent
+	game.State = StateWaitingForMove
+
+	fmt.Printf("\n=== PRACTICE BATTLE vs %s ===\n", opponent.Peer.Name)
+	if human.Profile != nil {
+		poke.ShowPreBattleMessage(human.Profile)
+	}
+	showBattlers(human, opponent)
+	showFieldEvents(game.Field.ApplyEntryAbility(&human.PokemonStruct))
+	showFieldEvents(game.Field.ApplyEntryAbility(&opponent.PokemonStruct))
+	showBattleRules(game, human, opponent)
+	fmt.Println()
+
+	view := &practiceView{
+		battleCtx: &BattleContext{Game: game, SelfPlayer: human, IsHost: true},
+		human:     human,
+		decider:   decider,
+	}
+	end := PlayDuel(game, terminalDecider{}, decider, view, 0)
+
+	// Practice goes on its own record
+	if human.Profile != nil {
+		teamManager := poke.NewTeamManager(human.TrainerName)
+		if err := teamManager.UpdateProfileAfterPractice(human.Profile, duelResult(end, human)); err != nil {
+			fmt.Printf("Warning: Could not save profile: %v\n", err)
+		}
+	}
+	return end.Winner
+}
+
+// practiceView shows a practice duel to the human at the terminal. The
+// opponent's choices are labelled with its decider's name.
+type practiceView struct {
+	battleCtx *BattleContext // For the personality messages
+	human     *player.Player
+	decider   Decider // Plays the opponent
+}
+
+func (v *practiceView) TurnStarted(g *Game, turn int) {
+	fmt.Printf("\n--- Turn %d ---\n", turn)
+	fmt.Printf("Field: %s\n", g.Field)
+}
+
+func (v *practiceView) Choosing(g *Game, p *player.Player) {}
+
+func (v *practiceView) RoundOpened(g *Game, opener *player.Player) {
+	showOpener(opener)
+}
+
+func (v *practiceView) ActionChosen(g *Game, attacker *player.Player, action TurnAction, rejected error) {
+	if attacker == v.human {
+		return
+	}
+	fmt.Println("Opponent's turn...")
+	if rejected != nil {
+		fmt.Printf("[%s] %v. Using the default action.\n", v.decider.Name(), rejected)
+	}
+	fmt.Printf("[%s] %s\n", v.decider.Name(), action.Describe(attacker.PokemonStruct.Name))
+}
+
+func (v *practiceView) DefenseBoostAsked(g *Game, defender *player.Player) {}
+
+func (v *practiceView) ActionResolved(g *Game, attacker *player.Player, defender *player.Player, action TurnAction, defenseBoost bool, outcome AttackOutcome) {
+	move := action.Move
+	if defenseBoost {
+		boostsLeft := *defender.DefenseBoostsLeft(move.DamageCategory)
+		if defender == v.human {
+			fmt.Printf("%s Defense boost used! (%d left)\n", categoryName(move.DamageCategory), boostsLeft)
+		} else {
+			fmt.Printf("[%s] %s Defense boost used! (%d left)\n", v.decider.Name(), categoryName(move.DamageCategory), boostsLeft)
+		}
+	}
+
+	actorName, targetName := attacker.PokemonStruct.Name, defender.PokemonStruct.Name
+	fmt.Printf("\n%s\n", describeAction(actorName, move, outcome))
+	v.battleCtx.announceOutcome(attacker, actorName, targetName, outcome)
+}
+
+func (v *practiceView) TurnFinished(g *Game, attacker *player.Player, defender *player.Player, events []string) {
+	showFieldEvents(events)
+
+	human, opponent := v.human, g.Side(otherSide(g.SideOf(v.human)))
+	fmt.Printf("\nYour Pokemon: %s (HP: %d/%d, %s)\n",
+		human.PokemonStruct.Name, human.PokemonStruct.HP, human.PokemonStruct.MaxHP, poke.StatusName(human.PokemonStruct.Status))
+	fmt.Printf("Opponent's Pokemon: %s (HP: %d/%d, %s)\n",
+		opponent.PokemonStruct.Name, opponent.PokemonStruct.HP, opponent.PokemonStruct.MaxHP, poke.StatusName(opponent.PokemonStruct.Status))
+
+	// Show low HP warning if HP is below 30%
+	hpPercent := float64(human.PokemonStruct.HP) / float64(human.PokemonStruct.MaxHP)
+	if hpPercent < 0.3 && hpPercent > 0 && human.Profile != nil {
+		poke.ShowLowHealthMessage(human.Profile)
+	}
+}
+
+func (v *practiceView) DuelEnded(g *Game, end DuelEnd) {
+	human, opponent := v.human, g.Side(otherSide(g.SideOf(v.human)))
+	switch end.Reason {
+	case DuelForfeit:
+		if end.Loser == human {
+			fmt.Println("\nYou forfeited the match.")
+		} else {
+			fmt.Println("\nOpponent forfeited the match. You win!")
+		}
+	case DuelStuck:
+		if end.Loser == human {
+			fmt.Printf("Cannot act: %v. You lose!\n", end.Problem)
+		} else {
+			fmt.Printf("[%s] Cannot act: %v. You win!\n", v.decider.Name(), end.Problem)
+		}
+	case DuelFainted:
+		if end.Loser == human {
+			fmt.Println("\nYour Pokemon fainted! You lose!")
+		} else {
+			fmt.Println("\nOpponent's Pokemon fainted! You win!")
+		}
+	case DuelTurnLimit:
+		fmt.Printf("\nTurn limit of %d reached!\n", g.Rules.TurnLimit)
+		fmt.Printf("Team HP left: you %.1f%%, opponent %.1f%%\n", HPPercent(human), HPPercent(opponent))
+		fmt.Printf("Damage dealt: you %d, opponent %d\n", human.DamageDealt, opponent.DamageDealt)
+		switch end.Winner {
+		case nil:
+			fmt.Println("The battle is a draw!")
+		case human:
+			fmt.Printf("You win on the %s tiebreaker!\n", end.Tiebreak)
+		default:
+			fmt.Printf("You lose on the %s tiebreaker!\n", end.Tiebreak)
+		}
+	}
+	showBattleLog(g)
+}
+
+// terminalDecider is the trainer at the terminal, asked with RunBattle's
+// prompts. An action the engine would reject is asked for again.
+type terminalDecider struct{}
+
+func (terminalDecider) Name() string {
+	return "terminal"
+}
+
+func (terminalDecider) ChooseAction(g *Game, self *player.Player, foe *player.Player) TurnAction {
+	for {
+		action := askLocalAction(g, self)
+		if action.Type == ActionForfeit {
+			return action
+		}
+		if err := checkAction(g, self, foe, action); err != nil {
+			fmt.Printf("Can't do that: %v\n", err)
+			continue
+		}
+		return action
+	}
+}
+
+func (terminalDecider) UseDefenseBoost(g *Game, self *player.Player, attacker *player.Player, move poke.Move, attackBoost bool) bool {
+	return askLocalDefenseBoost(self, move, attackBoost)
+}
+
+// askLocalAction reads the human's action for a local battle, and an attack
+// boost if one can be spent on the move, with RunBattle's prompts.
+func askLocalAction(game *Game, self *player.Player) TurnAction {
+	fmt.Println("Your turn!")
+	showActionMenu(self, false)
+	for {
+		action, problem := parseTurnAction(netio.RLine(), self)
+		if problem != "" {
+			fmt.Println(problem)
+			continue
+		}
+		if action.Type == ActionAttack && canAttackBoost(game, self, action.Move) {
+			category := action.Move.DamageCategory
+			fmt.Printf("Use a %s Attack boost? (y/n, %d left): \n", categoryName(category), *self.AttackBoostsLeft(category))
+			action.AttackBoost = strings.EqualFold(netio.RLine(), "y")
+		}
+		return action
+	}
+}
+
+// askLocalDefenseBoost asks the human whether to brace against a move, with
+// RunBattle's prompt but no time limit.
+func askLocalDefenseBoost(self *player.Player, move poke.Move, attackBoost bool) bool {
+	category := categoryName(move.DamageCategory)
+	fmt.Printf("\nOpponent is using %s", move.Name)
+	if attackBoost {
+		fmt.Printf(" with a %s Attack boost", category)
+	}
+	fmt.Printf("! Use a %s Defense boost? (y/n, %d left): \n", category, *self.DefenseBoostsLeft(move.DamageCategory))
+	return strings.EqualFold(netio.RLine(), "y")
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/peer"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// NewTeam builds a trainer from species names, lead first: every Pokemon at
+// the default level (capped by the ruleset) with perfect IVs and a neutral
+// nature, no items, and the boost budget split evenly as a bot's is.
+// Returns an error if a species is unknown or the party is illegal.
+func NewTeam(self peer.PeerDescriptor, r rules.Ruleset, species []string) (player.Player, error) {
+	var party []poke.Pokemon
+	for _, name := range species {
+		mon, ok := speciesByName(name)
+		if !ok {
+			return player.Player{}, fmt.Errorf("unknown species %q", name)
+		}
+		mon.ApplyStats(r.CapLevel(poke.DefaultLevel), poke.PerfectIVs(), poke.StatSet{}, poke.Natures[poke.NeutralNature])
+		party = append(party, mon)
+	}
+	if len(party) == 0 {
+		return player.Player{}, fmt.Errorf("%s has no Pokemon", self.Name)
+	}
+	if err := r.CheckParty(party); err != nil {
+		return player.Player{}, err
+	}
+	return newTrainer(self, r, party), nil
+}
+
+// MatchupResult is a search's verdict on a matchup between teams A and B,
+// once with each team moving first. Scores are for A: the expected lead in
+// share of team HP, or ±1000 for a sure win or loss.
+type MatchupResult struct {
+	AFirst SearchResult // A opens the battle; Action is A's best opening
+	BFirst SearchResult // B opens the battle; Action is B's best opening
+}
+
+// Score returns A's expected score over both turn orders.
+func (m MatchupResult) Score() float64 {
+	return (m.AFirst.Score + m.BFirst.Score) / 2
+}
+
+// EvaluateMatchup searches a duel between two teams from the opening,
+// thinking for budget with each team moving first. Neither team is changed.
+func EvaluateMatchup(a *player.Player, b *player.Player, r rules.Ruleset, budget time.Duration, seed int64) MatchupResult {
+	searcher := NewSearcher(budget, seed)
+	return MatchupResult{
+		AFirst: searcher.Search(matchupGame(a, b, r), "host"),
+		BFirst: searcher.Search(matchupGame(b, a, r), "joiner"),
+	}
+}
+
+// matchupGame sets up a local duel between copies of two teams, the host
+// opening, with the entry abilities applied.
+func matchupGame(host *player.Player, joiner *player.Player, r rules.Ruleset) *Game {
+	g := NewGame(0, P2P, LocalRules(r))
+	g.Host, g.Joiner = forkPlayer(host), forkPlayer(joiner)
+	g.Field.ApplyEntryAbility(&g.Host.PokemonStruct)
+	g.Field.ApplyEntryAbility(&g.Joiner.PokemonStruct)
+	g.State = StateWaitingForMove
+	return g
+}
@@ -7,10 +7,86 @@ import (
 
 // Player represents a player in the Pokemon battle game.
 type Player struct {
-	Peer                   peer.PeerDescriptor  // Network connection information
-	PokemonStruct          poke.Pokemon         // Player's pokemon
-	SpecialAttackUsesLeft  int                  // Special attack boosts remaining
-	SpecialDefenseUsesLeft int                  // Special defense boosts remaining
-	Profile                *poke.PokemonProfile // Pokemon personality and nickname
-	TrainerName            string               // Trainer name for profile management
+	Peer                    peer.PeerDescriptor  // Network connection information
+	PokemonStruct           poke.Pokemon         // Player's pokemon
+	SpecialAttackUsesLeft   int                  // Special attack boosts remaining
+	SpecialDefenseUsesLeft  int                  // Special defense boosts remaining
+	PhysicalAttackUsesLeft  int                  // Physical attack boosts remaining
+	PhysicalDefenseUsesLeft int                  // Physical defense boosts remaining
+	Profile                 *poke.PokemonProfile // Pokemon personality and nickname
+	TrainerName             string               // Trainer name for profile management
+	Friendship              int                  // Friendship (0-100), shared in BATTLE_SETUP
+	Bag                     poke.Bag             // Consumable items left, shared in BATTLE_SETUP
+	Reserves                []poke.Pokemon       // Benched Pokemon that can be switched in
+	Partner                 poke.Pokemon         // Second active Pokemon in doubles (slot 1)
+	HasPartner              bool                 // Partner is in use (doubles only)
+	LastAction              string               // Type of the player's previous turn action
+	DamageDealt             int                  // HP taken from the opponent this battle, for tiebreaks
+}
+
+// AttackBoostsLeft returns the attack boost counter used by moves of a damage category.
+func (p *Player) AttackBoostsLeft(category string) *int {
+	if category == poke.Physical {
+		return &p.PhysicalAttackUsesLeft
+	}
+	return &p.SpecialAttackUsesLeft
+}
+
+// DefenseBoostsLeft returns the defense boost counter used against moves of a damage category.
+func (p *Player) DefenseBoostsLeft(category string) *int {
+	if category == poke.Physical {
+		return &p.PhysicalDefenseUsesLeft
+	}
+	return &p.SpecialDefenseUsesLeft
+}
+
+// SwitchActive swaps the active Pokemon with the reserve in slot. The
+// outgoing Pokemon leaves its stat stages, protection and Choice item lock
+// behind.
+func (p *Player) SwitchActive(slot int) {
+	p.PokemonStruct.Stages = poke.StatSet{}
+	p.PokemonStruct.Protecting = false
+	p.PokemonStruct.ChoiceLock = ""
+	p.PokemonStruct, p.Reserves[slot] = p.Reserves[slot], p.PokemonStruct
+}
+
+// TeamHP returns the HP left and the max HP summed over the active Pokemon,
+// the doubles partner and the reserves.
+func (p *Player) TeamHP() (hp int, maxHP int) {
+	hp, maxHP = p.PokemonStruct.HP, p.PokemonStruct.MaxHP
+	if p.HasPartner {
+		hp += p.Partner.HP
+		maxHP += p.Partner.MaxHP
+	}
+	for _, mon := range p.Reserves {
+		hp += mon.HP
+		maxHP += mon.MaxHP
+	}
+	return hp, maxHP
+}
+
+// Active returns the active Pokemon in a slot: 0 for PokemonStruct, 1 for
+// the doubles partner.
+func (p *Player) Active(slot int) *poke.Pokemon {
+	if slot == 1 {
+		return &p.Partner
+	}
+	return &p.PokemonStruct
+}
+
+// SwapPartner swaps the two active Pokemon, so code written for the single
+// active Pokemon can act on the partner.
+func (p *Player) SwapPartner() {
+	p.PokemonStruct, p.Partner = p.Partner, p.PokemonStruct
+}
+
+// HealthyReserves returns how many reserves have not fainted.
+func (p *Player) HealthyReserves() int {
+	healthy := 0
+	for _, mon := range p.Reserves {
+		if mon.HP > 0 {
+			healthy++
+		}
+	}
+	return healthy
 }
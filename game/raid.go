@@ -0,0 +1,127 @@
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/peer"
+	"github.com/zrygan/pokemonbattler/poke"
+	monsters "github.com/zrygan/pokemonbattler/poke/mons"
+)
+
+// RaidsDir holds the raids a host can run with the -raid flag.
+var RaidsDir = filepath.Join("data", "raids")
+
+// RaidBossName is the trainer name the raid boss battles under.
+const RaidBossName = "Raid Boss"
+
+// NewRaidBoss builds the boss a ruleset describes: its species at the raid
+// level with perfect IVs and its max HP multiplied. Every peer builds the
+// same boss from the RULESET, so it needs no BATTLE_SETUP.
+func NewRaidBoss(r rules.Ruleset) (*player.Player, error) {
+	mon, ok := speciesByName(r.RaidBoss)
+	if !ok {
+		return nil, fmt.Errorf("unknown raid boss %q", r.RaidBoss)
+	}
+	mon.ApplyStats(r.RaidLevel, poke.PerfectIVs(), poke.StatSet{}, poke.Natures[poke.NeutralNature])
+	mon.MaxHP *= r.RaidHPMultiplier
+	mon.HP = mon.MaxHP
+
+	return &player.Player{
+		Peer:          peer.MakePD(RaidBossName, nil, nil),
+		PokemonStruct: mon,
+		TrainerName:   RaidBossName,
+	}, nil
+}
+
+// CheckRaid returns an error if a raid's boss is not a known species or its
+// script uses a move the boss does not know.
+func CheckRaid(raid rules.Raid) error {
+	mon, ok := speciesByName(raid.Boss)
+	if !ok {
+		return fmt.Errorf("unknown raid boss %q", raid.Boss)
+	}
+	for _, name := range raid.Script {
+		if _, ok := moveByName(&mon, name); !ok {
+			return fmt.Errorf("%s does not know %q", mon.Name, name)
+		}
+	}
+	return nil
+}
+
+// speciesByName looks a species up in the Pokemon data, ignoring case.
+func speciesByName(name string) (poke.Pokemon, bool) {
+	if mon, ok := monsters.MONSTERS[name]; ok {
+		return mon, true
+	}
+	for key, mon := range monsters.MONSTERS {
+		if strings.EqualFold(key, name) {
+			return mon, true
+		}
+	}
+	return poke.Pokemon{}, false
+}
+
+// moveByName finds a move in a Pokemon's moveset, ignoring case.
+func moveByName(mon *poke.Pokemon, name string) (poke.Move, bool) {
+	for _, move := range mon.Moves {
+		if strings.EqualFold(move.Name, name) {
+			return move, true
+		}
+	}
+	return poke.Move{}, false
+}
+
+// BossSeat returns the raid boss's seat, which follows every trainer's.
+func (g *Game) BossSeat() int {
+	return len(g.Players) - 1
+}
+
+// BossAction returns the raid boss's next action and its target. The boss
+// works through its script in order and aims each action at the next
+// trainer still standing, so the trainers share the punishment. Only the
+// host calls it, since only the host has the script.
+func (g *Game) BossAction() (TurnAction, int) {
+	boss := g.Players[g.BossSeat()]
+	action := DefaultAction(boss)
+	if len(g.BossScript) > 0 {
+		name := g.BossScript[g.BossTurns%len(g.BossScript)]
+		if move, ok := moveByName(&boss.PokemonStruct, name); ok {
+			action = TurnAction{Type: ActionAttack, Move: move}
+		}
+	}
+
+	var trainers []int
+	for _, seat := range g.StandingSeats() {
+		if seat != g.BossSeat() {
+			trainers = append(trainers, seat)
+		}
+	}
+	target := g.BossSeat()
+	if len(trainers) > 0 {
+		target = trainers[g.BossTurns%len(trainers)]
+	}
+	g.BossTurns++
+	return action, target
+}
+
+// RaidOver reports whether the raid has ended and, if so, whether the
+// trainers cleared it by knocking out the boss.
+func (g *Game) RaidOver() (over bool, cleared bool) {
+	if !g.Standing(g.BossSeat()) {
+		return true, true
+	}
+	return len(g.StandingSeats()) == 1, false
+}
+
+// RaidTrainers returns the names of the trainers in a raid, e.g. "Ash & Misty".
+func (g *Game) RaidTrainers() string {
+	var names []string
+	for _, p := range g.Players[:g.BossSeat()] {
+		names = append(names, p.Peer.Name)
+	}
+	return strings.Join(names, " & ")
+}
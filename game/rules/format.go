@@ -0,0 +1,133 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// MaxGeneration is the newest generation in the Pokemon data.
+const MaxGeneration = 7
+
+// Format is a named ruleset bundled as a data file. Keys missing from the
+// file keep the values of Default.
+type Format struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	LevelCap      int    `json:"level_cap"`
+	BanLegendary  bool   `json:"ban_legendary"`
+	Generations   []int  `json:"generations"`
+	SpeciesClause bool   `json:"species_clause"`
+	PartySize     int    `json:"party_size"`
+	BoostBudget   int    `json:"boost_budget"`
+	TurnLimit     int    `json:"turn_limit"`
+	DamageModel   string `json:"damage_model"`
+	Doubles       bool   `json:"doubles"`
+	TurnTimer     int    `json:"turn_timer"`
+	GameClock     int    `json:"game_clock"`
+	TimeoutAction string `json:"timeout_action"`
+}
+
+// Ruleset returns the default ruleset with the format's settings applied.
+func (f Format) Ruleset() Ruleset {
+	r := Default()
+	r.Format = f.Name
+	r.LevelCap = f.LevelCap
+	r.BanLegendary = f.BanLegendary
+	r.Generations = f.Generations
+	r.SpeciesClause = f.SpeciesClause
+	r.PartySize = f.PartySize
+	r.BoostBudget = f.BoostBudget
+	r.TurnLimit = f.TurnLimit
+	r.DamageModel = f.DamageModel
+	r.Doubles = f.Doubles
+	r.TurnTimer = f.TurnTimer
+	r.GameClock = f.GameClock
+	r.TimeoutAction = f.TimeoutAction
+	return r
+}
+
+// Validate returns an error if the format's settings are out of range.
+func (f Format) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("format has no name")
+	}
+	if f.LevelCap < poke.MinLevel || f.LevelCap > poke.MaxLevel {
+		return fmt.Errorf("level cap %d is not between %d and %d", f.LevelCap, poke.MinLevel, poke.MaxLevel)
+	}
+	for _, generation := range f.Generations {
+		if generation < 1 || generation > MaxGeneration {
+			return fmt.Errorf("generation %d is not between 1 and %d", generation, MaxGeneration)
+		}
+	}
+	if f.PartySize < 1 || f.PartySize > MaxPartySize {
+		return fmt.Errorf("party size %d is not between 1 and %d", f.PartySize, MaxPartySize)
+	}
+	if f.Doubles && f.PartySize < 2 {
+		return fmt.Errorf("doubles needs a party size of at least 2")
+	}
+	if f.BoostBudget < 0 || f.BoostBudget > MaxBoostBudget {
+		return fmt.Errorf("boost budget %d is not between 0 and %d", f.BoostBudget, MaxBoostBudget)
+	}
+	if f.TurnLimit < 0 {
+		return fmt.Errorf("turn limit %d is negative", f.TurnLimit)
+	}
+	if f.TurnTimer < 0 || f.GameClock < 0 {
+		return fmt.Errorf("timers cannot be negative")
+	}
+	if f.TimeoutAction != TimeoutDefault && f.TimeoutAction != TimeoutForfeit {
+		return fmt.Errorf("timeout action must be %q or %q", TimeoutDefault, TimeoutForfeit)
+	}
+	if !ValidDamageModel(f.DamageModel) {
+		return fmt.Errorf("unknown damage model %q", f.DamageModel)
+	}
+	return nil
+}
+
+// LoadFormat loads one format from a JSON file.
+func LoadFormat(path string) (Format, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Format{}, err
+	}
+
+	r := Default()
+	f := Format{
+		LevelCap:      r.LevelCap,
+		PartySize:     r.PartySize,
+		BoostBudget:   r.BoostBudget,
+		DamageModel:   r.DamageModel,
+		TimeoutAction: r.TimeoutAction,
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Format{}, err
+	}
+	if err := f.Validate(); err != nil {
+		return Format{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// LoadFormats loads every *.json format in a directory, sorted by name.
+func LoadFormats(dir string) ([]Format, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	formats := make([]Format, 0, len(paths))
+	for _, path := range paths {
+		f, err := LoadFormat(path)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, f)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i].Name < formats[j].Name })
+	return formats, nil
+}
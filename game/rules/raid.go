@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// Limits a raid file must stay within.
+const (
+	MaxRaidHPMultiplier = 20 // Most times the boss's HP may be multiplied
+	MaxRaidActions      = 4  // Most actions the boss may take per round
+)
+
+// Raid is a scripted boss battle bundled as a data file. Two trainers team
+// up against the boss, which the host's engine controls.
+type Raid struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Boss            string   `json:"boss"`              // Species of the boss
+	Level           int      `json:"level"`             // Level the boss battles at
+	HPMultiplier    int      `json:"hp_multiplier"`     // The boss's max HP is multiplied by this
+	ActionsPerRound int      `json:"actions_per_round"` // Actions the boss takes each round
+	Script          []string `json:"script"`            // Moves the boss uses in order, repeating
+	TurnLimit       int      `json:"turn_limit"`        // Turns the trainers have to win (0 for no limit)
+}
+
+// Apply sets the raid's boss on a ruleset. The script is not part of the
+// ruleset: only the host knows which move comes next.
+func (raid Raid) Apply(r *Ruleset) {
+	r.Players = 2
+	r.Doubles = false
+	r.RaidBoss = raid.Boss
+	r.RaidLevel = raid.Level
+	r.RaidHPMultiplier = raid.HPMultiplier
+	r.RaidActions = raid.ActionsPerRound
+	if raid.TurnLimit > 0 {
+		r.TurnLimit = raid.TurnLimit
+	}
+}
+
+// Validate returns an error if the raid's settings are out of range.
+func (raid Raid) Validate() error {
+	if strings.TrimSpace(raid.Name) == "" {
+		return fmt.Errorf("raid has no name")
+	}
+	if strings.TrimSpace(raid.Boss) == "" {
+		return fmt.Errorf("raid %q has no boss", raid.Name)
+	}
+	if raid.Level < poke.MinLevel || raid.Level > poke.MaxLevel {
+		return fmt.Errorf("boss level %d is not between %d and %d", raid.Level, poke.MinLevel, poke.MaxLevel)
+	}
+	if raid.HPMultiplier < 1 || raid.HPMultiplier > MaxRaidHPMultiplier {
+		return fmt.Errorf("HP multiplier %d is not between 1 and %d", raid.HPMultiplier, MaxRaidHPMultiplier)
+	}
+	if raid.ActionsPerRound < 1 || raid.ActionsPerRound > MaxRaidActions {
+		return fmt.Errorf("actions per round %d is not between 1 and %d", raid.ActionsPerRound, MaxRaidActions)
+	}
+	if len(raid.Script) == 0 {
+		return fmt.Errorf("raid %q has an empty move script", raid.Name)
+	}
+	if raid.TurnLimit < 0 {
+		return fmt.Errorf("turn limit %d is negative", raid.TurnLimit)
+	}
+	return nil
+}
+
+// LoadRaid loads one raid from a JSON file.
+func LoadRaid(path string) (Raid, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Raid{}, err
+	}
+
+	raid := Raid{Level: poke.MaxLevel, HPMultiplier: 1, ActionsPerRound: 1}
+	if err := json.Unmarshal(data, &raid); err != nil {
+		return Raid{}, err
+	}
+	if err := raid.Validate(); err != nil {
+		return Raid{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return raid, nil
+}
+
+// LoadRaids loads every *.json raid in a directory, sorted by name.
+func LoadRaids(dir string) ([]Raid, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	raids := make([]Raid, 0, len(paths))
+	for _, path := range paths {
+		raid, err := LoadRaid(path)
+		if err != nil {
+			return nil, err
+		}
+		raids = append(raids, raid)
+	}
+	sort.Slice(raids, func(i, j int) bool { return raids[i].Name < raids[j].Name })
+	return raids, nil
+}
@@ -0,0 +1,301 @@
+// Package rules defines the battle ruleset shared by host and joiner.
+// It lives outside package game so protocol messages can carry it.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// Upper bounds a ruleset may set.
+const (
+	MaxBagLimit    = 10 // Largest bag a ruleset may allow
+	MaxBoostBudget = 40 // Largest boost point budget
+	MaxPartySize   = 6  // Most Pokemon a trainer may bring
+	MaxPlayers     = 4  // Most trainers in a free-for-all
+)
+
+// Damage models a ruleset can select.
+const (
+	DamageProtocol = "protocol" // The PokeProtocol formula: stat ratio x power x effectiveness x 0.85-1.0
+	DamageOfficial = "official" // The main series formula with levels and STAB
+)
+
+// What happens when a player runs out of time.
+const (
+	TimeoutDefault = "default" // The player's first damaging move is used
+	TimeoutForfeit = "forfeit" // The player loses the match
+)
+
+// DamageModels lists the damage models in the order they are offered.
+var DamageModels = []string{DamageProtocol, DamageOfficial}
+
+// Ruleset contains the battle rules both peers must agree on.
+type Ruleset struct {
+	LevelCap              int  // Highest level a Pokemon battles at; higher levels are scaled down
+	CosmeticPersonalities bool // Personalities only change flavor text, not stats
+	FriendshipEffects     bool // Friendship can trigger crits and let a Pokemon endure a KO
+	HeldItems             bool // Pokemon may hold an item
+	BagLimit              int  // Most consumables a trainer may bring (0 disables the bag)
+	PartySize             int  // Most Pokemon a trainer may bring, active one included
+
+	BoostSpecial    bool    // Special attack/defense boosts can be allocated
+	BoostPhysical   bool    // Physical attack/defense boosts can be allocated
+	BoostMultiplier float64 // Stat multiplier applied by a boost
+	BoostBudget     int     // Total boost points each trainer allocates
+
+	DamageModel   string // Damage formula, one of DamageModels
+	InverseBattle bool   // Type matchups are reversed
+	Doubles       bool   // Each side battles with two active Pokemon
+	Players       int    // Trainers in the battle; more than 2 is a free-for-all
+
+	RaidBoss         string // Species of the raid boss ("" when there is no raid)
+	RaidLevel        int    // Level the raid boss battles at
+	RaidHPMultiplier int    // The raid boss's max HP is multiplied by this
+	RaidActions      int    // Actions the raid boss takes each round
+
+	Format        string // Name of the format the rules came from ("" for custom rules)
+	BanLegendary  bool   // Legendary species may not battle
+	Generations   []int  // Generations species must come from (empty allows all)
+	SpeciesClause bool   // A party may not bring two of the same species
+	TurnLimit     int    // Most turns a battle may last (0 for no limit)
+
+	TurnTimer     int    // Seconds a player has to choose an action (0 for no limit)
+	GameClock     int    // Seconds each player has for the whole match (0 for no clock)
+	TimeoutAction string // TimeoutDefault or TimeoutForfeit
+}
+
+// Default returns the ruleset used when the host does not change anything.
+func Default() Ruleset {
+	return Ruleset{
+		LevelCap:              poke.MaxLevel,
+		CosmeticPersonalities: false,
+		FriendshipEffects:     false,
+		HeldItems:             true,
+		BagLimit:              4,
+		PartySize:             1,
+		BoostSpecial:          true,
+		BoostPhysical:         false,
+		BoostMultiplier:       1.5,
+		BoostBudget:           10,
+		DamageModel:           DamageProtocol,
+		Players:               2,
+		TimeoutAction:         TimeoutDefault,
+	}
+}
+
+// CapLevel scales a Pokemon's level down to the level cap.
+func (r Ruleset) CapLevel(level int) int {
+	return poke.ClampLevel(level, r.LevelCap)
+}
+
+// BattleNature returns the nature applied to battle stats.
+// Cosmetic leagues replace every nature with a neutral one.
+func (r Ruleset) BattleNature(nature poke.Nature) poke.Nature {
+	if r.CosmeticPersonalities {
+		return poke.Natures[poke.NeutralNature]
+	}
+	return nature
+}
+
+// CheckItems returns an error if a held item or bag breaks the ruleset.
+func (r Ruleset) CheckItems(heldItem string, bag poke.Bag) error {
+	if heldItem != "" {
+		item, ok := poke.GetItem(heldItem)
+		if !ok || item.Kind != poke.HeldItem {
+			return fmt.Errorf("%q is not a held item", heldItem)
+		}
+		if !r.HeldItems {
+			return fmt.Errorf("held items are not allowed")
+		}
+	}
+	if bag.Count() > r.BagLimit {
+		return fmt.Errorf("bag has %d items, the limit is %d", bag.Count(), r.BagLimit)
+	}
+	return nil
+}
+
+// TypeChart returns the type chart battles under this ruleset use.
+func (r Ruleset) TypeChart() poke.TypeChart {
+	return poke.BattleTypeChart(r.InverseBattle)
+}
+
+// FreeForAll reports whether more than two trainers battle each other.
+func (r Ruleset) FreeForAll() bool {
+	return r.Players > 2
+}
+
+// Raid reports whether the trainers team up against a raid boss.
+func (r Ruleset) Raid() bool {
+	return r.RaidBoss != ""
+}
+
+// HostOrdered reports whether the host hands out every turn and resolves
+// every action, as in a free-for-all or a raid.
+func (r Ruleset) HostOrdered() bool {
+	return r.FreeForAll() || r.Raid()
+}
+
+// Mode names the type matchup rule, e.g. for spectator headers.
+func (r Ruleset) Mode() string {
+	switch {
+	case r.InverseBattle && r.Raid():
+		return "Inverse Raid vs " + r.RaidBoss
+	case r.Raid():
+		return "Raid vs " + r.RaidBoss
+	case r.InverseBattle && r.FreeForAll():
+		return fmt.Sprintf("Inverse Free-for-All (%d trainers)", r.Players)
+	case r.FreeForAll():
+		return fmt.Sprintf("Free-for-All (%d trainers)", r.Players)
+	case r.InverseBattle && r.Doubles:
+		return "Inverse Doubles Battle"
+	case r.InverseBattle:
+		return "Inverse Battle"
+	case r.Doubles:
+		return "Doubles Battle"
+	}
+	return "Standard Battle"
+}
+
+// ValidDamageModel reports whether name is one of DamageModels.
+func ValidDamageModel(name string) bool {
+	for _, model := range DamageModels {
+		if model == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSpecies returns an error if the ruleset bans a Pokemon's species.
+func (r Ruleset) CheckSpecies(mon poke.Pokemon) error {
+	if r.BanLegendary && mon.Legendary {
+		return fmt.Errorf("%s is legendary, and legendaries are banned", mon.Name)
+	}
+	if !r.AllowsGeneration(mon.Generation) {
+		return fmt.Errorf("%s is from generation %d, which is not allowed", mon.Name, mon.Generation)
+	}
+	return nil
+}
+
+// AllowsGeneration reports whether species from a generation may battle.
+func (r Ruleset) AllowsGeneration(generation int) bool {
+	if len(r.Generations) == 0 {
+		return true
+	}
+	for _, allowed := range r.Generations {
+		if allowed == generation {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckParty returns an error if any Pokemon in a party is banned or the
+// party breaks the species clause.
+func (r Ruleset) CheckParty(party []poke.Pokemon) error {
+	seen := map[string]bool{}
+	for _, mon := range party {
+		if err := r.CheckSpecies(mon); err != nil {
+			return err
+		}
+		if r.SpeciesClause && seen[mon.Name] {
+			return fmt.Errorf("species clause: %s is in the party twice", mon.Name)
+		}
+		seen[mon.Name] = true
+	}
+	return nil
+}
+
+// DescribeFormat summarizes the species rules and turn limit, e.g.
+// "Kanto Classic: gen 1, no legendaries, species clause, 100 turns".
+func (r Ruleset) DescribeFormat() string {
+	name := r.Format
+	if name == "" {
+		name = "Custom"
+	}
+	var parts []string
+	if len(r.Generations) > 0 {
+		gens := make([]string, len(r.Generations))
+		for i, gen := range r.Generations {
+			gens[i] = fmt.Sprint(gen)
+		}
+		parts = append(parts, "gen "+strings.Join(gens, "/"))
+	}
+	if r.BanLegendary {
+		parts = append(parts, "no legendaries")
+	}
+	if r.SpeciesClause {
+		parts = append(parts, "species clause")
+	}
+	if r.TurnLimit > 0 {
+		parts = append(parts, fmt.Sprintf("%d turns", r.TurnLimit))
+	}
+	if len(parts) == 0 {
+		return name
+	}
+	return name + ": " + strings.Join(parts, ", ")
+}
+
+// DescribeTimers summarizes the turn timer and game clock, e.g.
+// "30s per turn, 5:00 clock, forfeit on timeout".
+func (r Ruleset) DescribeTimers() string {
+	var parts []string
+	if r.TurnTimer > 0 {
+		parts = append(parts, fmt.Sprintf("%ds per turn", r.TurnTimer))
+	}
+	if r.GameClock > 0 {
+		parts = append(parts, fmt.Sprintf("%d:%02d clock", r.GameClock/60, r.GameClock%60))
+	}
+	if len(parts) == 0 {
+		return "untimed"
+	}
+	return strings.Join(parts, ", ") + ", " + r.TimeoutAction + " on timeout"
+}
+
+// CanBoost reports whether moves of a damage category can be boosted.
+func (r Ruleset) CanBoost(category string) bool {
+	switch category {
+	case poke.Special:
+		return r.BoostSpecial
+	case poke.Physical:
+		return r.BoostPhysical
+	}
+	return false
+}
+
+// CheckBoosts returns an error if a boost allocation breaks the ruleset.
+func (r Ruleset) CheckBoosts(spAtk, spDef, phAtk, phDef int) error {
+	for _, n := range []int{spAtk, spDef, phAtk, phDef} {
+		if n < 0 {
+			return fmt.Errorf("boost allocations cannot be negative")
+		}
+	}
+	if !r.BoostSpecial && spAtk+spDef > 0 {
+		return fmt.Errorf("special boosts are not allowed")
+	}
+	if !r.BoostPhysical && phAtk+phDef > 0 {
+		return fmt.Errorf("physical boosts are not allowed")
+	}
+	if total := spAtk + spDef + phAtk + phDef; total > r.BoostBudget {
+		return fmt.Errorf("allocated %d boost points, the budget is %d", total, r.BoostBudget)
+	}
+	return nil
+}
+
+// DescribeBoosts summarizes the boost rules, e.g. "special+physical x1.5, 10 points".
+func (r Ruleset) DescribeBoosts() string {
+	var categories []string
+	if r.BoostSpecial {
+		categories = append(categories, poke.Special)
+	}
+	if r.BoostPhysical {
+		categories = append(categories, poke.Physical)
+	}
+	if len(categories) == 0 || r.BoostBudget == 0 {
+		return "no boosts"
+	}
+	return fmt.Sprintf("%s x%g, %d points", strings.Join(categories, "+"), r.BoostMultiplier, r.BoostBudget)
+}
@@ -0,0 +1,285 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// Search tuning.
+const (
+	SearchChanceSamples = 3                      // Damage rolls a chance node averages over
+	SearchMaxDepth      = 12                     // Deepest search, in actions
+	DefaultSearchBudget = 500 * time.Millisecond // Thinking time per move unless set otherwise
+
+	searchWin        = 1000.0 // Score of a won battle; a lost one scores the negative
+	searchBoostWorth = 2.0    // Score of each boost left, in percent of team HP
+)
+
+// Searcher picks actions by expectiminimax over forks of a duel. Trainers'
+// choices are decision nodes: the searching side maximizes and the other
+// side minimizes. The damage roll, critical hits and other random effects
+// are chance nodes, averaged over SearchChanceSamples rolls; the search
+// never peeks at the battle's own RNG. A defense boost is the defender's
+// choice, so every damaging attack that can be braced against branches on
+// it. Searches deepen one action at a time until the budget runs out.
+type Searcher struct {
+	Budget time.Duration // Thinking time per search
+
+	rng      *rand.Rand
+	rolls    []int64 // Seeds of the chance node rolls, shared by every node of one search
+	deadline time.Time
+	stopped  bool // The budget ran out during the current depth
+	nodes    int
+}
+
+// SearchResult is the outcome of a search.
+type SearchResult struct {
+	Action TurnAction // Best action for the side to move
+	Score  float64    // Expected score of Action for the searching side
+	Depth  int        // Deepest fully searched depth, in actions
+	Nodes  int        // Positions visited
+}
+
+// NewSearcher returns a searcher that thinks for budget per search. The
+// seed only drives its own chance node rolls.
+func NewSearcher(budget time.Duration, seed int64) *Searcher {
+	return &Searcher{Budget: budget, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Search returns the best action for the trainer whose turn it is in a
+// duel, scored for side. Every depth ends at the end of a round, so both
+// trainers get as many actions; stopping mid-round would make protecting
+// look like a free turn. The first depth is always searched in full, so
+// there is an answer even if the budget is tiny.
+func (s *Searcher) Search(g *Game, side string) SearchResult {
+	s.deadline = time.Now().Add(s.Budget)
+	s.nodes = 0
+	s.rollDice()
+
+	var result SearchResult
+	first := roundEnd(g)
+	for depth := first; depth <= SearchMaxDepth; depth += 2 {
+		s.stopped = false
+		action, score, ok := s.bestAction(g, side, depth, depth == first)
+		if s.stopped || !ok {
+			break
+		}
+		result = SearchResult{Action: action, Score: score, Depth: depth}
+		if math.Abs(score) >= searchWin {
+			break // The result is decided; deeper searches cannot change it
+		}
+	}
+	result.Nodes = s.nodes
+	return result
+}
+
+// ShouldBrace reports whether the defender does better spending a defense
+// boost against the action the trainer to move announced, looking to the
+// end of the round. It is a short, fixed search and ignores the budget.
+func (s *Searcher) ShouldBrace(g *Game, defenderSide string, action TurnAction) bool {
+	s.stopped = false
+	s.rollDice()
+	depth := roundEnd(g)
+	without, ok := s.chanceValue(g, defenderSide, action, false, depth, true)
+	if !ok {
+		return false
+	}
+	with, ok := s.chanceValue(g, defenderSide, action, true, depth, true)
+	return ok && with > without
+}
+
+// roundEnd returns how many actions are left in the current round.
+func roundEnd(g *Game) int {
+	return 2 - g.TurnsThisRound
+}
+
+// rollDice draws fresh seeds for the chance node rolls.
+func (s *Searcher) rollDice() {
+	s.rolls = make([]int64, SearchChanceSamples)
+	for i := range s.rolls {
+		s.rolls[i] = s.rng.Int63()
+	}
+}
+
+// bestAction returns the best action for the trainer to move and its value
+// for side. ok is false if no action could be played.
+func (s *Searcher) bestAction(g *Game, side string, depth int, complete bool) (TurnAction, float64, bool) {
+	mover := g.CurrentTurn
+	maximize := mover == side
+	best, bestValue, found := TurnAction{}, math.Inf(1), false
+	if maximize {
+		bestValue = math.Inf(-1)
+	}
+	for _, action := range searchActions(g, g.Side(mover)) {
+		value, ok := s.actionValue(g, side, action, depth, complete)
+		if s.stopped {
+			return best, bestValue, false
+		}
+		if !ok {
+			continue
+		}
+		if !found || (maximize && value > bestValue) || (!maximize && value < bestValue) {
+			best, bestValue, found = action, value, true
+		}
+	}
+	return best, bestValue, found
+}
+
+// actionValue returns the value for side of the trainer to move playing
+// action. The defender braces or not, whichever is better for it.
+func (s *Searcher) actionValue(g *Game, side string, action TurnAction, depth int, complete bool) (float64, bool) {
+	value, ok := s.chanceValue(g, side, action, false, depth, complete)
+	if !ok || !canDefenseBoost(g, g.Side(otherSide(g.CurrentTurn)), action) {
+		return value, ok
+	}
+	braced, ok := s.chanceValue(g, side, action, true, depth, complete)
+	if !ok {
+		return value, true
+	}
+	if otherSide(g.CurrentTurn) == side {
+		return max(value, braced), true
+	}
+	return min(value, braced), true
+}
+
+// chanceValue plays action, with or without a defense boost, on forks of g
+// and averages the value for side over the chance node's rolls. Actions
+// that never touch the RNG are played once.
+func (s *Searcher) chanceValue(g *Game, side string, action TurnAction, defenseBoost bool, depth int, complete bool) (float64, bool) {
+	samples := s.rolls
+	if action.Type != ActionAttack {
+		samples = samples[:1]
+	}
+
+	total := 0.0
+	for _, roll := range samples {
+		sim := g.forkWithSeed(roll + int64(depth))
+		actor, target := sim.Side(sim.CurrentTurn), sim.Side(otherSide(sim.CurrentTurn))
+		outcome, err := sim.ResolveAction(actor, target, action, defenseBoost)
+		if err != nil {
+			return 0, false
+		}
+		if action.AttackBoost {
+			*actor.AttackBoostsLeft(action.Move.DamageCategory)--
+		}
+		if defenseBoost {
+			*target.DefenseBoostsLeft(action.Move.DamageCategory)--
+		}
+		sim.FinishAttack(actor, target, action.Move, outcome)
+		sim.AdvanceTurn()
+		total += s.value(sim, side, depth-1, complete)
+		if s.stopped {
+			return 0, false
+		}
+	}
+	return total / float64(len(samples)), true
+}
+
+// value returns the value of a position for side, searching depth actions
+// further. An incomplete search gives up once the deadline passes.
+func (s *Searcher) value(g *Game, side string, depth int, complete bool) float64 {
+	s.nodes++
+	if !complete && time.Now().After(s.deadline) {
+		s.stopped = true
+		return 0
+	}
+	score := evaluate(g, side)
+	if depth <= 0 || math.Abs(score) >= searchWin {
+		return score
+	}
+	if _, value, ok := s.bestAction(g, side, depth, complete); ok {
+		return value
+	}
+	return score
+}
+
+// evaluate scores a position for side: the lead in share of team HP left,
+// plus a little for every boost left, or ±searchWin once a team is out.
+func evaluate(g *Game, side string) float64 {
+	self, foe := g.Side(side), g.Side(otherSide(side))
+	switch {
+	case IsFainted(&foe.PokemonStruct):
+		return searchWin
+	case IsFainted(&self.PokemonStruct):
+		return -searchWin
+	}
+	return HPPercent(self) - HPPercent(foe) + searchBoostWorth*float64(boostsLeft(g, self)-boostsLeft(g, foe))
+}
+
+// boostsLeft counts a player's attack and defense boosts in the categories
+// the ruleset lets be boosted.
+func boostsLeft(g *Game, p *player.Player) int {
+	total := 0
+	for _, category := range []string{poke.Special, poke.Physical} {
+		if g.Rules.CanBoost(category) {
+			total += *p.AttackBoostsLeft(category) + *p.DefenseBoostsLeft(category)
+		}
+	}
+	return total
+}
+
+// searchActions lists what the search considers for a player: every attack
+// with and without a boost, protecting, and switching to each healthy
+// reserve. Actions the engine rejects, such as protecting twice in a row,
+// are dropped when they are played.
+func searchActions(g *Game, p *player.Player) []TurnAction {
+	actions := candidateActions(g, p)
+	actions = append(actions, TurnAction{Type: ActionProtect})
+	for i := range p.Reserves {
+		if !IsFainted(&p.Reserves[i]) {
+			actions = append(actions, TurnAction{Type: ActionSwitch, SwitchTo: i})
+		}
+	}
+	return actions
+}
+
+// canDefenseBoost reports whether defender may brace against action.
+func canDefenseBoost(g *Game, defender *player.Player, action TurnAction) bool {
+	move := action.Move
+	return action.Type == ActionAttack && move.IsDamaging() && g.Rules.CanBoost(move.DamageCategory) &&
+		*defender.DefenseBoostsLeft(move.DamageCategory) > 0
+}
+
+// forkWithSeed forks the battle with a fresh RNG seeded by seed instead of
+// a copy of the battle's, for searches that must not know the real rolls.
+// The RNG is a splitmix64 generator, which is far cheaper to seed than the
+// standard source and good enough for rolls.
+func (g *Game) forkWithSeed(seed int64) *Game {
+	forked := g.forkState()
+	forked.rngSource = countingFrom(seed, newSplitMix)
+	forked.RNG = rand.New(forked.rngSource)
+	return forked
+}
+
+// splitMix is the splitmix64 generator.
+type splitMix struct {
+	state uint64
+}
+
+// newSplitMix returns a splitmix64 generator seeded with seed.
+func newSplitMix(seed int64) rand.Source64 {
+	return &splitMix{state: uint64(seed)}
+}
+
+// Uint64 returns the next number.
+func (s *splitMix) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 returns the next number without its sign bit.
+func (s *splitMix) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Seed restarts the generator from seed.
+func (s *splitMix) Seed(seed int64) {
+	s.state = uint64(seed)
+}
@@ -2,10 +2,14 @@ package game
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
 	"github.com/zrygan/pokemonbattler/messages"
 	"github.com/zrygan/pokemonbattler/netio"
 	"github.com/zrygan/pokemonbattler/peer"
@@ -13,7 +17,254 @@ import (
 	monsters "github.com/zrygan/pokemonbattler/poke/mons"
 )
 
-func Host_setCMode(host peer.PeerDescriptor, join peer.PeerDescriptor) string {
+// FormatsDir holds the named formats a host can pick instead of custom rules.
+var FormatsDir = filepath.Join("data", "formats")
+
+// Host_setRules asks the host for the battle rules before the communication mode is sent.
+// Picking a named format takes its rules, and only the options the format
+// leaves open are asked for; otherwise every rule is asked for.
+func Host_setRules() rules.Ruleset {
+	return setRules(false)
+}
+
+// Local_setRules asks for the rules of a practice or hot-seat battle like
+// Host_setRules, but neither offers the formats nor asks for the settings
+// that LocalRules drops: doubles and timers.
+func Local_setRules() rules.Ruleset {
+	return LocalRules(setRules(true))
+}
+
+// setRules asks for the battle rules, leaving out what a local battle
+// cannot play when local is set.
+func setRules(local bool) rules.Ruleset {
+	if f, ok := chooseFormat(local); ok {
+		r := f.Ruleset()
+		fmt.Printf("Format: %s\n", r.DescribeFormat())
+		return askBattleOptions(r, local)
+	}
+
+	r := rules.Default()
+
+	for {
+		input := netio.PRLine(fmt.Sprintf("Select a level cap (%d-%d, Enter for %d):", poke.MinLevel, poke.MaxLevel, r.LevelCap))
+		if input == "" {
+			break
+		}
+
+		levelCap, err := strconv.Atoi(input)
+		if err != nil || levelCap < poke.MinLevel || levelCap > poke.MaxLevel {
+			netio.ERLine(fmt.Sprintf("Invalid input. Should be a number from %d--%d", poke.MinLevel, poke.MaxLevel), false)
+			continue
+		}
+
+		r.LevelCap = levelCap
+		break
+	}
+
+	heldItems := strings.ToLower(netio.PRLine("Allow held items? [Y:default / n]"))
+	r.HeldItems = heldItems != "n"
+
+	for {
+		input := netio.PRLine(fmt.Sprintf("Select a bag item limit (0-%d, Enter for %d):", rules.MaxBagLimit, r.BagLimit))
+		if input == "" {
+			break
+		}
+
+		limit, err := strconv.Atoi(input)
+		if err != nil || limit < 0 || limit > rules.MaxBagLimit {
+			netio.ERLine(fmt.Sprintf("Invalid input. Should be a number from 0--%d", rules.MaxBagLimit), false)
+			continue
+		}
+
+		r.BagLimit = limit
+		break
+	}
+
+	for {
+		input := netio.PRLine(fmt.Sprintf("Select a party size (1-%d, Enter for %d):", rules.MaxPartySize, r.PartySize))
+		if input == "" {
+			break
+		}
+
+		size, err := strconv.Atoi(input)
+		if err != nil || size < 1 || size > rules.MaxPartySize {
+			netio.ERLine(fmt.Sprintf("Invalid input. Should be a number from 1--%d", rules.MaxPartySize), false)
+			continue
+		}
+
+		r.PartySize = size
+		break
+	}
+
+	physical := strings.ToLower(netio.PRLine("Allow physical attack/defense boosts too? [y / N:default]"))
+	r.BoostPhysical = physical == "y"
+
+	for {
+		input := netio.PRLine(fmt.Sprintf("Select a boost multiplier (1-3, Enter for %g):", r.BoostMultiplier))
+		if input == "" {
+			break
+		}
+
+		multiplier, err := strconv.ParseFloat(input, 64)
+		if err != nil || multiplier < 1 || multiplier > 3 {
+			netio.ERLine("Invalid input. Should be a number from 1--3", false)
+			continue
+		}
+
+		r.BoostMultiplier = multiplier
+		break
+	}
+
+	for {
+		input := netio.PRLine(fmt.Sprintf("Select a boost point budget (0-%d, Enter for %d):", rules.MaxBoostBudget, r.BoostBudget))
+		if input == "" {
+			break
+		}
+
+		budget, err := strconv.Atoi(input)
+		if err != nil || budget < 0 || budget > rules.MaxBoostBudget {
+			netio.ERLine(fmt.Sprintf("Invalid input. Should be a number from 0--%d", rules.MaxBoostBudget), false)
+			continue
+		}
+
+		r.BoostBudget = budget
+		break
+	}
+
+	for {
+		input := strings.ToLower(netio.PRLine(fmt.Sprintf("Select a damage model (%s, Enter for %s):", strings.Join(rules.DamageModels, " / "), r.DamageModel)))
+		if input == "" {
+			break
+		}
+		if !rules.ValidDamageModel(input) {
+			netio.ERLine("Invalid input. Should be one of: "+strings.Join(rules.DamageModels, ", "), false)
+			continue
+		}
+
+		r.DamageModel = input
+		break
+	}
+
+	return askBattleOptions(r, local)
+}
+
+// askBattleOptions asks for the rules that do not decide which Pokemon are
+// legal: personalities, friendship, inverse type matchups, doubles, the
+// turn limit and timers. A doubles battle, turn limit or timers that r
+// already has, e.g. from a format, are kept without asking. A local battle
+// is never asked about doubles or timers.
+func askBattleOptions(r rules.Ruleset, local bool) rules.Ruleset {
+	cosmetic := strings.ToLower(netio.PRLine("Make personalities cosmetic (no nature stat changes)? [y / N:default]"))
+	r.CosmeticPersonalities = cosmetic == "y"
+
+	friendship := strings.ToLower(netio.PRLine("Enable friendship effects (crits, enduring KO hits and shaking off statuses)? [y / N:default]"))
+	r.FriendshipEffects = friendship == "y"
+
+	inverse := strings.ToLower(netio.PRLine("Play an inverse battle (type matchups reversed)? [y / N:default]"))
+	r.InverseBattle = inverse == "y"
+
+	if !r.Doubles && !local {
+		doubles := strings.ToLower(netio.PRLine("Play a doubles battle (two active Pokemon per side)? [y / N:default]"))
+		r.Doubles = doubles == "y"
+		if r.Doubles && r.PartySize < 2 {
+			r.PartySize = 2
+			fmt.Println("Doubles needs two Pokemon each; party size set to 2.")
+		}
+	}
+
+	if r.TurnLimit == 0 {
+		r.TurnLimit = readWholeNumber("Select a turn limit, settled by tiebreakers (0 for none, Enter for none):", "turns")
+	}
+	if r.TurnTimer == 0 && r.GameClock == 0 && !local {
+		r.TurnTimer = readWholeNumber("Select a turn timer in seconds (0 for none, Enter for none):", "seconds")
+		r.GameClock = readWholeNumber("Select a total game clock per player in seconds (0 for none, Enter for none):", "seconds")
+		if r.TurnTimer > 0 || r.GameClock > 0 {
+			forfeit := strings.ToLower(netio.PRLine("Forfeit on timeout instead of using a default move? [y / N:default]"))
+			if forfeit == "y" {
+				r.TimeoutAction = rules.TimeoutForfeit
+			}
+		}
+	}
+
+	return r
+}
+
+// readWholeNumber reads a non-negative number of units (e.g. "seconds");
+// Enter means 0.
+func readWholeNumber(prompt string, units string) int {
+	for {
+		input := netio.PRLine(prompt)
+		if input == "" {
+			return 0
+		}
+
+		value, err := strconv.Atoi(input)
+		if err != nil || value < 0 {
+			netio.ERLine("Invalid input. Should be a whole number of "+units, false)
+			continue
+		}
+		return value
+	}
+}
+
+// chooseFormat lists the formats in FormatsDir and asks the host to pick one.
+// Formats a local battle cannot play are left out when local is set.
+// Returns false for custom rules, including when there are no formats.
+func chooseFormat(local bool) (rules.Format, bool) {
+	formats, err := rules.LoadFormats(FormatsDir)
+	if err != nil {
+		netio.ERLine(fmt.Sprintf("Could not load formats: %v", err), false)
+		return rules.Format{}, false
+	}
+	if local {
+		formats = slices.DeleteFunc(formats, func(f rules.Format) bool {
+			return f.Doubles || f.TurnTimer > 0 || f.GameClock > 0
+		})
+	}
+	if len(formats) == 0 {
+		return rules.Format{}, false
+	}
+
+	fmt.Println("Formats:")
+	for i, f := range formats {
+		fmt.Printf("  %d. %s - %s\n", i+1, f.Name, f.Description)
+	}
+	for {
+		input := netio.PRLine(fmt.Sprintf("Select a format (1-%d, Enter for custom rules):", len(formats)))
+		if input == "" {
+			return rules.Format{}, false
+		}
+
+		choice, err := strconv.Atoi(input)
+		if err != nil || choice < 1 || choice > len(formats) {
+			netio.ERLine(fmt.Sprintf("Invalid input. Should be a number from 1--%d", len(formats)), false)
+			continue
+		}
+		return formats[choice-1], true
+	}
+}
+
+// FindFormat loads the formats in FormatsDir and returns the ruleset of the
+// one with the given name, ignoring case.
+func FindFormat(name string) (rules.Ruleset, error) {
+	formats, err := rules.LoadFormats(FormatsDir)
+	if err != nil {
+		return rules.Ruleset{}, err
+	}
+
+	var names []string
+	for _, format := range formats {
+		if strings.EqualFold(format.Name, name) {
+			return format.Ruleset(), nil
+		}
+		names = append(names, format.Name)
+	}
+	return rules.Ruleset{}, fmt.Errorf("no format named %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Host_setCMode asks the host for the communication mode and sends it,
+// followed by the RULESET, to every joiner and any spectators.
+func Host_setCMode(host peer.PeerDescriptor, joiners []peer.PeerDescriptor, r rules.Ruleset, spectators []peer.PeerDescriptor) string {
 	for {
 		mode := strings.ToUpper(netio.PRLine("Select a communication mode:\nP: peer-to-peer\nB: broadcast"))
 
@@ -21,15 +272,31 @@ func Host_setCMode(host peer.PeerDescriptor, join peer.PeerDescriptor) string {
 		case P2P:
 			fallthrough
 		case Broadcast:
-			msg := messages.GS_MakeCMode(mode)
-			host.Conn.WriteToUDP(msg.SerializeMessage(), join.Addr)
+			msg := messages.GS_MakeCMode(mode, poke.MovesHash(), poke.TypeChartHash())
+			rulesMsg := messages.GS_MakeRuleset(r)
+			for _, m := range []messages.Message{msg, rulesMsg} {
+				for _, join := range joiners {
+					host.Conn.WriteToUDP(m.SerializeMessage(), join.Addr)
+				}
+				for _, spectator := range spectators {
+					host.Conn.WriteToUDP(m.SerializeMessage(), spectator.Addr)
+				}
+			}
 
-			netio.VerboseEventLog(
-				"PokeProtocol: Host Peer sent COMM_MODE message to Joiner Peer '"+join.Name+"'",
-				&netio.LogOptions{
-					MessageParams: msg.MessageParams,
-				},
-			)
+			for _, join := range joiners {
+				netio.VerboseEventLog(
+					"PokeProtocol: Host Peer sent COMM_MODE message to Joiner Peer '"+join.Name+"'",
+					&netio.LogOptions{
+						MessageParams: msg.MessageParams,
+					},
+				)
+				netio.VerboseEventLog(
+					"PokeProtocol: Host Peer sent RULESET message to Joiner Peer '"+join.Name+"'",
+					&netio.LogOptions{
+						MessageParams: rulesMsg.MessageParams,
+					},
+				)
+			}
 
 			return mode
 		default:
@@ -38,17 +305,22 @@ func Host_setCMode(host peer.PeerDescriptor, join peer.PeerDescriptor) string {
 	}
 }
 
-func Joiner_getCMode(p peer.PeerDescriptor) string {
+// Joiner_getCMode waits for the host's COMM_MODE and RULESET messages and
+// returns the communication mode together with the host's ruleset.
+func Joiner_getCMode(p peer.PeerDescriptor) (string, rules.Ruleset) {
 	buf := make([]byte, 65535)
 
-	for {
+	mode := ""
+	var r *rules.Ruleset
+	for mode == "" || r == nil {
 		n, _, err := p.Conn.ReadFromUDP(buf)
 		if err != nil {
 			panic(err)
 		}
 		msg := messages.DeserializeMessage(buf[:n])
 
-		if msg.MessageType == messages.GS_COMMMODE {
+		switch msg.MessageType {
+		case messages.GS_COMMMODE:
 			netio.VerboseEventLog(
 				"PokeProtocol: Joiner Peer received COMM_MODE message from Host Peer",
 				&netio.LogOptions{
@@ -56,15 +328,140 @@ func Joiner_getCMode(p peer.PeerDescriptor) string {
 				},
 			)
 
-			return (*msg.MessageParams)["cmode"].(string)
+			// Both peers rebuild each other's moves from their own move data
+			if hash, _ := (*msg.MessageParams)["moves_hash"].(string); hash != poke.MovesHash() {
+				panic(fmt.Sprintf("Host's move data (%s) does not match ours (%s); check data/moves.json", hash, poke.MovesHash()))
+			}
+			if hash, _ := (*msg.MessageParams)["type_chart_hash"].(string); hash != poke.TypeChartHash() {
+				panic(fmt.Sprintf("Host's type chart (%s) does not match ours (%s); check data/typechart.json", hash, poke.TypeChartHash()))
+			}
+			mode = (*msg.MessageParams)["cmode"].(string)
+		case messages.GS_RULESET:
+			netio.VerboseEventLog(
+				"PokeProtocol: Joiner Peer received RULESET message from Host Peer",
+				&netio.LogOptions{
+					MessageParams: msg.MessageParams,
+				},
+			)
+
+			parsed := ParseRuleset(*msg.MessageParams)
+			r = &parsed
 		}
 	}
+	return mode, *r
 }
 
-func PlayerSetUp(self peer.PeerDescriptor) player.Player {
-	var err error
-	var ok bool
+// ParseRuleset reads the fields of a RULESET message.
+// Missing fields keep their default values.
+func ParseRuleset(params map[string]any) rules.Ruleset {
+	r := rules.Default()
+	if levelCap, ok := params["level_cap"].(int); ok {
+		r.LevelCap = levelCap
+	}
+	if cosmetic, ok := params["cosmetic_personalities"].(string); ok {
+		r.CosmeticPersonalities = cosmetic == "true"
+	}
+	if friendship, ok := params["friendship_effects"].(string); ok {
+		r.FriendshipEffects = friendship == "true"
+	}
+	if heldItems, ok := params["held_items"].(string); ok {
+		r.HeldItems = heldItems == "true"
+	}
+	if bagLimit, ok := params["bag_limit"].(int); ok {
+		r.BagLimit = bagLimit
+	}
+	if partySize, ok := params["party_size"].(int); ok {
+		r.PartySize = partySize
+	}
+	if boostSpecial, ok := params["boost_special"].(string); ok {
+		r.BoostSpecial = boostSpecial == "true"
+	}
+	if boostPhysical, ok := params["boost_physical"].(string); ok {
+		r.BoostPhysical = boostPhysical == "true"
+	}
+	// Whole multipliers arrive as ints, fractional ones as strings
+	switch multiplier := params["boost_multiplier"].(type) {
+	case int:
+		r.BoostMultiplier = float64(multiplier)
+	case string:
+		if parsed, err := strconv.ParseFloat(multiplier, 64); err == nil {
+			r.BoostMultiplier = parsed
+		}
+	}
+	if boostBudget, ok := params["boost_budget"].(int); ok {
+		r.BoostBudget = boostBudget
+	}
+	if format, ok := params["format"].(string); ok {
+		r.Format = format
+	}
+	if banLegendary, ok := params["ban_legendary"].(string); ok {
+		r.BanLegendary = banLegendary == "true"
+	}
+	// A single generation arrives as an int, several as "1,2,3"
+	switch generations := params["generations"].(type) {
+	case int:
+		r.Generations = []int{generations}
+	case string:
+		for _, part := range strings.Split(generations, ",") {
+			if generation, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+				r.Generations = append(r.Generations, generation)
+			}
+		}
+	}
+	if speciesClause, ok := params["species_clause"].(string); ok {
+		r.SpeciesClause = speciesClause == "true"
+	}
+	if turnLimit, ok := params["turn_limit"].(int); ok {
+		r.TurnLimit = turnLimit
+	}
+	if turnTimer, ok := params["turn_timer"].(int); ok {
+		r.TurnTimer = turnTimer
+	}
+	if gameClock, ok := params["game_clock"].(int); ok {
+		r.GameClock = gameClock
+	}
+	if timeoutAction, ok := params["timeout_action"].(string); ok {
+		if timeoutAction != rules.TimeoutDefault && timeoutAction != rules.TimeoutForfeit {
+			panic(fmt.Sprintf("Unsupported timeout action from host: %q", timeoutAction))
+		}
+		r.TimeoutAction = timeoutAction
+	}
+	if inverse, ok := params["inverse_battle"].(string); ok {
+		r.InverseBattle = inverse == "true"
+	}
+	if doubles, ok := params["doubles"].(string); ok {
+		r.Doubles = doubles == "true"
+	}
+	if players, ok := params["players"].(int); ok {
+		if players < 2 || players > rules.MaxPlayers {
+			panic(fmt.Sprintf("Unsupported number of trainers from host: %d", players))
+		}
+		r.Players = players
+	}
+	if boss, ok := params["raid_boss"].(string); ok && boss != "" {
+		level, _ := params["raid_level"].(int)
+		multiplier, _ := params["raid_hp_multiplier"].(int)
+		actions, _ := params["raid_actions"].(int)
+		if r.Players != 2 || level < poke.MinLevel || level > poke.MaxLevel ||
+			multiplier < 1 || multiplier > rules.MaxRaidHPMultiplier ||
+			actions < 1 || actions > rules.MaxRaidActions {
+			panic(fmt.Sprintf("Unsupported raid from host: %s Lv. %d, x%d HP, %d actions", boss, level, multiplier, actions))
+		}
+		r.RaidBoss = boss
+		r.RaidLevel = level
+		r.RaidHPMultiplier = multiplier
+		r.RaidActions = actions
+	}
+	if model, ok := params["damage_model"].(string); ok {
+		if !rules.ValidDamageModel(model) {
+			panic(fmt.Sprintf("Unsupported damage model from host: %q", model))
+		}
+		r.DamageModel = model
+	}
+	return r
+}
 
+func PlayerSetUp(self peer.PeerDescriptor, r rules.Ruleset) player.Player {
 	// Use trainer name from login for profiles
 	trainerName := self.Name
 	teamManager := poke.NewTeamManager(trainerName)
@@ -76,10 +473,74 @@ func PlayerSetUp(self peer.PeerDescriptor) player.Player {
 		teamManager.ListProfiles()
 	}
 
+	fmt.Printf("Format: %s\n", r.DescribeFormat())
+	pokemonStruct, profile := selectPokemon(teamManager, r, "Select a pokemon: ", nil)
+
+	// Reserves can be switched in during battle
+	var reserves []poke.Pokemon
+	for len(reserves) < r.PartySize-1 {
+		more := strings.ToLower(netio.PRLine(fmt.Sprintf("Add a reserve Pokemon? (%d/%d) [y / N:default]", len(reserves), r.PartySize-1)))
+		if more != "y" {
+			break
+		}
+		party := append([]poke.Pokemon{pokemonStruct}, reserves...)
+		reserve, _ := selectPokemon(teamManager, r, "Select a reserve pokemon: ", party)
+		reserves = append(reserves, reserve)
+	}
+
+	// allocate boost points to the categories the ruleset allows
+	var spatk, spdef, phatk, phdef int
+	for r.BoostBudget > 0 {
+		fmt.Printf("You can allocate %d points to your boosts (%s), use it wisely.\n", r.BoostBudget, r.DescribeBoosts())
+		spatk, spdef, phatk, phdef = 0, 0, 0, 0
+		if r.BoostSpecial {
+			spatk = readAllocation("Special attack allocation: ", r.BoostBudget)
+			spdef = readAllocation("Special defense allocation: ", r.BoostBudget)
+		}
+		if r.BoostPhysical {
+			phatk = readAllocation("Physical attack allocation: ", r.BoostBudget)
+			phdef = readAllocation("Physical defense allocation: ", r.BoostBudget)
+		}
+
+		if err := r.CheckBoosts(spatk, spdef, phatk, phdef); err != nil {
+			netio.ERLine(fmt.Sprintf("Invalid inputs. Sum should be at most %d", r.BoostBudget), false)
+		} else {
+			break
+		}
+	}
+
+	// Pack the bag within the ruleset's limits
+	bag := packBag(r.BagLimit)
+
+	return player.Player{
+		Peer:                    self,
+		PokemonStruct:           pokemonStruct,
+		SpecialAttackUsesLeft:   spatk,
+		SpecialDefenseUsesLeft:  spdef,
+		PhysicalAttackUsesLeft:  phatk,
+		PhysicalDefenseUsesLeft: phdef,
+		Profile:                 profile,
+		TrainerName:             trainerName,
+		Friendship:              profile.Friendship,
+		Bag:                     bag,
+		Reserves:                reserves,
+	}
+}
+
+// selectPokemon asks for a Pokemon, customizes it and computes its battle
+// stats under the ruleset. Only species the ruleset allows alongside the
+// rest of the party are accepted; "list" shows them.
+func selectPokemon(teamManager *poke.TeamManager, r rules.Ruleset, prompt string, party []poke.Pokemon) (poke.Pokemon, *poke.PokemonProfile) {
 	// get pokemon name
 	var pokemonStruct poke.Pokemon
+	var ok bool
 	for {
-		pokeName := netio.PRLine("Select a pokemon: ")
+		pokeName := netio.PRLine(prompt)
+		if strings.EqualFold(pokeName, "list") {
+			legal := legalSpecies(r, party)
+			fmt.Printf("%d legal Pokemon: %s\n", len(legal), strings.Join(legal, ", "))
+			continue
+		}
 		// Try exact match first, then case-insensitive
 		pokemonStruct, ok = monsters.MONSTERS[pokeName]
 		if !ok {
@@ -94,9 +555,13 @@ func PlayerSetUp(self peer.PeerDescriptor) player.Player {
 		}
 		if !ok {
 			netio.ERLine("Invalid pokemon. Please put a valid pokemon name", false)
-		} else {
-			break
+			continue
 		}
+		if err := r.CheckParty(append(slices.Clone(party), pokemonStruct)); err != nil {
+			netio.ERLine(fmt.Sprintf("%v. Type 'list' to see the legal Pokemon", err), false)
+			continue
+		}
+		break
 	}
 
 	// Customize Pokemon (nickname & personality)
@@ -104,51 +569,111 @@ func PlayerSetUp(self peer.PeerDescriptor) player.Player {
 	if err != nil {
 		fmt.Printf("Warning: Could not customize Pokemon: %v\n", err)
 		profile = poke.NewPokemonProfile(pokemonStruct.Name)
+		profile.EnsureTraining(pokemonStruct.ExperienceGrowth)
+	}
+
+	// Compute battle stats from the profile, scaled down to the level cap
+	level := r.CapLevel(profile.Level)
+	if level < profile.Level {
+		fmt.Printf("%s battles at level %d under the level cap (trained to %d).\n",
+			profile.GetDisplayName(), level, profile.Level)
+	}
+	nature := r.BattleNature(poke.GetNature(profile.Nature))
+	if r.CosmeticPersonalities {
+		fmt.Println("Personalities are cosmetic in this battle; natures do not change stats.")
+	}
+	pokemonStruct.ApplyStats(level, profile.IVs, profile.EVs, nature)
+
+	// Choose a held item within the ruleset's limits
+	if r.HeldItems {
+		pokemonStruct.HeldItem = chooseHeldItem()
+	}
+
+	return pokemonStruct, profile
+}
+
+// LegalSpecies returns the sorted names of the species a ruleset allows.
+func LegalSpecies(r rules.Ruleset) []string {
+	return legalSpecies(r, nil)
+}
+
+// legalSpecies returns the sorted names of the species that may join the party.
+func legalSpecies(r rules.Ruleset, party []poke.Pokemon) []string {
+	var names []string
+	for name, mon := range monsters.MONSTERS {
+		if r.CheckParty(append(slices.Clone(party), mon)) == nil {
+			names = append(names, name)
+		}
 	}
+	sort.Strings(names)
+	return names
+}
 
-	// allocate spatk and spdef
-	var spdef int
-	var spatk int
+// readAllocation reads a boost allocation from 0 to budget.
+func readAllocation(prompt string, budget int) int {
 	for {
-		fmt.Println("You can allocate 10 points to your special attack and special defense, use it wisely.")
-		spatk, err = strconv.Atoi(netio.PRLine("Special attack allocation: "))
-		if err != nil || spatk > 10 || spatk < 0 {
-			netio.ERLine("Invalid input. Should be a number from 1--10", false)
-			continue
+		points, err := strconv.Atoi(netio.PRLine(prompt))
+		if err == nil && points >= 0 && points <= budget {
+			return points
+		}
+		netio.ERLine(fmt.Sprintf("Invalid input. Should be a number from 0--%d", budget), false)
+	}
+}
+
+// chooseHeldItem asks the player for a held item. Returns "" for none.
+func chooseHeldItem() string {
+	fmt.Println("\nHeld items:")
+	for _, name := range poke.ItemNames(poke.HeldItem) {
+		fmt.Printf("  %s - %s\n", name, poke.Items[name].Description)
+	}
+
+	for {
+		input := netio.PRLine("Select a held item (Enter for none): ")
+		if input == "" {
+			return ""
 		}
 
-		spdef, err = strconv.Atoi(netio.PRLine("Special defense allocation: "))
-		if err != nil || spdef > 10 || spdef < 0 {
-			netio.ERLine("Invalid input. Should be a number from 1--10", false)
+		item, ok := poke.GetItem(input)
+		if !ok || item.Kind != poke.HeldItem {
+			netio.ERLine("Invalid held item. Please put a listed item name", false)
 			continue
 		}
+		return item.Name
+	}
+}
 
-		if spatk+spdef > 10 {
-			netio.ERLine("Invalid inputs. Sum should be at most 10", false)
-		} else {
+// packBag asks the player which consumables to bring, up to limit items.
+func packBag(limit int) poke.Bag {
+	bag := poke.Bag{}
+	if limit <= 0 {
+		return bag
+	}
+
+	fmt.Printf("\nYou can bring up to %d items in your bag:\n", limit)
+	for _, name := range poke.ItemNames(poke.BagItem) {
+		fmt.Printf("  %s - %s\n", name, poke.Items[name].Description)
+	}
+
+	for bag.Count() < limit {
+		input := netio.PRLine(fmt.Sprintf("Add an item (%d left, Enter to finish): ", limit-bag.Count()))
+		if input == "" {
 			break
 		}
-	}
 
-	return player.Player{
-		Peer:                   self,
-		PokemonStruct:          pokemonStruct,
-		SpecialAttackUsesLeft:  spatk,
-		SpecialDefenseUsesLeft: spdef,
-		Profile:                profile,
-		TrainerName:            trainerName,
+		item, ok := poke.GetItem(input)
+		if !ok || item.Kind != poke.BagItem {
+			netio.ERLine("Invalid bag item. Please put a listed item name", false)
+			continue
+		}
+		bag[item.Name]++
 	}
+
+	return bag
 }
 
-func BattleSetup(self player.Player, other peer.PeerDescriptor, cmode string, spectators []peer.PeerDescriptor) player.Player {
+func BattleSetup(self player.Player, other peer.PeerDescriptor, cmode string, r rules.Ruleset, spectators []peer.PeerDescriptor) player.Player {
 	// Send BATTLE_SETUP
-	msg := messages.MakeBattleSetup(
-		self,
-		cmode,
-		self.PokemonStruct.Name,
-		int8(self.SpecialAttackUsesLeft),
-		int8(self.SpecialDefenseUsesLeft),
-	)
+	msg := makeSetupMessage(self, cmode)
 
 	msgBytes := msg.SerializeMessage()
 
@@ -213,40 +738,165 @@ func BattleSetup(self player.Player, other peer.PeerDescriptor, cmode string, sp
 				)
 			}
 
-			// Parse opponent's Pokemon data from the message
-			params := *res.MessageParams
-			opponentPokemonName := params["pokemon_name"].(string)
-			specialAttackUses := params["special_attack_uses"].(int)
-			specialDefenseUses := params["special_defense_uses"].(int)
-
-			// Load opponent's Pokemon
-			opponentPokemon, ok := monsters.MONSTERS[opponentPokemonName]
-			if !ok {
-				// Try case-insensitive
-				for key, mon := range monsters.MONSTERS {
-					if strings.EqualFold(key, opponentPokemonName) {
-						opponentPokemon = mon
-						ok = true
-						break
-					}
-				}
-			}
+			return playerFromSetup(*res.MessageParams, other, r)
+		}
+	}
+}
 
-			if !ok {
-				panic(fmt.Sprintf("Unknown Pokemon: %s", opponentPokemonName))
-			}
+// makeSetupMessage builds a player's BATTLE_SETUP message.
+func makeSetupMessage(self player.Player, cmode string) messages.Message {
+	return messages.MakeBattleSetup(
+		self,
+		cmode,
+		self.PokemonStruct.Name,
+		int8(self.SpecialAttackUsesLeft),
+		int8(self.SpecialDefenseUsesLeft),
+		int8(self.PhysicalAttackUsesLeft),
+		int8(self.PhysicalDefenseUsesLeft),
+		self.PokemonStruct.Level,
+		self.PokemonStruct.IVs,
+		self.PokemonStruct.EVs,
+		self.PokemonStruct.Nature.Name,
+		self.Friendship,
+		self.PokemonStruct.HeldItem,
+		self.Bag,
+		self.Reserves,
+	)
+}
+
+// playerFromSetup builds the player described by a BATTLE_SETUP message and
+// panics if the setup breaks the ruleset.
+func playerFromSetup(params map[string]any, other peer.PeerDescriptor, r rules.Ruleset) player.Player {
+	specialAttackUses := params["special_attack_uses"].(int)
+	specialDefenseUses := params["special_defense_uses"].(int)
+	physicalAttackUses, _ := params["physical_attack_uses"].(int)
+	physicalDefenseUses, _ := params["physical_defense_uses"].(int)
+	if err := r.CheckBoosts(specialAttackUses, specialDefenseUses, physicalAttackUses, physicalDefenseUses); err != nil {
+		panic(fmt.Sprintf("Opponent's boost allocation is invalid: %v", err))
+	}
+	friendship, _ := params["friendship"].(int)
+	if friendship < 0 || friendship > 100 {
+		panic(fmt.Sprintf("Invalid friendship value from opponent: %d", friendship))
+	}
 
-			// Create opponent player
-			opponentPlayer := player.Player{
-				Peer:                   other,
-				PokemonStruct:          opponentPokemon,
-				SpecialAttackUsesLeft:  specialAttackUses,
-				SpecialDefenseUsesLeft: specialDefenseUses,
+	// Load opponent's Pokemon and compute its battle stats
+	opponentPokemon, err := PokemonFromSetup(params)
+	if err != nil {
+		panic(err.Error())
+	}
+	if opponentPokemon.Level > r.CapLevel(opponentPokemon.Level) {
+		panic(fmt.Sprintf("Opponent's %s is level %d, above the level cap of %d",
+			opponentPokemon.Name, opponentPokemon.Level, r.LevelCap))
+	}
+	if r.CosmeticPersonalities && !opponentPokemon.Nature.IsNeutral() {
+		panic(fmt.Sprintf("Opponent's %s has a %s nature, but personalities are cosmetic",
+			opponentPokemon.Name, opponentPokemon.Nature.Name))
+	}
+	bagParam, _ := params["bag"].(string)
+	bag, err := poke.ParseBag(bagParam)
+	if err == nil {
+		err = r.CheckItems(opponentPokemon.HeldItem, bag)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Opponent's items are invalid: %v", err))
+	}
+
+	// Load the opponent's reserves the same way
+	reserveCount, _ := params["reserves"].(int)
+	if reserveCount > r.PartySize-1 {
+		panic(fmt.Sprintf("Opponent brought %d reserves, the party size is %d", reserveCount, r.PartySize))
+	}
+	reserves := make([]poke.Pokemon, 0, reserveCount)
+	for i := range reserveCount {
+		reserve, err := PokemonFromSetup(messages.ReserveParams(params, i))
+		if err == nil && reserve.Level > r.CapLevel(reserve.Level) {
+			err = fmt.Errorf("reserve %s is above the level cap", reserve.Name)
+		}
+		if err == nil && r.CosmeticPersonalities && !reserve.Nature.IsNeutral() {
+			err = fmt.Errorf("reserve %s has a %s nature, but personalities are cosmetic", reserve.Name, reserve.Nature.Name)
+		}
+		if err == nil {
+			err = r.CheckItems(reserve.HeldItem, nil)
+		}
+		if err != nil {
+			panic(err.Error())
+		}
+		reserves = append(reserves, reserve)
+	}
+	if err := r.CheckParty(append([]poke.Pokemon{opponentPokemon}, reserves...)); err != nil {
+		panic(fmt.Sprintf("Opponent's party is not legal in this format: %v", err))
+	}
+
+	return player.Player{
+		Peer:                    other,
+		PokemonStruct:           opponentPokemon,
+		SpecialAttackUsesLeft:   specialAttackUses,
+		SpecialDefenseUsesLeft:  specialDefenseUses,
+		PhysicalAttackUsesLeft:  physicalAttackUses,
+		PhysicalDefenseUsesLeft: physicalDefenseUses,
+		Friendship:              friendship,
+		Bag:                     bag,
+		Reserves:                reserves,
+	}
+}
+
+// PokemonFromSetup loads the Pokemon named in a BATTLE_SETUP message and
+// applies the level, IVs, EVs, nature and held item it carries.
+func PokemonFromSetup(params map[string]any) (poke.Pokemon, error) {
+	pokemonName, _ := params["pokemon_name"].(string)
+
+	pokemon, ok := monsters.MONSTERS[pokemonName]
+	if !ok {
+		// Try case-insensitive
+		for key, mon := range monsters.MONSTERS {
+			if strings.EqualFold(key, pokemonName) {
+				pokemon = mon
+				ok = true
+				break
 			}
+		}
+	}
+	if !ok {
+		return pokemon, fmt.Errorf("Unknown Pokemon: %s", pokemonName)
+	}
 
-			return opponentPlayer
+	if heldItem, ok := params["held_item"].(string); ok {
+		item, found := poke.GetItem(heldItem)
+		if !found || item.Kind != poke.HeldItem {
+			return pokemon, fmt.Errorf("unknown held item %q for %s", heldItem, pokemon.Name)
 		}
+		pokemon.HeldItem = item.Name
 	}
+
+	// Older peers omit training data; keep the CSV defaults in that case
+	level, ok := params["level"].(int)
+	if !ok {
+		return pokemon, nil
+	}
+
+	ivs, err := poke.ParseStatSet(fmt.Sprint(params["ivs"]))
+	if err == nil {
+		err = poke.ValidateIVs(ivs)
+	}
+	if err != nil {
+		return pokemon, fmt.Errorf("invalid IVs for %s: %w", pokemon.Name, err)
+	}
+
+	evs, err := poke.ParseStatSet(fmt.Sprint(params["evs"]))
+	if err == nil {
+		err = poke.ValidateEVs(evs)
+	}
+	if err != nil {
+		return pokemon, fmt.Errorf("invalid EVs for %s: %w", pokemon.Name, err)
+	}
+
+	if level < poke.MinLevel || level > poke.MaxLevel {
+		return pokemon, fmt.Errorf("invalid level %d for %s", level, pokemon.Name)
+	}
+
+	nature, _ := params["nature"].(string)
+	pokemon.ApplyStats(level, ivs, evs, poke.GetNature(nature))
+	return pokemon, nil
 }
 
 // func Host_PBSetUp(
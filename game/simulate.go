@@ -0,0 +1,70 @@
+package game
+
+import (
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+)
+
+// SimMaxTurns ends a simulated battle with no turn limit as a draw, so two
+// trainers that cannot hurt each other do not battle forever.
+const SimMaxTurns = 500
+
+// SimResult is the outcome of a simulated battle.
+type SimResult struct {
+	Winner string    // "host", "joiner", or "" for a draw
+	Opener string    // "host" or "joiner": who went first in the first round
+	Turns  int       // Actions taken, as the turn counter counts them
+	Hits   []float64 // Damage of every damaging attack, in percent of the defender's max HP
+}
+
+// SimulateBattle plays a duel between two trainers to the end on PlayDuel
+// with no output and no sockets, each side played by its decider. Both
+// players are changed, so pass copies to keep the originals. Safe to run in
+// parallel for different players and deciders.
+func SimulateBattle(host *player.Player, joiner *player.Player, hostDecider Decider, joinerDecider Decider, seed int, r rules.Ruleset) SimResult {
+	game := NewGame(seed, P2P, LocalRules(r))
+	game.Host, game.Joiner = host, joiner
+	game.State = StateWaitingForMove
+	game.Field.ApplyEntryAbility(&host.PokemonStruct)
+	game.Field.ApplyEntryAbility(&joiner.PokemonStruct)
+
+	view := &simView{}
+	end := PlayDuel(game, hostDecider, joinerDecider, view, SimMaxTurns)
+	result := SimResult{Opener: view.opener, Turns: end.Turns, Hits: view.hits}
+	if end.Winner != nil {
+		result.Winner = game.SideOf(end.Winner)
+	}
+	return result
+}
+
+// simView shows nothing. It records the damage of every damaging attack
+// and who went first.
+type simView struct {
+	hits   []float64
+	opener string // Who went first in the first round
+}
+
+func (v *simView) TurnStarted(g *Game, turn int) {}
+
+func (v *simView) Choosing(g *Game, p *player.Player) {}
+
+func (v *simView) RoundOpened(g *Game, opener *player.Player) {
+	if v.opener == "" {
+		v.opener = g.SideOf(opener)
+	}
+}
+
+func (v *simView) ActionChosen(g *Game, attacker *player.Player, action TurnAction, rejected error) {}
+
+func (v *simView) DefenseBoostAsked(g *Game, defender *player.Player) {}
+
+func (v *simView) ActionResolved(g *Game, attacker *player.Player, defender *player.Player, action TurnAction, defenseBoost bool, outcome AttackOutcome) {
+	if action.Type == ActionAttack && action.Move.IsDamaging() && !outcome.Protected {
+		v.hits = append(v.hits, 100*float64(outcome.Damage)/float64(max(defender.PokemonStruct.MaxHP, 1)))
+	}
+}
+
+func (v *simView) TurnFinished(g *Game, attacker *player.Player, defender *player.Player, events []string) {
+}
+
+func (v *simView) DuelEnded(g *Game, end DuelEnd) {}
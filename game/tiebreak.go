@@ -0,0 +1,44 @@
+package game
+
+import (
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/messages"
+)
+
+// TurnLimitReached reports whether a battle has played the ruleset's last
+// turn. turnNumber counts every player's turn, as shown in the battle loop.
+func (g *Game) TurnLimitReached(turnNumber int) bool {
+	return g.Rules.TurnLimit > 0 && turnNumber >= g.Rules.TurnLimit
+}
+
+// Tiebreak decides a battle stopped at the turn limit. The player with the
+// larger share of their team's HP left wins; if that is even, the player who
+// dealt more damage wins. Returns the winner and the deciding tiebreaker
+// (messages.TiebreakHP or messages.TiebreakDamage), or nil and "" for a draw.
+// Both peers reach the same decision because it only uses synchronized state.
+func (g *Game) Tiebreak() (*player.Player, string) {
+	hostHP, hostMax := g.Host.TeamHP()
+	joinerHP, joinerMax := g.Joiner.TeamHP()
+
+	// Compare hostHP/hostMax with joinerHP/joinerMax without floating point
+	switch hostShare, joinerShare := hostHP*max(joinerMax, 1), joinerHP*max(hostMax, 1); {
+	case hostShare > joinerShare:
+		return g.Host, messages.TiebreakHP
+	case joinerShare > hostShare:
+		return g.Joiner, messages.TiebreakHP
+	}
+
+	switch {
+	case g.Host.DamageDealt > g.Joiner.DamageDealt:
+		return g.Host, messages.TiebreakDamage
+	case g.Joiner.DamageDealt > g.Host.DamageDealt:
+		return g.Joiner, messages.TiebreakDamage
+	}
+	return nil, ""
+}
+
+// HPPercent returns the share of a player's team HP that is left, in percent.
+func HPPercent(p *player.Player) float64 {
+	hp, maxHP := p.TeamHP()
+	return float64(hp) / float64(max(maxHP, 1)) * 100
+}
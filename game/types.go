@@ -6,7 +6,9 @@ import (
 	"math/rand"
 
 	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
 	"github.com/zrygan/pokemonbattler/peer"
+	"github.com/zrygan/pokemonbattler/poke"
 )
 
 // Game represents the state of a Pokemon battle.
@@ -18,9 +20,23 @@ type Game struct {
 	Seed              int                   // Random seed for synchronized RNG
 	RNG               *rand.Rand            // Seeded random number generator
 	CommunicationMode string                // P2P (P) or broadcast (B) mode
+	Rules             rules.Ruleset         // Ruleset agreed during setup
+	TypeChart         poke.TypeChart        // Type matchups of the ruleset, e.g. the inverse chart
+	Field             Field                 // Weather and terrain, updated identically by both peers
 	State             BattleState           // Current battle state
 	CurrentTurn       string                // "host" or "joiner" - whose turn it is
+	TurnsThisRound    int                   // Actions taken so far in the current round
+	Clock             *Clock                // Turn timer and game clock (disabled when untimed)
+	RoundOrder        []Actor               // Doubles: actors still to move this round
+	ActingSlot        int                   // Doubles: slot of the Pokemon whose turn it is
+	Players           []*player.Player      // Free-for-all: every trainer by seat, the host in seat 0
+	SeatOrder         []int                 // Free-for-all: seats still to move this round
+	Eliminated        []int                 // Free-for-all: knocked out seats, first out first
+	BossScript        []string              // Raid: the boss's moves in order (host only)
+	BossTurns         int                   // Raid: actions the boss has taken
 	BattleLog         []string              // Log of all battle events
+
+	rngSource *countingSource // Source behind RNG, so Fork can copy its position
 }
 
 const (
@@ -29,14 +45,19 @@ const (
 )
 
 // NewGame creates a new Game instance with the given seed.
-func NewGame(seed int, commMode string) *Game {
+func NewGame(seed int, commMode string, r rules.Ruleset) *Game {
+	source := newCountingSource(int64(seed))
 	return &Game{
 		Seed:              seed,
-		RNG:               rand.New(rand.NewSource(int64(seed))),
+		RNG:               rand.New(source),
 		CommunicationMode: commMode,
+		Rules:             r,
+		TypeChart:         r.TypeChart(),
+		Clock:             NewClock(r),
 		State:             StateSetup,
-		CurrentTurn:       "host", // Host always goes first
+		CurrentTurn:       "host", // Host always opens the first round
 		Spectators:        make([]peer.PeerDescriptor, 0),
+		rngSource:         source,
 	}
 }
 
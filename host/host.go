@@ -5,10 +5,12 @@ package main
 import (
 	"flag"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/zrygan/pokemonbattler/game"
+	"github.com/zrygan/pokemonbattler/game/rules"
 	"github.com/zrygan/pokemonbattler/messages"
 	"github.com/zrygan/pokemonbattler/netio"
 	"github.com/zrygan/pokemonbattler/peer"
@@ -103,7 +105,12 @@ func waitForMatch(self peer.PeerDescriptor) (peer.PeerDescriptor, []peer.PeerDes
 // handshake sends a handshake response to the joiner and returns the battle seed.
 // The seed is used to synchronize random number generation between host and joiner.
 func handshake(self peer.PeerDescriptor, join peer.PeerDescriptor) int {
-	msg := messages.MakeHandshakeResponse()
+	return sendHandshake(self, join, messages.MakeHandshakeResponse())
+}
+
+// sendHandshake sends a built handshake response to the joiner and returns
+// the seed it carries.
+func sendHandshake(self peer.PeerDescriptor, join peer.PeerDescriptor, msg messages.Message) int {
 	self.Conn.WriteToUDP(msg.SerializeMessage(), join.Addr)
 
 	netio.VerboseEventLog(
@@ -120,16 +127,131 @@ func handshake(self peer.PeerDescriptor, join peer.PeerDescriptor) int {
 	return val
 }
 
+// hostFreeForAll accepts joiners until the free-for-all has the given number
+// of trainers, sets it up and runs it, with decider battling for the host
+// when set. Returns the spectators that watched.
+func hostFreeForAll(self peer.PeerDescriptor, trainers int, decider game.Decider) []peer.PeerDescriptor {
+	joiners, spectators, seed := acceptTrainers(self, trainers)
+
+	// choose the battle rules; every trainer battles with one active Pokemon
+	ruleset := game.Host_setRules()
+	ruleset.Players = trainers
+	if ruleset.Doubles {
+		fmt.Println("Doubles is not available in a free-for-all; playing singles.")
+		ruleset.Doubles = false
+	}
+
+	cmode := game.Host_setCMode(self, joiners, ruleset, spectators)
+	game.Host_sendRoster(self, joiners)
+
+	p := game.PlayerSetUp(self, ruleset)
+	players := game.Host_FreeForAllSetup(p, joiners, cmode, ruleset, spectators)
+	game.RunFreeForAll(players, 0, seed, cmode, ruleset, spectators, decider)
+	return spectators
+}
+
+// hostRaid accepts a second trainer, sets up the raid and runs it with the
+// host's engine playing the boss, and decider battling for the host when
+// set. Returns the spectators that watched.
+func hostRaid(self peer.PeerDescriptor, raid rules.Raid, decider game.Decider) []peer.PeerDescriptor {
+	fmt.Printf("Raid: %s - %s\n", raid.Name, raid.Description)
+	joiners, spectators, seed := acceptTrainers(self, 2)
+
+	// the raid decides the boss and the turn limit; the rest is up to the host
+	ruleset := game.Host_setRules()
+	if ruleset.Doubles {
+		fmt.Println("Doubles is not available in a raid; playing singles.")
+	}
+	raid.Apply(&ruleset)
+
+	cmode := game.Host_setCMode(self, joiners, ruleset, spectators)
+	game.Host_sendRoster(self, joiners)
+
+	p := game.PlayerSetUp(self, ruleset)
+	players := game.Host_FreeForAllSetup(p, joiners, cmode, ruleset, spectators)
+	game.RunRaid(players, 0, seed, cmode, ruleset, raid.Script, spectators, decider)
+	return spectators
+}
+
+// acceptTrainers accepts joiners until there are the given number of
+// trainers, host included. Every joiner gets the same seed. Returns the
+// joiners in the order they were accepted, the spectators and the seed.
+func acceptTrainers(self peer.PeerDescriptor, trainers int) ([]peer.PeerDescriptor, []peer.PeerDescriptor, int) {
+	var joiners, spectators []peer.PeerDescriptor
+	seed := 0
+	for len(joiners) < trainers-1 {
+		fmt.Printf("Trainers: %d/%d\n", len(joiners)+1, trainers)
+		joiner, watching := waitForMatch(self)
+		spectators = append(spectators, watching...)
+		if slices.ContainsFunc(joiners, func(j peer.PeerDescriptor) bool { return j.Addr.String() == joiner.Addr.String() }) {
+			continue // A repeated request from a trainer already in
+		}
+		if len(joiners) == 0 {
+			seed = handshake(self, joiner)
+		} else {
+			sendHandshake(self, joiner, messages.MakeHandshakeResponseWithSeed(seed))
+		}
+		joiners = append(joiners, joiner)
+	}
+	return joiners, spectators, seed
+}
+
+// findRaid loads the raids in game.RaidsDir and returns the one with the
+// given name, ignoring case.
+func findRaid(name string) (rules.Raid, error) {
+	raids, err := rules.LoadRaids(game.RaidsDir)
+	if err != nil {
+		return rules.Raid{}, err
+	}
+
+	var names []string
+	for _, raid := range raids {
+		if strings.EqualFold(raid.Name, name) {
+			return raid, game.CheckRaid(raid)
+		}
+		names = append(names, raid.Name)
+	}
+	return rules.Raid{}, fmt.Errorf("no raid named %q (available: %s)", name, strings.Join(names, ", "))
+}
+
 // main is the entry point for the host application.
 // It initializes the host, waits for a joiner, performs handshake, and starts the battle.
 func main() {
 	// Parse command-line flags
 	verboseFlag := flag.Bool("verbose", false, "Enable verbose logging of network events")
+	ffaFlag := flag.Int("ffa", 0, fmt.Sprintf("Host a free-for-all for this many trainers (3-%d)", rules.MaxPlayers))
+	raidFlag := flag.String("raid", "", "Host a co-op raid for two trainers against the named boss in data/raids")
+	externalFlag := flag.String("external", "", "Let a program battle for you, speaking line-delimited JSON on stdin/stdout (e.g. \"python3 mybot.py\")")
 	flag.Parse()
 
 	// Set global verbose mode
 	netio.Verbose = *verboseFlag
 
+	if *ffaFlag != 0 && (*ffaFlag < 3 || *ffaFlag > rules.MaxPlayers) {
+		netio.ERLine(fmt.Sprintf("A free-for-all needs 3 to %d trainers.", rules.MaxPlayers), true)
+	}
+	var raid rules.Raid
+	if *raidFlag != "" {
+		if *ffaFlag != 0 {
+			netio.ERLine("Choose either -ffa or -raid, not both.", true)
+		}
+		var err error
+		if raid, err = findRaid(*raidFlag); err != nil {
+			netio.ERLine(fmt.Sprintf("Could not load raid: %v", err), true)
+		}
+	}
+
+	// The external bot battles for the host; nil leaves it to the terminal
+	var decider game.Decider
+	if *externalFlag != "" {
+		external, err := game.StartExternalBot(*externalFlag)
+		if err != nil {
+			netio.ERLine(fmt.Sprintf("Could not start the external bot: %v", err), true)
+		}
+		defer external.Close()
+		decider = external
+	}
+
 	self := peer.MakePDFromLogin("hostW")
 	defer self.Conn.Close()
 
@@ -138,35 +260,56 @@ func main() {
 		fmt.Println("\n=== HOSTING NEW BATTLE ===")
 		fmt.Println("Waiting for players to join...")
 
+		if *raidFlag != "" {
+			spectators := hostRaid(self, raid, decider)
+			finishBattle(spectators)
+			continue
+		}
+
+		if *ffaFlag > 0 {
+			spectators := hostFreeForAll(self, *ffaFlag, decider)
+			finishBattle(spectators)
+			continue
+		}
+
 		// at the start say that somebody can join you
 		joiner, spectators := waitForMatch(self)
 
 		// when watchForMatch returns, initialize a handshake
 		seed := handshake(self, joiner)
 
+		// choose the battle rules
+		ruleset := game.Host_setRules()
+
 		// set the communication for a battle
-		cmode := game.Host_setCMode(self, joiner)
+		cmode := game.Host_setCMode(self, []peer.PeerDescriptor{joiner}, ruleset, spectators)
 
 		// create Host's player
-		p := game.PlayerSetUp(self)
+		p := game.PlayerSetUp(self, ruleset)
 
 		// make BattleSetup and get opponent player info
-		opponentPlayer := game.BattleSetup(p, joiner, cmode, spectators)
+		opponentPlayer := game.BattleSetup(p, joiner, cmode, ruleset, spectators)
 
 		// Start the battle with spectators
-		game.RunBattle(&p, &opponentPlayer, seed, cmode, true, spectators)
+		game.RunBattle(&p, &opponentPlayer, seed, cmode, ruleset, true, spectators, decider)
 
-		// Battle ended, clear spectators and return to main menu
-		fmt.Println("\n=== BATTLE COMPLETED ===")
+		finishBattle(spectators)
+	}
+}
 
-		// Notify spectators that battle has ended and they should look for new battles
-		if len(spectators) > 0 {
-			fmt.Printf("Disconnecting %d spectator(s)...\n", len(spectators))
-			// Give spectators time to process GAME_OVER message
-			time.Sleep(1 * time.Second)
-		}
+// finishBattle lets spectators see the end of a battle before the host
+// returns to its menu.
+func finishBattle(spectators []peer.PeerDescriptor) {
+	// Battle ended, clear spectators and return to main menu
+	fmt.Println("\n=== BATTLE COMPLETED ===")
 
-		fmt.Println("Returning to host menu...")
-		time.Sleep(2 * time.Second)
+	// Notify spectators that battle has ended and they should look for new battles
+	if len(spectators) > 0 {
+		fmt.Printf("Disconnecting %d spectator(s)...\n", len(spectators))
+		// Give spectators time to process GAME_OVER message
+		time.Sleep(1 * time.Second)
 	}
+
+	fmt.Println("Returning to host menu...")
+	time.Sleep(2 * time.Second)
 }
@@ -0,0 +1,46 @@
+// Package main implements the Pokemon Battler hot-seat application.
+// Two trainers share one terminal and battle in one process, with no
+// networking.
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game"
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/peer"
+)
+
+// main asks for both trainers' names, lets the first choose the rules, then
+// hands the terminal to each trainer in turn to pick a Pokemon and battle
+// until they stop.
+func main() {
+	fmt.Println("Welcome to PokeBattler hot-seat")
+	first := peer.MakePD(netio.PRLine("First trainer, what is your name?"), nil, nil)
+	var second peer.PeerDescriptor
+	for {
+		second = peer.MakePD(netio.PRLine("Second trainer, what is your name?"), nil, nil)
+		if !strings.EqualFold(second.Name, first.Name) {
+			break
+		}
+		netio.ERLine("Both trainers need different names", false)
+	}
+
+	for {
+		ruleset := game.Local_setRules()
+
+		// Each trainer builds their team out of the other's sight
+		game.PassTerminal(first.Name)
+		host := game.PlayerSetUp(first, ruleset)
+		game.PassTerminal(second.Name)
+		joiner := game.PlayerSetUp(second, ruleset)
+
+		game.RunHotSeatBattle(&host, &joiner, rand.Intn(999), ruleset)
+
+		if strings.ToLower(netio.PRLine("Battle again? [y / N:default]")) != "y" {
+			return
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/zrygan/pokemonbattler/game"
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
 	"github.com/zrygan/pokemonbattler/messages"
 	"github.com/zrygan/pokemonbattler/netio"
 	"github.com/zrygan/pokemonbattler/peer"
@@ -173,16 +175,56 @@ func handshake(self peer.PeerDescriptor, host peer.PeerDescriptor) int {
 	}
 }
 
+// setUpPlayer builds the joiner's player: interactively, or by the bot
+// when one plays for the joiner.
+func setUpPlayer(self peer.PeerDescriptor, ruleset rules.Ruleset, bot *game.Bot) player.Player {
+	if bot == nil {
+		return game.PlayerSetUp(self, ruleset)
+	}
+	p, err := bot.NewBotPlayer(self, ruleset)
+	if err != nil {
+		netio.ERLine(fmt.Sprintf("The bot could not build a team: %v", err), true)
+	}
+	fmt.Printf("The %s bot chose %s Lv. %d\n", bot.Difficulty, p.PokemonStruct.Name, p.PokemonStruct.Level)
+	return p
+}
+
 // main is the entry point for the joiner application.
 // It discovers hosts, allows user selection, and initiates the handshake process.
 func main() {
 	// Parse command-line flags
 	verboseFlag := flag.Bool("verbose", false, "Enable verbose logging of network events")
+	botFlag := flag.String("bot", "", fmt.Sprintf("Let a bot battle for you (%s)", strings.Join(game.BotDifficulties, ", ")))
+	budgetFlag := flag.Duration("budget", game.DefaultSearchBudget, "Thinking time per move for the expert bot")
+	externalFlag := flag.String("external", "", "Let a program battle for you, speaking line-delimited JSON on stdin/stdout (e.g. \"python3 mybot.py\")")
 	flag.Parse()
 
 	// Set global verbose mode
 	netio.Verbose = *verboseFlag
 
+	if *botFlag != "" && *externalFlag != "" {
+		netio.ERLine("Choose either -bot or -external, not both.", true)
+	}
+
+	// The decider battles for the joiner; nil leaves it to the terminal
+	var bot *game.Bot
+	var decider game.Decider
+	if *botFlag != "" {
+		var err error
+		if bot, err = game.NewBot(*botFlag, time.Now().UnixNano(), *budgetFlag); err != nil {
+			netio.ERLine(fmt.Sprintf("Could not start the bot: %v", err), true)
+		}
+		decider = bot
+	}
+	if *externalFlag != "" {
+		external, err := game.StartExternalBot(*externalFlag)
+		if err != nil {
+			netio.ERLine(fmt.Sprintf("Could not start the external bot: %v", err), true)
+		}
+		defer external.Close()
+		decider = external
+	}
+
 	self := peer.MakePDFromLogin("joiner")
 	defer self.Conn.Close()
 
@@ -218,17 +260,34 @@ func main() {
 			break
 		}
 
-		// get the communication mode from the host
-		cmode := game.Joiner_getCMode(self)
+		// get the communication mode and ruleset from the host
+		cmode, ruleset := game.Joiner_getCMode(self)
+
+		// a free-for-all or raid goes through the host, which assigns the seats
+		if ruleset.HostOrdered() {
+			seat, trainers := game.Joiner_getRoster(self)
+			p := setUpPlayer(self, ruleset, bot)
+			players := game.Joiner_FreeForAllSetup(p, *host, seat, trainers, cmode, ruleset)
+			if ruleset.Raid() {
+				game.RunRaid(players, seat, seed, cmode, ruleset, nil, []peer.PeerDescriptor{}, decider)
+			} else {
+				game.RunFreeForAll(players, seat, seed, cmode, ruleset, []peer.PeerDescriptor{}, decider)
+			}
+
+			fmt.Println("\n=== BATTLE COMPLETED ===")
+			fmt.Println("Returning to joiner menu...")
+			time.Sleep(2 * time.Second)
+			continue
+		}
 
 		// create joiner's player
-		p := game.PlayerSetUp(self)
+		p := setUpPlayer(self, ruleset, bot)
 
 		// exchange BattleSetup and get opponent player info
-		opponentPlayer := game.BattleSetup(p, *host, cmode, []peer.PeerDescriptor{})
+		opponentPlayer := game.BattleSetup(p, *host, cmode, ruleset, []peer.PeerDescriptor{})
 
 		// Start the battle (joiner has no spectators)
-		game.RunBattle(&p, &opponentPlayer, seed, cmode, false, []peer.PeerDescriptor{})
+		game.RunBattle(&p, &opponentPlayer, seed, cmode, ruleset, false, []peer.PeerDescriptor{}, decider)
 
 		// Battle ended, return to main menu
 		fmt.Println("\n=== BATTLE COMPLETED ===")
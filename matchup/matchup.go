@@ -0,0 +1,99 @@
+// Package main implements the team matchup evaluator.
+// It searches a duel between two teams from the opening and reports which
+// team is expected to come out ahead, with each team moving first.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game"
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/peer"
+)
+
+// main builds the teams named by the flags and prints the search's verdict.
+func main() {
+	aFlag := flag.String("a", "", "Team A as comma-separated species, lead first")
+	bFlag := flag.String("b", "", "Team B as comma-separated species, lead first")
+	formatFlag := flag.String("format", "", "Format in data/formats to play under (default rules if empty)")
+	budgetFlag := flag.Duration("budget", 2*time.Second, "Thinking time for each turn order")
+	seedFlag := flag.Int64("seed", 1, "Seed for the search's damage rolls")
+	flag.Parse()
+
+	if *aFlag == "" || *bFlag == "" {
+		netio.ERLine("Name both teams with -a and -b.", true)
+	}
+	r := rules.Default()
+	if *formatFlag != "" {
+		var err error
+		if r, err = game.FindFormat(*formatFlag); err != nil {
+			netio.ERLine(fmt.Sprintf("Could not load format: %v", err), true)
+		}
+	}
+	r = game.LocalRules(r)
+
+	a, err := game.NewTeam(peer.MakePD("A", nil, nil), r, splitTeam(*aFlag))
+	if err != nil {
+		netio.ERLine(fmt.Sprintf("Team A: %v", err), true)
+	}
+	b, err := game.NewTeam(peer.MakePD("B", nil, nil), r, splitTeam(*bFlag))
+	if err != nil {
+		netio.ERLine(fmt.Sprintf("Team B: %v", err), true)
+	}
+
+	fmt.Printf("Format: %s\n", r.DescribeFormat())
+	fmt.Printf("Team A: %s\n", describeTeam(&a))
+	fmt.Printf("Team B: %s\n", describeTeam(&b))
+
+	result := game.EvaluateMatchup(&a, &b, r, *budgetFlag, *seedFlag)
+	showOrder("A moves first", result.AFirst, "A", &a)
+	showOrder("B moves first", result.BFirst, "B", &b)
+	fmt.Printf("\nExpected score for A: %+.1f (%s)\n", result.Score(), verdict(result.Score()))
+}
+
+// splitTeam splits a comma-separated list of species.
+func splitTeam(list string) []string {
+	var species []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			species = append(species, name)
+		}
+	}
+	return species
+}
+
+// describeTeam lists a team's Pokemon with their levels, lead first.
+func describeTeam(p *player.Player) string {
+	names := []string{fmt.Sprintf("%s Lv. %d", p.PokemonStruct.Name, p.PokemonStruct.Level)}
+	for _, mon := range p.Reserves {
+		names = append(names, fmt.Sprintf("%s Lv. %d", mon.Name, mon.Level))
+	}
+	return strings.Join(names, ", ")
+}
+
+// showOrder prints the search's result for one turn order.
+func showOrder(label string, result game.SearchResult, opener string, team *player.Player) {
+	fmt.Printf("\n%s:\n", label)
+	fmt.Printf("  Best opening for %s: %s\n", opener, result.Action.Describe(team.PokemonStruct.Name))
+	fmt.Printf("  Score for A: %+.1f, searched %d actions deep over %d positions\n", result.Score, result.Depth, result.Nodes)
+}
+
+// verdict puts a score for A into words.
+func verdict(score float64) string {
+	switch {
+	case score >= 1000:
+		return "A wins"
+	case score <= -1000:
+		return "B wins"
+	case score > 0:
+		return "A favored"
+	case score < 0:
+		return "B favored"
+	}
+	return "even"
+}
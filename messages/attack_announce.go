@@ -1,10 +1,102 @@
 package messages
 
+// Turn action types carried in the "action" field of ATTACK_ANNOUNCE.
+// Messages without the field are attacks.
+const (
+	ActionAttack  = "attack"
+	ActionProtect = "protect"
+	ActionItem    = "item"
+	ActionSwitch  = "switch"
+	ActionForfeit = "forfeit" // Free-for-all only; a duel forfeit is sent as GAME_OVER
+)
+
+// Doubles targets carried in the "target" field of ATTACK_ANNOUNCE.
+const (
+	TargetFoe    = "foe"    // One opposing Pokemon, chosen by target_slot
+	TargetAlly   = "ally"   // The user's partner
+	TargetSpread = "spread" // Both opposing Pokemon, at reduced damage
+)
+
 // MakeAttackAnnounce creates an attack announcement message.
-// This message is sent by the attacking player to announce their move choice.
-func MakeAttackAnnounce(moveName string, sequenceNumber int) Message {
+// This message is sent by the attacking player to announce their move choice
+// and whether it spends an attack boost for the move's category.
+func MakeAttackAnnounce(moveName string, attackBoost bool, sequenceNumber int) Message {
 	params := map[string]any{
+		"action":          ActionAttack,
 		"move_name":       moveName,
+		"attack_boost":    attackBoost,
+		"sequence_number": sequenceNumber,
+	}
+
+	return Message{
+		MessageType:   AttackAnnounce,
+		MessageParams: &params,
+	}
+}
+
+// AddTimeUsed adds the milliseconds the attacker spent choosing to an
+// announcement, so the waiting peer can charge the same time to its clock.
+func AddTimeUsed(msg Message, milliseconds int) {
+	(*msg.MessageParams)["time_used"] = milliseconds
+}
+
+// AddTarget adds the doubles slot of the acting Pokemon and its target to an
+// announcement. targetSlot is only used with TargetFoe.
+func AddTarget(msg Message, userSlot int, target string, targetSlot int) {
+	params := *msg.MessageParams
+	params["user_slot"] = userSlot
+	params["target"] = target
+	params["target_slot"] = targetSlot
+}
+
+// MakeItemAnnounce creates an attack announcement for a turn spent using a bag
+// item. It carries item_name instead of move_name.
+func MakeItemAnnounce(itemName string, sequenceNumber int) Message {
+	params := map[string]any{
+		"action":          ActionItem,
+		"item_name":       itemName,
+		"sequence_number": sequenceNumber,
+	}
+
+	return Message{
+		MessageType:   AttackAnnounce,
+		MessageParams: &params,
+	}
+}
+
+// MakeProtectAnnounce creates an attack announcement for a turn spent protecting.
+func MakeProtectAnnounce(sequenceNumber int) Message {
+	params := map[string]any{
+		"action":          ActionProtect,
+		"sequence_number": sequenceNumber,
+	}
+
+	return Message{
+		MessageType:   AttackAnnounce,
+		MessageParams: &params,
+	}
+}
+
+// MakeSwitchAnnounce creates an attack announcement for a turn spent switching
+// to the reserve in switchTo (0-based, in BATTLE_SETUP order).
+func MakeSwitchAnnounce(switchTo int, sequenceNumber int) Message {
+	params := map[string]any{
+		"action":          ActionSwitch,
+		"switch_to":       switchTo,
+		"sequence_number": sequenceNumber,
+	}
+
+	return Message{
+		MessageType:   AttackAnnounce,
+		MessageParams: &params,
+	}
+}
+
+// MakeForfeitAnnounce creates an attack announcement for a free-for-all
+// trainer giving up. The other trainers battle on.
+func MakeForfeitAnnounce(sequenceNumber int) Message {
+	params := map[string]any{
+		"action":          ActionForfeit,
 		"sequence_number": sequenceNumber,
 	}
 
@@ -1,25 +1,85 @@
 package messages
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/poke"
 )
 
 // MakeBattleSetup creates a battle setup message with game configuration.
+// Level, IVs, EVs and nature let the receiver compute the same battle stats.
+// The held item and bag are declared here so the receiver can validate them.
 func MakeBattleSetup(
 	p player.Player,
 	cmode string, // ensure, only "P" or "B"
 	pokeName string,
 	atk int8,
 	def int8,
+	physAtk int8,
+	physDef int8,
+	level int,
+	ivs poke.StatSet,
+	evs poke.StatSet,
+	nature string,
+	friendship int,
+	heldItem string,
+	bag poke.Bag,
+	reserves []poke.Pokemon,
 ) Message {
 	params := map[string]any{
-		"communication_mode":   cmode,
-		"pokemon_name":         pokeName,
-		"special_attack_uses":  int(atk),
-		"special_defense_uses": int(def),
+		"communication_mode":    cmode,
+		"pokemon_name":          pokeName,
+		"special_attack_uses":   int(atk),
+		"special_defense_uses":  int(def),
+		"physical_attack_uses":  int(physAtk),
+		"physical_defense_uses": int(physDef),
+		"level":                 level,
+		"ivs":                   ivs.String(),
+		"evs":                   evs.String(),
+		"nature":                nature,
+		"friendship":            friendship,
+	}
+	if heldItem != "" {
+		params["held_item"] = heldItem
+	}
+	if bag.Count() > 0 {
+		params["bag"] = bag.String()
+	}
+
+	// Reserves use the same keys as the active Pokemon behind a "reserve_<n>_" prefix
+	params["reserves"] = len(reserves)
+	for i, mon := range reserves {
+		prefix := reservePrefix(i)
+		params[prefix+"pokemon_name"] = mon.Name
+		params[prefix+"level"] = mon.Level
+		params[prefix+"ivs"] = mon.IVs.String()
+		params[prefix+"evs"] = mon.EVs.String()
+		params[prefix+"nature"] = mon.Nature.Name
+		if mon.HeldItem != "" {
+			params[prefix+"held_item"] = mon.HeldItem
+		}
 	}
 	return Message{
 		MessageType:   BattleSetup,
 		MessageParams: &params,
 	}
 }
+
+// ReserveParams extracts the parameters of the reserve at index i from a
+// BATTLE_SETUP message, with the "reserve_<n>_" prefix removed.
+func ReserveParams(params map[string]any, i int) map[string]any {
+	prefix := reservePrefix(i)
+	reserve := map[string]any{}
+	for key, value := range params {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			reserve[name] = value
+		}
+	}
+	return reserve
+}
+
+func reservePrefix(i int) string {
+	return "reserve_" + strconv.Itoa(i+1) + "_"
+}
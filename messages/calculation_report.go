@@ -1,7 +1,14 @@
 package messages
 
+import (
+	"strconv"
+	"strings"
+)
+
 // MakeCalculationReport creates a calculation report message.
 // This message is sent by both players to report the results of their independent damage calculation.
+// remainingHealth and defenderHPEnd are the HP values after end-of-turn field effects,
+// while defenderHPRemaining is the HP right after the attack.
 func MakeCalculationReport(
 	attacker string,
 	moveUsed string,
@@ -9,6 +16,9 @@ func MakeCalculationReport(
 	damageDealt int,
 	defenderHPRemaining int,
 	statusMessage string,
+	defenderHPEnd int,
+	field string,
+	fieldEvents string,
 	sequenceNumber int,
 ) Message {
 	params := map[string]any{
@@ -18,6 +28,9 @@ func MakeCalculationReport(
 		"damage_dealt":          damageDealt,
 		"defender_hp_remaining": defenderHPRemaining,
 		"status_message":        statusMessage,
+		"defender_hp_end":       defenderHPEnd,
+		"field":                 field,
+		"field_events":          fieldEvents,
 		"sequence_number":       sequenceNumber,
 	}
 
@@ -26,3 +39,74 @@ func MakeCalculationReport(
 		MessageParams: &params,
 	}
 }
+
+// AddActiveState adds both sides' active Pokemon and HP to a report, so
+// spectators can follow switches and replacements after a faint.
+func AddActiveState(
+	msg Message,
+	hostPokemon string,
+	hostHP int,
+	hostMaxHP int,
+	joinerPokemon string,
+	joinerHP int,
+	joinerMaxHP int,
+) {
+	params := *msg.MessageParams
+	params["host_pokemon"] = hostPokemon
+	params["host_hp"] = hostHP
+	params["host_max_hp"] = hostMaxHP
+	params["joiner_pokemon"] = joinerPokemon
+	params["joiner_hp"] = joinerHP
+	params["joiner_max_hp"] = joinerMaxHP
+}
+
+// AddEffectBreakdown adds the per-hit damage, recoil, held item recoil,
+// drain, priority and scripted effects of an attack to a report, so the
+// defender can verify each effect and spectators can narrate it. Hits are
+// sent comma-separated, e.g. "23,21,25", and scripted effects likewise, e.g.
+// "target:speed:-1".
+func AddEffectBreakdown(msg Message, hits []int, recoil int, itemRecoil int, drained int, priority int, scripted []string) {
+	parts := make([]string, len(hits))
+	for i, hit := range hits {
+		parts[i] = strconv.Itoa(hit)
+	}
+
+	params := *msg.MessageParams
+	params["hit_count"] = len(hits)
+	params["hit_damage"] = strings.Join(parts, ",")
+	params["recoil"] = recoil
+	params["item_recoil"] = itemRecoil
+	params["drained"] = drained
+	params["priority"] = priority
+	params["scripted_effects"] = strings.Join(scripted, ",")
+}
+
+// AddDoublesState adds the doubles target and both partners to a report.
+// spreadDamage lists a spread move's damage per foe, comma-separated like
+// hit_damage, and is empty for single-target moves.
+func AddDoublesState(
+	msg Message,
+	target string,
+	spreadDamage []int,
+	hostPartner string,
+	hostPartnerHP int,
+	hostPartnerMaxHP int,
+	joinerPartner string,
+	joinerPartnerHP int,
+	joinerPartnerMaxHP int,
+) {
+	parts := make([]string, len(spreadDamage))
+	for i, damage := range spreadDamage {
+		parts[i] = strconv.Itoa(damage)
+	}
+
+	params := *msg.MessageParams
+	params["target"] = target
+	params["spread_damage"] = strings.Join(parts, ",")
+	params["host_partner"] = hostPartner
+	params["host_partner_hp"] = hostPartnerHP
+	params["host_partner_max_hp"] = hostPartnerMaxHP
+	params["joiner_partner"] = joinerPartner
+	params["joiner_partner_hp"] = joinerPartnerHP
+	params["joiner_partner_max_hp"] = joinerPartnerMaxHP
+}
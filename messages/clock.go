@@ -0,0 +1,18 @@
+package messages
+
+// MakeClock creates a clock update for spectators. turn is "host" or
+// "joiner"; the times are whole seconds. hostClock and joinerClock are -1
+// when the match has no game clock.
+func MakeClock(turn string, turnLeft int, hostClock int, joinerClock int) Message {
+	params := map[string]any{
+		"turn":         turn,
+		"turn_left":    turnLeft,
+		"host_clock":   hostClock,
+		"joiner_clock": joinerClock,
+	}
+
+	return Message{
+		MessageType:   Clock,
+		MessageParams: &params,
+	}
+}
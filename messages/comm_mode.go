@@ -3,9 +3,13 @@ package messages
 // GS_MakeCMode creates a communication mode set up.
 // The communication mode here are one of the game.CommunicationModeEnum.
 // This is only used by a HOST user, and only HOST users can set the mode.
-func GS_MakeCMode(mode string) Message {
+// movesHash and chartHash identify the host's custom moves and type chart,
+// so a joiner with other data can refuse to battle.
+func GS_MakeCMode(mode string, movesHash string, chartHash string) Message {
 	params := map[string]any{
-		"cmode": mode,
+		"cmode":           mode,
+		"moves_hash":      movesHash,
+		"type_chart_hash": chartHash,
 	}
 
 	return Message{
@@ -2,8 +2,10 @@ package messages
 
 // MakeDefenseAnnounce creates a defense announcement message.
 // This message is sent by the defending player to acknowledge the opponent's attack.
-func MakeDefenseAnnounce(sequenceNumber int) Message {
+// It carries the defender's choice to spend a defense boost for the move's category.
+func MakeDefenseAnnounce(defenseBoost bool, sequenceNumber int) Message {
 	params := map[string]any{
+		"defense_boost":   defenseBoost,
 		"sequence_number": sequenceNumber,
 	}
 
@@ -0,0 +1,56 @@
+package messages
+
+import "strings"
+
+// MakeRoster creates the FFA_ROSTER message the host sends each joiner of a
+// free-for-all. Trainers are listed comma-separated by seat, the host in
+// seat 0; seat is the receiving joiner's own seat.
+func MakeRoster(trainers []string, seat int) Message {
+	params := map[string]any{
+		"trainers": strings.Join(trainers, ","),
+		"seat":     seat,
+	}
+
+	return Message{
+		MessageType:   FFARoster,
+		MessageParams: &params,
+	}
+}
+
+// MakeFFATurn creates the FFA_TURN message with which the host hands the
+// next action of a free-for-all to a seat.
+func MakeFFATurn(seat int, turn int, sequenceNumber int) Message {
+	params := map[string]any{
+		"seat":            seat,
+		"turn":            turn,
+		"sequence_number": sequenceNumber,
+	}
+
+	return Message{
+		MessageType:   FFATurn,
+		MessageParams: &params,
+	}
+}
+
+// AddSeats adds the acting seat and its target seat to a free-for-all
+// ATTACK_ANNOUNCE or CALCULATION_REPORT.
+func AddSeats(msg Message, seat int, targetSeat int) {
+	params := *msg.MessageParams
+	params["seat"] = seat
+	params["target_seat"] = targetSeat
+}
+
+// AddStandings adds every trainer's active Pokemon and HP to a free-for-all
+// CALCULATION_REPORT, e.g. "Ash: Pikachu 35/35; Misty: Starmie out".
+func AddStandings(msg Message, standings string) {
+	(*msg.MessageParams)["standings"] = standings
+}
+
+// MakePlacementGameOver creates the game over message that ends a
+// free-for-all. placements lists the trainers from first to last; the first
+// is the winner and the last the loser.
+func MakePlacementGameOver(placements []string, reason string, sequenceNumber int) Message {
+	msg := MakeGameOver(placements[0], placements[len(placements)-1], reason, sequenceNumber)
+	(*msg.MessageParams)["placements"] = strings.Join(placements, ",")
+	return msg
+}
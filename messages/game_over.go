@@ -1,11 +1,33 @@
 package messages
 
+// Reasons a battle ended, carried in GAME_OVER.
+const (
+	ReasonFainted   = "fainted"    // The loser has no Pokemon left that can battle
+	ReasonForfeit   = "forfeit"    // The loser gave up
+	ReasonTimeout   = "timeout"    // The loser ran out of time
+	ReasonTurnLimit = "turn_limit" // The turn limit was reached; see "tiebreak"
+)
+
+// Results carried in GAME_OVER. A draw has empty winner and loser fields.
+const (
+	ResultWin  = "WIN"
+	ResultDraw = "DRAW"
+)
+
+// Tiebreakers that decide a battle at the turn limit, in order.
+const (
+	TiebreakHP     = "hp_percent"   // Higher share of the team's HP left
+	TiebreakDamage = "damage_dealt" // More total damage dealt
+)
+
 // MakeGameOver creates a game over message.
-// This message is sent when a Pokemon faints to declare the battle winner.
-func MakeGameOver(winner string, loser string, sequenceNumber int) Message {
+// This message is sent when a Pokemon faints or a trainer forfeits to declare the battle winner.
+func MakeGameOver(winner string, loser string, reason string, sequenceNumber int) Message {
 	params := map[string]any{
+		"result":          ResultWin,
 		"winner":          winner,
 		"loser":           loser,
+		"reason":          reason,
 		"sequence_number": sequenceNumber,
 	}
 
@@ -14,3 +36,24 @@ func MakeGameOver(winner string, loser string, sequenceNumber int) Message {
 		MessageParams: &params,
 	}
 }
+
+// MakeDrawGameOver creates a game over message for a drawn battle.
+func MakeDrawGameOver(reason string, sequenceNumber int) Message {
+	params := map[string]any{
+		"result":          ResultDraw,
+		"winner":          "",
+		"loser":           "",
+		"reason":          reason,
+		"sequence_number": sequenceNumber,
+	}
+
+	return Message{
+		MessageType:   GameOver,
+		MessageParams: &params,
+	}
+}
+
+// AddTiebreak records which tiebreaker decided a battle at the turn limit.
+func AddTiebreak(msg Message, tiebreak string) {
+	(*msg.MessageParams)["tiebreak"] = tiebreak
+}
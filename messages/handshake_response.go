@@ -7,8 +7,14 @@ import (
 // MakeHandshakeResponse creates a handshake response message with a random seed.
 // The seed is used to synchronize random number generation between host and joiner.
 func MakeHandshakeResponse() Message {
+	return MakeHandshakeResponseWithSeed(rand.Intn(999))
+}
+
+// MakeHandshakeResponseWithSeed creates a handshake response carrying a given
+// seed, so every joiner of a free-for-all shares one RNG.
+func MakeHandshakeResponseWithSeed(seed int) Message {
 	params := map[string]any{
-		"seed": rand.Intn(999),
+		"seed": seed,
 	}
 
 	return Message{
@@ -0,0 +1,23 @@
+package messages
+
+// AddBossState adds the raid boss's shared HP to a raid CALCULATION_REPORT,
+// so trainers and spectators can show the boss's health bar.
+func AddBossState(msg Message, boss string, hp int, maxHP int) {
+	params := *msg.MessageParams
+	params["boss"] = boss
+	params["boss_hp"] = hp
+	params["boss_max_hp"] = maxHP
+}
+
+// MakeRaidGameOver creates the game over message that ends a raid. The
+// trainers win together when they clear it, e.g. winner "Ash & Misty"; the
+// boss wins otherwise. "raid_cleared" carries the outcome for both sides.
+func MakeRaidGameOver(trainers string, boss string, cleared bool, reason string, sequenceNumber int) Message {
+	winner, loser := trainers, boss
+	if !cleared {
+		winner, loser = boss, trainers
+	}
+	msg := MakeGameOver(winner, loser, reason, sequenceNumber)
+	(*msg.MessageParams)["raid_cleared"] = cleared
+	return msg
+}
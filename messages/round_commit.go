@@ -0,0 +1,17 @@
+package messages
+
+// MakeRoundCommit creates a round commitment message. As a duel round
+// opens, both players choose their action for it and send its priority
+// bracket, but not the action itself; the higher bracket acts first, and
+// the action announced later must be in the committed bracket.
+func MakeRoundCommit(priority int, sequenceNumber int) Message {
+	params := map[string]any{
+		"priority":        priority,
+		"sequence_number": sequenceNumber,
+	}
+
+	return Message{
+		MessageType:   RoundCommit,
+		MessageParams: &params,
+	}
+}
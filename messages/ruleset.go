@@ -0,0 +1,53 @@
+package messages
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zrygan/pokemonbattler/game/rules"
+)
+
+// GS_MakeRuleset creates the RULESET message the host sends after COMM_MODE,
+// so both peers and any spectators battle under the same rules.
+// Generations are sent comma-separated, e.g. "1,2,3".
+// This is only used by a HOST user, and only HOST users can set the rules.
+func GS_MakeRuleset(r rules.Ruleset) Message {
+	generations := make([]string, len(r.Generations))
+	for i, generation := range r.Generations {
+		generations[i] = strconv.Itoa(generation)
+	}
+
+	params := map[string]any{
+		"format":                 r.Format,
+		"level_cap":              r.LevelCap,
+		"cosmetic_personalities": r.CosmeticPersonalities,
+		"friendship_effects":     r.FriendshipEffects,
+		"held_items":             r.HeldItems,
+		"bag_limit":              r.BagLimit,
+		"party_size":             r.PartySize,
+		"boost_special":          r.BoostSpecial,
+		"boost_physical":         r.BoostPhysical,
+		"boost_multiplier":       strconv.FormatFloat(r.BoostMultiplier, 'f', -1, 64),
+		"boost_budget":           r.BoostBudget,
+		"damage_model":           r.DamageModel,
+		"inverse_battle":         r.InverseBattle,
+		"doubles":                r.Doubles,
+		"players":                r.Players,
+		"raid_boss":              r.RaidBoss,
+		"raid_level":             r.RaidLevel,
+		"raid_hp_multiplier":     r.RaidHPMultiplier,
+		"raid_actions":           r.RaidActions,
+		"ban_legendary":          r.BanLegendary,
+		"generations":            strings.Join(generations, ","),
+		"species_clause":         r.SpeciesClause,
+		"turn_limit":             r.TurnLimit,
+		"turn_timer":             r.TurnTimer,
+		"game_clock":             r.GameClock,
+		"timeout_action":         r.TimeoutAction,
+	}
+
+	return Message{
+		MessageType:   GS_RULESET,
+		MessageParams: &params,
+	}
+}
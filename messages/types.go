@@ -26,14 +26,21 @@ const (
 
 	// (stage) GameSetup message types
 	GS_COMMMODE = "COMM_MODE"
+	GS_RULESET  = "RULESET" // Host's ruleset, sent after COMM_MODE
 
 	// Battle turn message types
+	RoundCommit        = "ROUND_COMMIT"        // Player commits to a priority bracket as a duel round opens
 	AttackAnnounce     = "ATTACK_ANNOUNCE"     // Attacker announces chosen move
 	DefenseAnnounce    = "DEFENSE_ANNOUNCE"    // Defender acknowledges attack
 	CalculationReport  = "CALCULATION_REPORT"  // Player reports damage calculation
 	CalculationConfirm = "CALCULATION_CONFIRM" // Player confirms matching calculation
 	ResolutionRequest  = "RESOLUTION_REQUEST"  // Request to resolve calculation discrepancy
 	GameOver           = "GAME_OVER"           // Battle ends, declare winner
+	Clock              = "CLOCK"               // Host tells spectators the time left
+
+	// Free-for-all message types
+	FFARoster = "FFA_ROSTER" // Host tells a joiner the trainers and its seat
+	FFATurn   = "FFA_TURN"   // Host says which seat acts next
 
 	// Chat message types
 	ChatMessage = "CHAT_MESSAGE" // Chat or sticker message
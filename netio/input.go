@@ -15,6 +15,12 @@ func ShowMenu(texts ...string) {
 	}
 }
 
+// ClearScreen clears the terminal and its scrollback, so the next user of a
+// shared terminal cannot scroll back to what was shown before.
+func ClearScreen() {
+	fmt.Print("\033[H\033[2J\033[3J")
+}
+
 // PRLine (Print-Read Line) displays an instruction and reads user input.
 // Returns the trimmed input string.
 func PRLine(instruction string) string {
@@ -0,0 +1,330 @@
+package poke
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Effect targets.
+const (
+	EffectTargetUser   = "user"   // The Pokemon using the move
+	EffectTargetTarget = "target" // The opposing Pokemon (default)
+)
+
+// MaxStatStages is the largest stat change a single effect can make, and
+// the furthest a stat's stage can go either way.
+const MaxStatStages = 6
+
+// MoveEffect is one declarative effect of a move, read from a move data
+// file. An effect applies only when its condition holds and its chance roll
+// succeeds, and does exactly one thing: multiply the move's damage, change a
+// stat or inflict a status.
+type MoveEffect struct {
+	When             string  `json:"when,omitempty"`              // Condition, e.g. "target.status == poison"
+	Chance           int     `json:"chance,omitempty"`            // Percent chance to apply (0 means always)
+	Target           string  `json:"target,omitempty"`            // EffectTargetUser or EffectTargetTarget
+	DamageMultiplier float64 `json:"damage_multiplier,omitempty"` // Scales every hit of the move
+	Stat             Stat    `json:"stat,omitempty"`              // Stat to change, e.g. StatSpeed (not StatHP)
+	Stages           int     `json:"stages,omitempty"`            // Stat stages to add (-6 to 6)
+	Status           string  `json:"status,omitempty"`            // Status to inflict, e.g. StatusBurn
+
+	condition Condition // Parsed form of When
+}
+
+// OnUser reports whether the effect changes the move's user.
+func (e MoveEffect) OnUser() bool {
+	return e.Target == EffectTargetUser
+}
+
+// Holds reports whether the effect's condition is true in env.
+func (e MoveEffect) Holds(env EffectEnv) bool {
+	return e.condition.Holds(env)
+}
+
+// Describe summarizes the effect, e.g. "30% chance: burns the target".
+func (e MoveEffect) Describe() string {
+	who := "the target"
+	if e.OnUser() {
+		who = "the user"
+	}
+
+	var text string
+	switch {
+	case e.DamageMultiplier != 0:
+		text = fmt.Sprintf("x%.2g damage", e.DamageMultiplier)
+	case e.Stat != "" && e.Stages > 0:
+		text = fmt.Sprintf("raises %s's %s by %d", who, e.Stat, e.Stages)
+	case e.Stat != "":
+		text = fmt.Sprintf("lowers %s's %s by %d", who, e.Stat, -e.Stages)
+	default:
+		text = fmt.Sprintf("inflicts %s on %s", e.Status, who)
+	}
+	if e.When != "" {
+		text += " if " + e.When
+	}
+	if e.Chance > 0 && e.Chance < 100 {
+		text = fmt.Sprintf("%d%% chance: %s", e.Chance, text)
+	}
+	return text
+}
+
+// Validate checks the effect and parses its condition.
+func (e *MoveEffect) Validate() error {
+	condition, err := ParseCondition(e.When)
+	if err != nil {
+		return err
+	}
+	e.condition = condition
+
+	switch e.Target {
+	case "", EffectTargetTarget, EffectTargetUser:
+	default:
+		return fmt.Errorf("unknown effect target %q", e.Target)
+	}
+	if e.Chance < 0 || e.Chance > 100 {
+		return fmt.Errorf("chance %d is not between 0 and 100", e.Chance)
+	}
+	if e.DamageMultiplier < 0 {
+		return fmt.Errorf("damage multiplier %.2f is negative", e.DamageMultiplier)
+	}
+	kinds := 0
+	for _, set := range []bool{e.DamageMultiplier > 0, e.Stat != "", e.Status != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("an effect needs exactly one of damage_multiplier, stat or status")
+	}
+	if e.Stat != "" {
+		if !stageStats[e.Stat] {
+			return fmt.Errorf("unknown stat %q", e.Stat)
+		}
+		if e.Stages == 0 || e.Stages < -MaxStatStages || e.Stages > MaxStatStages {
+			return fmt.Errorf("stat stages must be between -%d and %d, not 0", MaxStatStages, MaxStatStages)
+		}
+	}
+	if e.Status != "" {
+		if StatusResidualFraction(e.Status) == 0 {
+			return fmt.Errorf("unknown status %q", e.Status)
+		}
+	}
+	return nil
+}
+
+// StageMultiplier returns the stat multiplier of a stage change:
+// +1 is x1.5, +2 is x2, -1 is x0.67 and so on.
+func StageMultiplier(stages int) float64 {
+	if stages >= 0 {
+		return float64(2+stages) / 2
+	}
+	return 2 / float64(2-stages)
+}
+
+// ChangeStat adds a stage change to one of the Pokemon's stats, keeping the
+// stage within MaxStatStages either way. Returns the change actually made,
+// 0 when the stage is already at its limit.
+func (p *Pokemon) ChangeStat(stat Stat, stages int) int {
+	if !stageStats[stat] {
+		return 0
+	}
+	current := p.Stages.Get(stat)
+	next := min(max(current+stages, -MaxStatStages), MaxStatStages)
+	p.Stages.Set(stat, next)
+	return next - current
+}
+
+// BattleStat returns one of the Pokemon's stats as its stage modifies it.
+// The stat itself is never changed. Stats never drop below 1.
+func (p *Pokemon) BattleStat(stat Stat) int {
+	value := p.Stats().Get(stat)
+	if stages := p.Stages.Get(stat); stages != 0 {
+		value = max(int(float64(value)*StageMultiplier(stages)), 1)
+	}
+	return value
+}
+
+// Stats returns the Pokemon's unmodified battle stats.
+func (p *Pokemon) Stats() StatSet {
+	return StatSet{
+		HP:             p.MaxHP,
+		Attack:         p.Attack,
+		Defense:        p.Defense,
+		SpecialAttack:  p.SpecialAttack,
+		SpecialDefense: p.SpecialDefense,
+		Speed:          p.Speed,
+	}
+}
+
+// stageStats are the stats effects can change.
+var stageStats = map[Stat]bool{
+	StatAttack:         true,
+	StatDefense:        true,
+	StatSpecialAttack:  true,
+	StatSpecialDefense: true,
+	StatSpeed:          true,
+}
+
+// EffectEnv is the battle state a condition is evaluated against.
+type EffectEnv struct {
+	User    *Pokemon
+	Target  *Pokemon
+	Weather string // "" when clear
+	Terrain string // "" when none
+}
+
+// Condition is a parsed effect condition: clauses joined by "and", each of
+// the form "<subject> <op> <value>". Subjects are "weather", "terrain", or
+// "user."/"target." followed by hp, hp_percent, status, type or item.
+// Numbers compare with == != < <= > >=, words with == and !=, and "none"
+// stands for no weather, terrain, status or item. The empty condition
+// always holds.
+type Condition struct {
+	clauses []clause
+}
+
+type clause struct {
+	subject string
+	op      string
+	value   string
+}
+
+// conditionOps lists the comparison operators, longest first so that "<="
+// is not read as "<".
+var conditionOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// numericSubjects are the properties compared as numbers.
+var numericSubjects = map[string]bool{"hp": true, "hp_percent": true}
+
+// ParseCondition parses a condition expression.
+func ParseCondition(expr string) (Condition, error) {
+	var condition Condition
+	if strings.TrimSpace(expr) == "" {
+		return condition, nil
+	}
+
+	for _, part := range strings.Split(expr, " and ") {
+		fields := strings.Fields(part)
+		if len(fields) != 3 {
+			return condition, fmt.Errorf("condition %q is not \"<subject> <op> <value>\"", strings.TrimSpace(part))
+		}
+		c := clause{subject: strings.ToLower(fields[0]), op: fields[1], value: strings.ToLower(fields[2])}
+
+		property := c.subject
+		if owner, prop, ok := strings.Cut(c.subject, "."); ok {
+			if owner != EffectTargetUser && owner != EffectTargetTarget {
+				return condition, fmt.Errorf("unknown subject %q", c.subject)
+			}
+			property = prop
+		}
+		switch property {
+		case "weather", "terrain":
+			if strings.Contains(c.subject, ".") {
+				return condition, fmt.Errorf("unknown subject %q", c.subject)
+			}
+		case "hp", "hp_percent", "status", "type", "item":
+			if !strings.Contains(c.subject, ".") {
+				return condition, fmt.Errorf("%q needs a user. or target. prefix", c.subject)
+			}
+		default:
+			return condition, fmt.Errorf("unknown subject %q", c.subject)
+		}
+
+		validOp := false
+		for _, op := range conditionOps {
+			validOp = validOp || c.op == op
+		}
+		if !validOp {
+			return condition, fmt.Errorf("unknown operator %q", c.op)
+		}
+		if numericSubjects[property] {
+			if _, err := strconv.Atoi(c.value); err != nil {
+				return condition, fmt.Errorf("%q must be compared with a number", c.subject)
+			}
+		} else if c.op != "==" && c.op != "!=" {
+			return condition, fmt.Errorf("%q can only be compared with == or !=", c.subject)
+		}
+
+		condition.clauses = append(condition.clauses, c)
+	}
+	return condition, nil
+}
+
+// Holds reports whether every clause of the condition is true in env.
+func (c Condition) Holds(env EffectEnv) bool {
+	for _, cl := range c.clauses {
+		if !cl.holds(env) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cl clause) holds(env EffectEnv) bool {
+	switch cl.subject {
+	case "weather":
+		return compareWords(env.Weather, cl.op, cl.value)
+	case "terrain":
+		return compareWords(env.Terrain, cl.op, cl.value)
+	}
+
+	owner, property, _ := strings.Cut(cl.subject, ".")
+	mon := env.Target
+	if owner == EffectTargetUser {
+		mon = env.User
+	}
+	if mon == nil {
+		return false
+	}
+
+	switch property {
+	case "hp":
+		return compareNumbers(mon.HP, cl.op, cl.value)
+	case "hp_percent":
+		percent := 0
+		if mon.MaxHP > 0 {
+			percent = mon.HP * 100 / mon.MaxHP
+		}
+		return compareNumbers(percent, cl.op, cl.value)
+	case "status":
+		return compareWords(mon.Status, cl.op, cl.value)
+	case "item":
+		return compareWords(strings.ToLower(mon.HeldItem), cl.op, cl.value)
+	case "type":
+		// A dual-type Pokemon "is" either of its types
+		matches := mon.Type1 == cl.value || (mon.Type2 != "" && mon.Type2 == cl.value)
+		if cl.op == "==" {
+			return matches
+		}
+		return !matches
+	}
+	return false
+}
+
+func compareWords(actual string, op string, value string) bool {
+	if value == "none" {
+		value = ""
+	}
+	if op == "==" {
+		return actual == value
+	}
+	return actual != value
+}
+
+func compareNumbers(actual int, op string, raw string) bool {
+	value, _ := strconv.Atoi(raw)
+	switch op {
+	case "==":
+		return actual == value
+	case "!=":
+		return actual != value
+	case "<":
+		return actual < value
+	case "<=":
+		return actual <= value
+	case ">":
+		return actual > value
+	}
+	return actual >= value
+}
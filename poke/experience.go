@@ -0,0 +1,87 @@
+package poke
+
+// Experience growth groups, identified by the total experience needed to
+// reach level 100 (the value of the CSV's experience_growth column).
+const (
+	GrowthErratic     = 600000
+	GrowthFast        = 800000
+	GrowthMediumFast  = 1000000
+	GrowthMediumSlow  = 1059860
+	GrowthSlow        = 1250000
+	GrowthFluctuating = 1640000
+)
+
+// ExperienceForLevel returns the total experience needed to reach a level
+// for the given growth group. Unknown groups use Medium Fast.
+func ExperienceForLevel(growth int, level int) int {
+	if level <= MinLevel {
+		return 0
+	}
+	if level > MaxLevel {
+		level = MaxLevel
+	}
+	n := level
+	cube := n * n * n
+
+	switch growth {
+	case GrowthErratic:
+		switch {
+		case n < 50:
+			return cube * (100 - n) / 50
+		case n < 68:
+			return cube * (150 - n) / 100
+		case n < 98:
+			return cube * ((1911 - 10*n) / 3) / 500
+		default:
+			return cube * (160 - n) / 100
+		}
+	case GrowthFast:
+		return 4 * cube / 5
+	case GrowthMediumSlow:
+		return 6*cube/5 - 15*n*n + 100*n - 140
+	case GrowthSlow:
+		return 5 * cube / 4
+	case GrowthFluctuating:
+		switch {
+		case n < 15:
+			return cube * ((n+1)/3 + 24) / 50
+		case n < 36:
+			return cube * (n + 14) / 50
+		default:
+			return cube * (n/2 + 32) / 50
+		}
+	default:
+		return cube
+	}
+}
+
+// LevelForExperience returns the highest level reached with the given experience.
+func LevelForExperience(growth int, experience int) int {
+	level := MinLevel
+	for level < MaxLevel && ExperienceForLevel(growth, level+1) <= experience {
+		level++
+	}
+	return level
+}
+
+// BattleResult is how a battle ended for one trainer.
+type BattleResult string
+
+const (
+	ResultWin  BattleResult = "win"
+	ResultLoss BattleResult = "loss"
+	ResultDraw BattleResult = "draw"
+)
+
+// BattleExperience returns the experience earned from a battle fought at the given level.
+// Winning is worth three times as much as losing, and a draw twice as much.
+func BattleExperience(level int, result BattleResult) int {
+	gained := level * level
+	switch result {
+	case ResultWin:
+		gained *= 3
+	case ResultDraw:
+		gained *= 2
+	}
+	return gained
+}
@@ -0,0 +1,213 @@
+package poke
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ItemKind separates items held by a Pokemon from consumables kept in the bag.
+type ItemKind string
+
+const (
+	HeldItem ItemKind = "held"
+	BagItem  ItemKind = "bag"
+)
+
+// Item describes a held item or a bag consumable.
+// Only the fields relevant to the item's effect are set.
+type Item struct {
+	Name        string
+	Kind        ItemKind
+	Description string
+
+	// Held item effects
+	BoostType          string  // Move type boosted by the item (type-boosting items)
+	BoostCategory      string  // Damage category boosted by the item (Choice items)
+	DamageMultiplier   float64 // Multiplier applied when the boost applies
+	HealFraction       int     // Heals 1/HealFraction of max HP at the end of each turn
+	InflictsStatus     string  // Status inflicted on the holder at the end of a turn (Orbs)
+	ResidualDamageFrac int     // Deals 1/ResidualDamageFrac of max HP to the attacker per hit (Life Orb)
+	LocksMove          bool    // Locks the holder into the first move it uses until it switches out (Choice items)
+
+	// Bag item effects
+	HealHP      int  // HP restored when used
+	CuresStatus bool // Removes the Pokemon's status condition
+}
+
+// Items contains every item keyed by name.
+var Items = map[string]Item{
+	// Held items
+	"Leftovers":      {Name: "Leftovers", Kind: HeldItem, Description: "Restores 1/16 of max HP every turn", HealFraction: 16},
+	"Choice Band":    {Name: "Choice Band", Kind: HeldItem, Description: "Boosts physical moves by 50%, but allows only the first move used", BoostCategory: Physical, DamageMultiplier: 1.5, LocksMove: true},
+	"Choice Specs":   {Name: "Choice Specs", Kind: HeldItem, Description: "Boosts special moves by 50%, but allows only the first move used", BoostCategory: Special, DamageMultiplier: 1.5, LocksMove: true},
+	"Life Orb":       {Name: "Life Orb", Kind: HeldItem, Description: "Boosts moves by 30% at the cost of 1/10 HP per hit", DamageMultiplier: 1.3, ResidualDamageFrac: 10},
+	"Flame Orb":      {Name: "Flame Orb", Kind: HeldItem, Description: "Burns the holder at the end of the turn", InflictsStatus: StatusBurn},
+	"Toxic Orb":      {Name: "Toxic Orb", Kind: HeldItem, Description: "Poisons the holder at the end of the turn", InflictsStatus: StatusPoison},
+	"Charcoal":       typeBoostItem("Charcoal", "fire"),
+	"Mystic Water":   typeBoostItem("Mystic Water", "water"),
+	"Miracle Seed":   typeBoostItem("Miracle Seed", "grass"),
+	"Magnet":         typeBoostItem("Magnet", "electric"),
+	"Never-Melt Ice": typeBoostItem("Never-Melt Ice", "ice"),
+	"Black Belt":     typeBoostItem("Black Belt", "fighting"),
+	"Poison Barb":    typeBoostItem("Poison Barb", "poison"),
+	"Soft Sand":      typeBoostItem("Soft Sand", "ground"),
+	"Sharp Beak":     typeBoostItem("Sharp Beak", "flying"),
+	"Twisted Spoon":  typeBoostItem("Twisted Spoon", "psychic"),
+	"Silver Powder":  typeBoostItem("Silver Powder", "bug"),
+	"Hard Stone":     typeBoostItem("Hard Stone", "rock"),
+	"Spell Tag":      typeBoostItem("Spell Tag", "ghost"),
+	"Dragon Fang":    typeBoostItem("Dragon Fang", "dragon"),
+	"Black Glasses":  typeBoostItem("Black Glasses", "dark"),
+	"Metal Coat":     typeBoostItem("Metal Coat", "steel"),
+	"Silk Scarf":     typeBoostItem("Silk Scarf", "normal"),
+	"Fairy Feather":  typeBoostItem("Fairy Feather", "fairy"),
+
+	// Bag items
+	"Potion":       {Name: "Potion", Kind: BagItem, Description: "Restores 20 HP", HealHP: 20},
+	"Super Potion": {Name: "Super Potion", Kind: BagItem, Description: "Restores 60 HP", HealHP: 60},
+	"Hyper Potion": {Name: "Hyper Potion", Kind: BagItem, Description: "Restores 120 HP", HealHP: 120},
+	"Full Heal":    {Name: "Full Heal", Kind: BagItem, Description: "Cures any status condition", CuresStatus: true},
+	"Full Restore": {Name: "Full Restore", Kind: BagItem, Description: "Fully restores HP and cures status", HealHP: 9999, CuresStatus: true},
+}
+
+// typeBoostItem builds a held item that boosts moves of one type by 20%.
+func typeBoostItem(name string, moveType string) Item {
+	return Item{
+		Name:             name,
+		Kind:             HeldItem,
+		Description:      fmt.Sprintf("Boosts %s-type moves by 20%%", moveType),
+		BoostType:        moveType,
+		DamageMultiplier: 1.2,
+	}
+}
+
+// CanUse reports whether the Pokemon may use move. A Choice item locks the
+// holder into the first move it uses until it switches out.
+func (p *Pokemon) CanUse(move Move) bool {
+	return p.ChoiceLock == "" || p.ChoiceLock == move.Name
+}
+
+// UsableMoves returns the moves the Pokemon may use.
+func (p *Pokemon) UsableMoves() []Move {
+	var moves []Move
+	for _, move := range p.Moves {
+		if p.CanUse(move) {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// GetItem looks up an item by name (case-insensitive).
+func GetItem(name string) (Item, bool) {
+	name = strings.TrimSpace(name)
+	if item, ok := Items[name]; ok || name == "" {
+		return item, ok
+	}
+	for key, item := range Items {
+		if strings.EqualFold(key, name) {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// ItemNames returns the sorted names of all items of the given kind.
+func ItemNames(kind ItemKind) []string {
+	names := []string{}
+	for name, item := range Items {
+		if item.Kind == kind {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AttackMultiplier returns the damage multiplier the held item gives to a move.
+func (i Item) AttackMultiplier(move Move) float64 {
+	if i.DamageMultiplier == 0 || !move.IsDamaging() {
+		return 1.0
+	}
+	if i.BoostType != "" && i.BoostType != move.Type {
+		return 1.0
+	}
+	if i.BoostCategory != "" && i.BoostCategory != move.DamageCategory {
+		return 1.0
+	}
+	return i.DamageMultiplier
+}
+
+// Bag holds consumable items and how many of each are left.
+type Bag map[string]int
+
+// Count returns the total number of items in the bag.
+func (b Bag) Count() int {
+	total := 0
+	for _, n := range b {
+		total += n
+	}
+	return total
+}
+
+// Take removes one of the named item. Returns false if none are left.
+func (b Bag) Take(name string) bool {
+	if b[name] <= 0 {
+		return false
+	}
+	b[name]--
+	if b[name] == 0 {
+		delete(b, name)
+	}
+	return true
+}
+
+// Names returns the item names in the bag in sorted order.
+func (b Bag) Names() []string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String encodes the bag as "Full Heal*1,Potion*2" for protocol messages.
+func (b Bag) String() string {
+	parts := []string{}
+	for _, name := range b.Names() {
+		parts = append(parts, fmt.Sprintf("%s*%d", name, b[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseBag decodes a bag encoded by Bag.String and validates every item.
+func ParseBag(encoded string) (Bag, error) {
+	bag := Bag{}
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return bag, nil
+	}
+
+	for _, part := range strings.Split(encoded, ",") {
+		nameCount := strings.SplitN(part, "*", 2)
+		if len(nameCount) != 2 {
+			return nil, fmt.Errorf("invalid bag entry %q", part)
+		}
+
+		item, ok := GetItem(nameCount[0])
+		if !ok || item.Kind != BagItem {
+			return nil, fmt.Errorf("unknown bag item %q", nameCount[0])
+		}
+
+		count, err := strconv.Atoi(strings.TrimSpace(nameCount[1]))
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid count for %s", item.Name)
+		}
+		bag[item.Name] += count
+	}
+
+	return bag, nil
+}
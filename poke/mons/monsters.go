@@ -2,6 +2,7 @@ package monsters
 
 import (
 	"log"
+	"os"
 	"path/filepath"
 
 	"github.com/zrygan/pokemonbattler/poke"
@@ -12,6 +13,23 @@ var MONSTERS map[string]poke.Pokemon
 
 // init loads the Pokemon data when the package is imported.
 func init() {
+	// The type chart must be loaded before custom moves are validated against it
+	chartPath := filepath.Join("data", "typechart.json")
+	if chart, err := poke.LoadTypeChart(chartPath); err == nil {
+		poke.UseTypeChart(chart)
+	} else if !os.IsNotExist(err) {
+		log.Printf("Warning: Failed to load type chart from %s, using the built-in chart: %v", chartPath, err)
+	}
+
+	// Custom moves must be registered before the Pokemon learn their moves
+	movesPath := filepath.Join("data", "moves.json")
+	if moves, err := poke.LoadMovesFromJSON(movesPath); err == nil {
+		poke.RegisterMoves(moves)
+		log.Printf("Successfully loaded %d custom moves", len(moves))
+	} else if !os.IsNotExist(err) {
+		log.Printf("Warning: Failed to load custom moves from %s: %v", movesPath, err)
+	}
+
 	// Try to load from the data directory
 	csvPath := filepath.Join("data", "pokemon.csv")
 
@@ -0,0 +1,150 @@
+package poke
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LearnedByAll in a move's learned_by list teaches it to every Pokemon.
+const LearnedByAll = "all"
+
+// moveSpec is a move as written in a move data file.
+type moveSpec struct {
+	Name        string       `json:"name"`
+	Type        string       `json:"type"`
+	Category    string       `json:"category"`
+	Power       float64      `json:"power,omitempty"`
+	MinHits     int          `json:"min_hits,omitempty"`
+	MaxHits     int          `json:"max_hits,omitempty"`
+	Recoil      int          `json:"recoil_percent,omitempty"`
+	Drain       int          `json:"drain_percent,omitempty"`
+	FixedDamage int          `json:"fixed_damage,omitempty"`
+	Priority    int          `json:"priority,omitempty"`
+	Weather     string       `json:"weather,omitempty"`
+	Terrain     string       `json:"terrain,omitempty"`
+	LearnedBy   []string     `json:"learned_by"`
+	Effects     []MoveEffect `json:"effects,omitempty"`
+}
+
+// CustomMove is a move loaded from a data file together with the types or
+// species that learn it.
+type CustomMove struct {
+	Move
+	LearnedBy []string // Types, species names or LearnedByAll
+}
+
+// customMoves are the data file moves added to every matching Pokemon.
+var customMoves []CustomMove
+
+// LoadMovesFromJSON loads custom moves from a JSON move data file. Every move
+// and effect is validated, so a bad file is rejected as a whole.
+func LoadMovesFromJSON(filepath string) ([]CustomMove, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []moveSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, err
+	}
+
+	moves := make([]CustomMove, 0, len(specs))
+	for _, spec := range specs {
+		move, err := spec.toMove()
+		if err != nil {
+			return nil, fmt.Errorf("move %q: %w", spec.Name, err)
+		}
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// RegisterMoves makes custom moves learnable. It must be called before the
+// Pokemon data is loaded.
+func RegisterMoves(moves []CustomMove) {
+	customMoves = append(customMoves, moves...)
+}
+
+// MovesHash returns a short hash of the registered custom moves. Both peers
+// rebuild each other's moves from their own data files, so they compare it
+// before a battle.
+func MovesHash() string {
+	return dataHash(customMoves)
+}
+
+// dataHash returns a short hash of v's JSON form, e.g. "sha256:1f0c...". The
+// prefix keeps the message parser from ever reading it as a number.
+func dataHash(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// toMove validates a move spec and converts it to a CustomMove.
+func (spec moveSpec) toMove() (CustomMove, error) {
+	move := Move{
+		Name:           strings.TrimSpace(spec.Name),
+		BasePower:      spec.Power,
+		Type:           strings.ToLower(spec.Type),
+		DamageCategory: strings.ToLower(spec.Category),
+		Weather:        spec.Weather,
+		Terrain:        spec.Terrain,
+		MinHits:        spec.MinHits,
+		MaxHits:        spec.MaxHits,
+		RecoilPercent:  spec.Recoil,
+		DrainPercent:   spec.Drain,
+		FixedDamage:    spec.FixedDamage,
+		Priority:       spec.Priority,
+		Effects:        spec.Effects,
+	}
+
+	if move.Name == "" {
+		return CustomMove{}, fmt.Errorf("missing name")
+	}
+	if _, ok := battleChart[move.Type]; !ok {
+		return CustomMove{}, fmt.Errorf("unknown type %q", spec.Type)
+	}
+	switch move.DamageCategory {
+	case Physical, Special:
+		if move.BasePower <= 0 && move.FixedDamage <= 0 {
+			return CustomMove{}, fmt.Errorf("damaging moves need a power or fixed damage")
+		}
+	case StatusCategory:
+	default:
+		return CustomMove{}, fmt.Errorf("unknown category %q", spec.Category)
+	}
+	if move.MinHits < 0 || move.MaxHits < move.MinHits {
+		return CustomMove{}, fmt.Errorf("invalid hit range %d-%d", move.MinHits, move.MaxHits)
+	}
+	if len(spec.LearnedBy) == 0 {
+		return CustomMove{}, fmt.Errorf("learned_by is empty")
+	}
+
+	for i := range move.Effects {
+		if err := move.Effects[i].Validate(); err != nil {
+			return CustomMove{}, fmt.Errorf("effect %d: %w", i+1, err)
+		}
+	}
+
+	return CustomMove{Move: move, LearnedBy: spec.LearnedBy}, nil
+}
+
+// learnedBy reports whether a Pokemon of the given species and types learns the move.
+func (m CustomMove) learnedBy(name, type1, type2 string) bool {
+	for _, learner := range m.LearnedBy {
+		learner = strings.ToLower(learner)
+		if learner == LearnedByAll || learner == strings.ToLower(name) ||
+			learner == type1 || (type2 != "" && learner == type2) {
+			return true
+		}
+	}
+	return false
+}
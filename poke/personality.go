@@ -26,14 +26,68 @@ var AllPersonalities = []Personality{
 	PersonalitySassy, PersonalityCalm, PersonalityPlayful, PersonalityProud,
 }
 
-// PokemonProfile stores nickname, personality, and friendship data
+// Extended personalities cover the remaining natures that change stats
+const (
+	PersonalityLonely  Personality = "Lonely"
+	PersonalityNaughty Personality = "Naughty"
+	PersonalityBold    Personality = "Bold"
+	PersonalityRelaxed Personality = "Relaxed"
+	PersonalityImpish  Personality = "Impish"
+	PersonalityLax     Personality = "Lax"
+	PersonalityNaive   Personality = "Naive"
+	PersonalityModest  Personality = "Modest"
+	PersonalityMild    Personality = "Mild"
+	PersonalityQuiet   Personality = "Quiet"
+	PersonalityRash    Personality = "Rash"
+	PersonalityGentle  Personality = "Gentle"
+)
+
+var ExtendedPersonalities = []Personality{
+	PersonalityLonely, PersonalityNaughty, PersonalityBold, PersonalityRelaxed,
+	PersonalityImpish, PersonalityLax, PersonalityNaive, PersonalityModest,
+	PersonalityMild, PersonalityQuiet, PersonalityRash, PersonalityGentle,
+}
+
+// personalityNatures maps each personality to the nature it grants.
+// Extended personalities share their nature's name; classic ones without a
+// matching stat-changing nature get the closest fit.
+var personalityNatures = map[Personality]string{
+	PersonalityBrave:   "Brave",   // +Attack, -Speed
+	PersonalityTimid:   "Timid",   // +Speed, -Attack
+	PersonalityJolly:   "Jolly",   // +Speed, -Special Attack
+	PersonalitySerious: "Careful", // +Special Defense, -Special Attack
+	PersonalitySassy:   "Sassy",   // +Special Defense, -Speed
+	PersonalityCalm:    "Calm",    // +Special Defense, -Attack
+	PersonalityPlayful: "Hasty",   // +Speed, -Defense
+	PersonalityProud:   "Adamant", // +Attack, -Special Attack
+}
+
+// Nature returns the nature this personality grants.
+func (p Personality) Nature() Nature {
+	if name, ok := personalityNatures[p]; ok {
+		return Natures[name]
+	}
+	return GetNature(string(p))
+}
+
+// PokemonProfile stores nickname, personality, friendship and training data
 type PokemonProfile struct {
-	OriginalName string      `json:"original_name"`
-	Nickname     string      `json:"nickname"`
-	Personality  Personality `json:"personality"`
-	Friendship   int         `json:"friendship"`    // 0-100
-	Victories    int         `json:"victories"`     // Number of battle wins
-	TotalBattles int         `json:"total_battles"` // Total battles participated
+	OriginalName     string      `json:"original_name"`
+	Nickname         string      `json:"nickname"`
+	Personality      Personality `json:"personality"`
+	Friendship       int         `json:"friendship"`        // 0-100
+	Victories        int         `json:"victories"`         // Number of battle wins
+	Draws            int         `json:"draws"`             // Number of drawn battles
+	TotalBattles     int         `json:"total_battles"`     // Total battles participated
+	PracticeWins     int         `json:"practice_wins"`     // Practice battles won, kept apart from the record above
+	PracticeLosses   int         `json:"practice_losses"`   // Practice battles lost
+	PracticeDraws    int         `json:"practice_draws"`    // Practice battles drawn
+	Level            int         `json:"level"`             // 1-100, driven by Experience
+	Experience       int         `json:"experience"`        // Total experience earned
+	ExperienceGrowth int         `json:"experience_growth"` // Growth group from the CSV
+	Nature           string      `json:"nature"`            // Nature name (e.g. "Adamant")
+	IVs              StatSet     `json:"ivs"`               // Individual values rolled on creation
+	EVs              StatSet     `json:"evs"`               // Effort values spread by the trainer
 }
 
 // GetDisplayName returns the nickname if set, otherwise the original name
@@ -60,6 +114,34 @@ func (p *PokemonProfile) DecreaseFriendship(amount int) {
 	}
 }
 
+// EnsureTraining fills in level, experience and IVs for new profiles and for
+// profiles saved before these fields existed, and syncs the nature with the personality.
+func (p *PokemonProfile) EnsureTraining(experienceGrowth int) {
+	if p.ExperienceGrowth == 0 {
+		p.ExperienceGrowth = experienceGrowth
+	}
+	if p.Level == 0 {
+		p.Level = DefaultLevel
+		p.Experience = ExperienceForLevel(p.ExperienceGrowth, p.Level)
+		p.IVs = RandomIVs()
+	}
+	// The nature always follows the personality
+	p.Nature = p.Personality.Nature().Name
+}
+
+// GainExperience adds experience and levels up according to the growth group.
+// Returns the number of levels gained.
+func (p *PokemonProfile) GainExperience(amount int) int {
+	p.Experience += amount
+	newLevel := LevelForExperience(p.ExperienceGrowth, p.Experience)
+	gained := newLevel - p.Level
+	if gained < 0 {
+		return 0
+	}
+	p.Level = newLevel
+	return gained
+}
+
 // RecordVictory records a battle victory
 func (p *PokemonProfile) RecordVictory() {
 	p.Victories++
@@ -73,6 +155,29 @@ func (p *PokemonProfile) RecordDefeat() {
 	p.IncreaseFriendship(2) // Gain 2 friendship even in defeat
 }
 
+// RecordDraw records a drawn battle
+func (p *PokemonProfile) RecordDraw() {
+	p.Draws++
+	p.TotalBattles++
+	p.IncreaseFriendship(3) // Gain 3 friendship for holding on to a draw
+}
+
+// RecordPractice records a practice battle against a local bot. Practice
+// has its own record, but earns friendship as a networked battle does.
+func (p *PokemonProfile) RecordPractice(result BattleResult) {
+	switch result {
+	case ResultWin:
+		p.PracticeWins++
+		p.IncreaseFriendship(5)
+	case ResultDraw:
+		p.PracticeDraws++
+		p.IncreaseFriendship(3)
+	default:
+		p.PracticeLosses++
+		p.IncreaseFriendship(2)
+	}
+}
+
 // GetFlavorText returns personality-specific flavor text for different battle events
 func (p *PokemonProfile) GetFlavorText(event string) string {
 	displayName := p.GetDisplayName()
@@ -96,6 +201,8 @@ func (p *PokemonProfile) GetFlavorText(event string) string {
 			return fmt.Sprintf("%s playfully prances around, ready for action!", displayName)
 		case PersonalityProud:
 			return fmt.Sprintf("%s holds its head high with pride!", displayName)
+		default:
+			return fmt.Sprintf("%s is ready for battle!", displayName)
 		}
 	case "critical_hit":
 		switch p.Personality {
@@ -223,12 +330,29 @@ func (p *PokemonProfile) DisplayProfile() {
 	} else {
 		fmt.Printf("\n=== %s ===\n", displayName)
 	}
-	fmt.Printf("Personality: %s\n", p.Personality)
+	fmt.Printf("Level: %d (Exp: %d)\n", p.Level, p.Experience)
+	fmt.Printf("Personality: %s, %s nature\n", p.Personality, GetNature(p.Nature).Describe())
+	fmt.Printf("IVs: %s  EVs: %s\n", p.IVs, p.EVs)
 	fmt.Printf("Friendship: %d/100 (%s)\n", p.Friendship, p.GetFriendshipLevel())
-	fmt.Printf("Battle Record: %d-%d (%.1f%% win rate)\n",
-		p.Victories,
-		p.TotalBattles-p.Victories,
-		float64(p.Victories)/float64(max(p.TotalBattles, 1))*100)
+	if p.Draws > 0 {
+		fmt.Printf("Battle Record: %d-%d-%d (%.1f%% win rate)\n",
+			p.Victories,
+			p.TotalBattles-p.Victories-p.Draws,
+			p.Draws,
+			float64(p.Victories)/float64(max(p.TotalBattles, 1))*100)
+	} else {
+		fmt.Printf("Battle Record: %d-%d (%.1f%% win rate)\n",
+			p.Victories,
+			p.TotalBattles-p.Victories,
+			float64(p.Victories)/float64(max(p.TotalBattles, 1))*100)
+	}
+	if practiced := p.PracticeWins + p.PracticeLosses + p.PracticeDraws; practiced > 0 {
+		fmt.Printf("Practice Record: %d-%d-%d (%.1f%% win rate)\n",
+			p.PracticeWins,
+			p.PracticeLosses,
+			p.PracticeDraws,
+			float64(p.PracticeWins)/float64(practiced)*100)
+	}
 }
 
 func max(a, b int) int {
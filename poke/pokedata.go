@@ -29,45 +29,59 @@ func LoadPokemonFromCSV(filepath string) (map[string]Pokemon, error) {
 		record := records[i]
 
 		// Parse stats from CSV columns
-		// attack (19), defense (25), hp (28), sp_attack (33), sp_defense (34),
-		// speed (35), type1 (36), type2 (37), name (30)
+		// abilities (0), attack (19), defense (25), experience_growth (26), hp (28),
+		// sp_attack (33), sp_defense (34), speed (35), type1 (36), type2 (37), name (30),
+		// generation (39), is_legendary (40)
 
 		attack, _ := strconv.Atoi(strings.TrimSpace(record[19]))
 		defense, _ := strconv.Atoi(strings.TrimSpace(record[25]))
+		growth, _ := strconv.Atoi(strings.TrimSpace(record[26]))
 		hp, _ := strconv.Atoi(strings.TrimSpace(record[28]))
 		spAttack, _ := strconv.Atoi(strings.TrimSpace(record[33]))
 		spDefense, _ := strconv.Atoi(strings.TrimSpace(record[34]))
 		speed, _ := strconv.Atoi(strings.TrimSpace(record[35]))
+		generation, _ := strconv.Atoi(strings.TrimSpace(record[39]))
+		legendary := strings.TrimSpace(record[40]) == "1"
 
 		name := strings.TrimSpace(record[30])
 		type1 := strings.ToLower(strings.TrimSpace(record[36]))
 		type2 := strings.ToLower(strings.TrimSpace(record[37]))
+		abilities := parseAbilities(record[0])
 
 		// Create basic moves for this Pokemon based on its type
-		moves := createDefaultMoves(type1, type2)
+		moves := createDefaultMoves(name, type1, type2)
 
 		pokemon := Pokemon{
-			Name:           name,
-			HP:             hp,
-			MaxHP:          hp,
-			Attack:         attack,
-			Defense:        defense,
-			SpecialAttack:  spAttack,
-			SpecialDefense: spDefense,
-			Speed:          speed,
-			Type1:          type1,
-			Type2:          type2,
-			Moves:          moves,
+			Name:  name,
+			Type1: type1,
+			Type2: type2,
+			Moves: moves,
+			BaseStats: StatSet{
+				HP:             hp,
+				Attack:         attack,
+				Defense:        defense,
+				SpecialAttack:  spAttack,
+				SpecialDefense: spDefense,
+				Speed:          speed,
+			},
+			ExperienceGrowth: growth,
+			Abilities:        abilities,
+			Generation:       generation,
+			Legendary:        legendary,
 		}
 
+		// Battle stats default to a level 50, perfect IV, neutral nature build
+		pokemon.ApplyStats(DefaultLevel, PerfectIVs(), StatSet{}, Natures[NeutralNature])
+
 		pokemons[name] = pokemon
 	}
 
 	return pokemons, nil
 }
 
-// createDefaultMoves creates a set of default moves for a Pokemon based on its types.
-func createDefaultMoves(type1, type2 string) []Move {
+// createDefaultMoves creates a set of default moves for a Pokemon based on its
+// types, plus any registered custom moves it learns.
+func createDefaultMoves(name, type1, type2 string) []Move {
 	moves := []Move{
 		{Name: "Tackle", BasePower: 40, Type: "normal", DamageCategory: Physical},
 	}
@@ -100,9 +114,76 @@ func createDefaultMoves(type1, type2 string) []Move {
 		DamageCategory: Special,
 	})
 
+	// Add a weather or terrain move for types that have one
+	if fieldMove, ok := fieldMoves[type1]; ok {
+		moves = append(moves, fieldMove)
+	}
+
+	// Every Pokemon can strike first with Quick Attack
+	moves = append(moves, Move{Name: "Quick Attack", BasePower: 40, Type: "normal", DamageCategory: Physical, Priority: 1})
+
+	// Add a multi-hit, recoil, drain or fixed damage move for the primary type
+	if effectMove, ok := effectMoves[type1]; ok {
+		moves = append(moves, effectMove)
+	}
+
+	// Add custom moves from the move data file
+	for _, custom := range customMoves {
+		if custom.learnedBy(name, type1, type2) {
+			moves = append(moves, custom.Move)
+		}
+	}
+
 	return moves
 }
 
+// fieldMoves maps a type to the weather or terrain move its Pokemon learn.
+var fieldMoves = map[string]Move{
+	"fire":     {Name: "Sunny Day", Type: "fire", DamageCategory: StatusCategory, Weather: "sun"},
+	"water":    {Name: "Rain Dance", Type: "water", DamageCategory: StatusCategory, Weather: "rain"},
+	"rock":     {Name: "Sandstorm", Type: "rock", DamageCategory: StatusCategory, Weather: "sand"},
+	"ground":   {Name: "Sandstorm", Type: "rock", DamageCategory: StatusCategory, Weather: "sand"},
+	"ice":      {Name: "Hail", Type: "ice", DamageCategory: StatusCategory, Weather: "hail"},
+	"electric": {Name: "Electric Terrain", Type: "electric", DamageCategory: StatusCategory, Terrain: "electric"},
+	"grass":    {Name: "Grassy Terrain", Type: "grass", DamageCategory: StatusCategory, Terrain: "grassy"},
+	"psychic":  {Name: "Psychic Terrain", Type: "psychic", DamageCategory: StatusCategory, Terrain: "psychic"},
+	"fairy":    {Name: "Misty Terrain", Type: "fairy", DamageCategory: StatusCategory, Terrain: "misty"},
+}
+
+// effectMoves maps a type to a move with a special effect its Pokemon learn.
+var effectMoves = map[string]Move{
+	"normal":   {Name: "Double-Edge", BasePower: 120, Type: "normal", DamageCategory: Physical, RecoilPercent: 33},
+	"fire":     {Name: "Flare Blitz", BasePower: 120, Type: "fire", DamageCategory: Physical, RecoilPercent: 33},
+	"water":    {Name: "Aqua Jet", BasePower: 40, Type: "water", DamageCategory: Physical, Priority: 1},
+	"electric": {Name: "Volt Tackle", BasePower: 120, Type: "electric", DamageCategory: Physical, RecoilPercent: 33},
+	"grass":    {Name: "Giga Drain", BasePower: 75, Type: "grass", DamageCategory: Special, DrainPercent: 50},
+	"bug":      {Name: "Pin Missile", BasePower: 25, Type: "bug", DamageCategory: Physical, MinHits: 2, MaxHits: 5},
+	"fighting": {Name: "Double Kick", BasePower: 30, Type: "fighting", DamageCategory: Physical, MinHits: 2, MaxHits: 2},
+	"ghost":    {Name: "Night Shade", Type: "ghost", DamageCategory: Special, FixedDamage: 50},
+	"dragon":   {Name: "Dragon Rage", Type: "dragon", DamageCategory: Special, FixedDamage: 40},
+	"ice":      {Name: "Icicle Spear", BasePower: 25, Type: "ice", DamageCategory: Physical, MinHits: 2, MaxHits: 5},
+	"rock":     {Name: "Rock Blast", BasePower: 25, Type: "rock", DamageCategory: Physical, MinHits: 2, MaxHits: 5},
+	"dark":     {Name: "Sucker Punch", BasePower: 70, Type: "dark", DamageCategory: Physical, Priority: 1},
+	"steel":    {Name: "Bullet Punch", BasePower: 40, Type: "steel", DamageCategory: Physical, Priority: 1},
+	"flying":   {Name: "Brave Bird", BasePower: 120, Type: "flying", DamageCategory: Physical, RecoilPercent: 33},
+	"psychic":  {Name: "Psywave", Type: "psychic", DamageCategory: Special, FixedDamage: 40},
+	"fairy":    {Name: "Draining Kiss", BasePower: 50, Type: "fairy", DamageCategory: Special, DrainPercent: 75},
+	"ground":   {Name: "Bonemerang", BasePower: 50, Type: "ground", DamageCategory: Physical, MinHits: 2, MaxHits: 2},
+}
+
+// parseAbilities parses the CSV abilities column, e.g. "['Overgrow', 'Chlorophyll']".
+func parseAbilities(raw string) []string {
+	raw = strings.Trim(strings.TrimSpace(raw), "[]")
+	abilities := []string{}
+	for _, part := range strings.Split(raw, ",") {
+		ability := strings.Trim(strings.TrimSpace(part), "'\"")
+		if ability != "" {
+			abilities = append(abilities, ability)
+		}
+	}
+	return abilities
+}
+
 // getTypicalDamageCategory returns the typical damage category for a type.
 func getTypicalDamageCategory(pokeType string) string {
 	// Special types (typically use special attack/defense)
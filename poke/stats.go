@@ -0,0 +1,267 @@
+package poke
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Stat identifies one of the six battle stats.
+type Stat string
+
+const (
+	StatHP             Stat = "hp"
+	StatAttack         Stat = "attack"
+	StatDefense        Stat = "defense"
+	StatSpecialAttack  Stat = "special_attack"
+	StatSpecialDefense Stat = "special_defense"
+	StatSpeed          Stat = "speed"
+)
+
+// AllStats lists the stats in the order used for serialization.
+var AllStats = []Stat{
+	StatHP, StatAttack, StatDefense, StatSpecialAttack, StatSpecialDefense, StatSpeed,
+}
+
+// Level and training limits used by the stat formula.
+const (
+	MinLevel      = 1
+	MaxLevel      = 100
+	DefaultLevel  = 50
+	MaxIV         = 31
+	MaxEV         = 252
+	MaxTotalEV    = 510
+	NeutralNature = "Hardy"
+)
+
+// StatSet holds one value per stat (base stats, IVs or EVs).
+type StatSet struct {
+	HP             int `json:"hp"`
+	Attack         int `json:"attack"`
+	Defense        int `json:"defense"`
+	SpecialAttack  int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed          int `json:"speed"`
+}
+
+// Get returns the value for the given stat.
+func (s StatSet) Get(stat Stat) int {
+	switch stat {
+	case StatHP:
+		return s.HP
+	case StatAttack:
+		return s.Attack
+	case StatDefense:
+		return s.Defense
+	case StatSpecialAttack:
+		return s.SpecialAttack
+	case StatSpecialDefense:
+		return s.SpecialDefense
+	case StatSpeed:
+		return s.Speed
+	}
+	return 0
+}
+
+// Set updates the value for the given stat.
+func (s *StatSet) Set(stat Stat, value int) {
+	switch stat {
+	case StatHP:
+		s.HP = value
+	case StatAttack:
+		s.Attack = value
+	case StatDefense:
+		s.Defense = value
+	case StatSpecialAttack:
+		s.SpecialAttack = value
+	case StatSpecialDefense:
+		s.SpecialDefense = value
+	case StatSpeed:
+		s.Speed = value
+	}
+}
+
+// Total returns the sum of all six values.
+func (s StatSet) Total() int {
+	total := 0
+	for _, stat := range AllStats {
+		total += s.Get(stat)
+	}
+	return total
+}
+
+// String encodes the set as "hp/atk/def/spa/spd/spe" for protocol messages.
+func (s StatSet) String() string {
+	parts := make([]string, len(AllStats))
+	for i, stat := range AllStats {
+		parts[i] = strconv.Itoa(s.Get(stat))
+	}
+	return strings.Join(parts, "/")
+}
+
+// ParseStatSet decodes a set encoded by StatSet.String.
+func ParseStatSet(encoded string) (StatSet, error) {
+	var s StatSet
+	parts := strings.Split(strings.TrimSpace(encoded), "/")
+	if len(parts) != len(AllStats) {
+		return s, fmt.Errorf("expected %d values, got %d", len(AllStats), len(parts))
+	}
+	for i, stat := range AllStats {
+		value, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil {
+			return s, fmt.Errorf("invalid %s value %q", stat, parts[i])
+		}
+		s.Set(stat, value)
+	}
+	return s, nil
+}
+
+// ValidateIVs checks that every IV is within 0-31.
+func ValidateIVs(ivs StatSet) error {
+	for _, stat := range AllStats {
+		if v := ivs.Get(stat); v < 0 || v > MaxIV {
+			return fmt.Errorf("%s IV %d out of range 0-%d", stat, v, MaxIV)
+		}
+	}
+	return nil
+}
+
+// ValidateEVs checks that every EV is within 0-252 and the total is at most 510.
+func ValidateEVs(evs StatSet) error {
+	for _, stat := range AllStats {
+		if v := evs.Get(stat); v < 0 || v > MaxEV {
+			return fmt.Errorf("%s EV %d out of range 0-%d", stat, v, MaxEV)
+		}
+	}
+	if evs.Total() > MaxTotalEV {
+		return fmt.Errorf("EV total %d exceeds %d", evs.Total(), MaxTotalEV)
+	}
+	return nil
+}
+
+// RandomIVs rolls a fresh set of individual values.
+func RandomIVs() StatSet {
+	var ivs StatSet
+	for _, stat := range AllStats {
+		ivs.Set(stat, rand.Intn(MaxIV+1))
+	}
+	return ivs
+}
+
+// PerfectIVs returns a set with every IV at the maximum of 31.
+func PerfectIVs() StatSet {
+	return StatSet{MaxIV, MaxIV, MaxIV, MaxIV, MaxIV, MaxIV}
+}
+
+// Nature raises one stat by 10% and lowers another by 10%.
+// Neutral natures raise and lower the same stat, which cancels out.
+type Nature struct {
+	Name      string
+	Increased Stat
+	Decreased Stat
+}
+
+// Natures contains the 25 standard natures keyed by name.
+var Natures = map[string]Nature{
+	"Hardy":   {"Hardy", StatAttack, StatAttack},
+	"Lonely":  {"Lonely", StatAttack, StatDefense},
+	"Brave":   {"Brave", StatAttack, StatSpeed},
+	"Adamant": {"Adamant", StatAttack, StatSpecialAttack},
+	"Naughty": {"Naughty", StatAttack, StatSpecialDefense},
+	"Bold":    {"Bold", StatDefense, StatAttack},
+	"Docile":  {"Docile", StatDefense, StatDefense},
+	"Relaxed": {"Relaxed", StatDefense, StatSpeed},
+	"Impish":  {"Impish", StatDefense, StatSpecialAttack},
+	"Lax":     {"Lax", StatDefense, StatSpecialDefense},
+	"Timid":   {"Timid", StatSpeed, StatAttack},
+	"Hasty":   {"Hasty", StatSpeed, StatDefense},
+	"Serious": {"Serious", StatSpeed, StatSpeed},
+	"Jolly":   {"Jolly", StatSpeed, StatSpecialAttack},
+	"Naive":   {"Naive", StatSpeed, StatSpecialDefense},
+	"Modest":  {"Modest", StatSpecialAttack, StatAttack},
+	"Mild":    {"Mild", StatSpecialAttack, StatDefense},
+	"Quiet":   {"Quiet", StatSpecialAttack, StatSpeed},
+	"Bashful": {"Bashful", StatSpecialAttack, StatSpecialAttack},
+	"Rash":    {"Rash", StatSpecialAttack, StatSpecialDefense},
+	"Calm":    {"Calm", StatSpecialDefense, StatAttack},
+	"Gentle":  {"Gentle", StatSpecialDefense, StatDefense},
+	"Sassy":   {"Sassy", StatSpecialDefense, StatSpeed},
+	"Careful": {"Careful", StatSpecialDefense, StatSpecialAttack},
+	"Quirky":  {"Quirky", StatSpecialDefense, StatSpecialDefense},
+}
+
+// GetNature looks up a nature by name (case-insensitive).
+// Unknown names fall back to the neutral Hardy nature.
+func GetNature(name string) Nature {
+	for key, nature := range Natures {
+		if strings.EqualFold(key, name) {
+			return nature
+		}
+	}
+	return Natures[NeutralNature]
+}
+
+// IsNeutral reports whether the nature has no effect on stats.
+func (n Nature) IsNeutral() bool {
+	return n.Increased == n.Decreased
+}
+
+// Modifier returns the nature multiplier for a stat (0.9, 1.0 or 1.1).
+func (n Nature) Modifier(stat Stat) float64 {
+	if n.IsNeutral() {
+		return 1.0
+	}
+	switch stat {
+	case n.Increased:
+		return 1.1
+	case n.Decreased:
+		return 0.9
+	}
+	return 1.0
+}
+
+// Describe returns a short "+Stat / -Stat" summary of the nature.
+func (n Nature) Describe() string {
+	if n.IsNeutral() {
+		return n.Name + " (neutral)"
+	}
+	return fmt.Sprintf("%s (+%s, -%s)", n.Name, n.Increased, n.Decreased)
+}
+
+// CalculateStat applies the standard stat formula to a single stat.
+func CalculateStat(stat Stat, base, iv, ev, level int, nature Nature) int {
+	core := (2*base + iv + ev/4) * level / 100
+	if stat == StatHP {
+		if base == 1 {
+			return 1 // Shedinja-style species always have exactly 1 HP
+		}
+		return core + level + 10
+	}
+	// Integer percentages keep the result identical on every peer
+	return (core + 5) * int(math.Round(nature.Modifier(stat)*100)) / 100
+}
+
+// CalculateStats computes all battle stats from base stats, level, IVs, EVs and nature.
+func CalculateStats(base StatSet, level int, ivs StatSet, evs StatSet, nature Nature) StatSet {
+	var stats StatSet
+	for _, stat := range AllStats {
+		stats.Set(stat, CalculateStat(stat, base.Get(stat), ivs.Get(stat), evs.Get(stat), level, nature))
+	}
+	return stats
+}
+
+// ClampLevel keeps a level within 1 and the given cap.
+func ClampLevel(level int, levelCap int) int {
+	if levelCap <= 0 || levelCap > MaxLevel {
+		levelCap = MaxLevel
+	}
+	if level < MinLevel {
+		return MinLevel
+	}
+	if level > levelCap {
+		return levelCap
+	}
+	return level
+}
@@ -0,0 +1,49 @@
+package poke
+
+// Status conditions. StatusNone means the Pokemon is healthy.
+const (
+	StatusNone   = ""
+	StatusBurn   = "burn"
+	StatusPoison = "poison"
+)
+
+// StatusResidualFraction returns the 1/N of max HP a status deals at the end
+// of each turn, or 0 if the status deals no residual damage.
+func StatusResidualFraction(status string) int {
+	switch status {
+	case StatusBurn:
+		return 16
+	case StatusPoison:
+		return 8
+	}
+	return 0
+}
+
+// statusImmuneTypes lists the types that can never get a status.
+var statusImmuneTypes = map[string][]string{
+	StatusBurn:   {"fire"},
+	StatusPoison: {"poison", "steel"},
+}
+
+// ImmuneToStatus reports whether the Pokemon's type keeps it from ever
+// getting a status: Fire types are never burned, and Poison and Steel types
+// are never poisoned.
+func (p *Pokemon) ImmuneToStatus(status string) bool {
+	for _, t := range statusImmuneTypes[status] {
+		if p.Type1 == t || p.Type2 == t {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusName returns a display name for a status condition.
+func StatusName(status string) string {
+	switch status {
+	case StatusBurn:
+		return "Burned"
+	case StatusPoison:
+		return "Poisoned"
+	}
+	return "Healthy"
+}
@@ -28,6 +28,7 @@ func (tm *TeamManager) CustomizePokemon(pokemon *Pokemon) (*PokemonProfile, erro
 	profile, err := LoadProfile(tm.TrainerName, pokemon.Name)
 	if err == nil {
 		fmt.Printf("\nWelcome back! Found existing profile for %s!\n", pokemon.Name)
+		profile.EnsureTraining(pokemon.ExperienceGrowth)
 		profile.DisplayProfile()
 
 		fmt.Print("\nWould you like to use this profile? (y/n): ")
@@ -41,6 +42,7 @@ func (tm *TeamManager) CustomizePokemon(pokemon *Pokemon) (*PokemonProfile, erro
 
 	// Create new profile
 	profile = NewPokemonProfile(pokemon.Name)
+	profile.EnsureTraining(pokemon.ExperienceGrowth)
 
 	fmt.Printf("\nLet's customize your %s!\n", pokemon.Name)
 
@@ -53,24 +55,53 @@ func (tm *TeamManager) CustomizePokemon(pokemon *Pokemon) (*PokemonProfile, erro
 		fmt.Printf("Great! Your %s will be called \"%s\"!\n", pokemon.Name, nickname)
 	}
 
-	// Choose personality
+	// Choose personality (classic list first, then the extended list)
+	personalities := append(append([]Personality{}, AllPersonalities...), ExtendedPersonalities...)
 	fmt.Println("\nChoose a personality for your Pokemon:")
-	for i, personality := range AllPersonalities {
-		fmt.Printf("%d. %s\n", i+1, personality)
+	for i, personality := range personalities {
+		if i == len(AllPersonalities) {
+			fmt.Println("-- Extended personalities --")
+		}
+		fmt.Printf("%d. %s - %s\n", i+1, personality, personality.Nature().Describe())
 	}
 
 	for {
-		fmt.Print("\nEnter personality number (1-8): ")
+		fmt.Printf("\nEnter personality number (1-%d): ", len(personalities))
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
 
 		choice, err := strconv.Atoi(input)
-		if err == nil && choice >= 1 && choice <= len(AllPersonalities) {
-			profile.Personality = AllPersonalities[choice-1]
+		if err == nil && choice >= 1 && choice <= len(personalities) {
+			profile.Personality = personalities[choice-1]
+			profile.Nature = profile.Personality.Nature().Name
 			fmt.Printf("Perfect! Your Pokemon has a %s personality!\n", profile.Personality)
 			break
 		}
-		fmt.Println("Invalid choice. Please enter a number between 1 and 8.")
+		fmt.Printf("Invalid choice. Please enter a number between 1 and %d.\n", len(personalities))
+	}
+
+	// Spread effort values
+	fmt.Printf("\n%s has a %s nature and starts at level %d.\n",
+		profile.GetDisplayName(), GetNature(profile.Nature).Describe(), profile.Level)
+	for {
+		fmt.Printf("\nSpread up to %d effort values as hp/atk/def/spa/spd/spe (max %d each),\n", MaxTotalEV, MaxEV)
+		fmt.Print("or press Enter to skip: ")
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			break
+		}
+
+		evs, err := ParseStatSet(input)
+		if err == nil {
+			err = ValidateEVs(evs)
+		}
+		if err == nil {
+			profile.EVs = evs
+			fmt.Printf("Effort values set to %s!\n", evs)
+			break
+		}
+		fmt.Printf("Invalid effort values: %v\n", err)
 	}
 
 	// Show flavor text
@@ -87,17 +118,48 @@ func (tm *TeamManager) CustomizePokemon(pokemon *Pokemon) (*PokemonProfile, erro
 }
 
 // UpdateProfileAfterBattle updates the profile after a battle concludes
-func (tm *TeamManager) UpdateProfileAfterBattle(profile *PokemonProfile, won bool) error {
-	if won {
+func (tm *TeamManager) UpdateProfileAfterBattle(profile *PokemonProfile, result BattleResult) error {
+	switch result {
+	case ResultWin:
 		profile.RecordVictory()
+	case ResultDraw:
+		profile.RecordDraw()
+	default:
+		profile.RecordDefeat()
+	}
+	return tm.rewardBattle(profile, result)
+}
+
+// UpdateProfileAfterPractice updates the profile after a practice battle
+// against a local bot. The result goes on the practice record, apart from
+// networked battles; friendship and experience are earned as usual.
+func (tm *TeamManager) UpdateProfileAfterPractice(profile *PokemonProfile, result BattleResult) error {
+	profile.RecordPractice(result)
+	return tm.rewardBattle(profile, result)
+}
+
+// rewardBattle announces the friendship a recorded battle earned, awards
+// its experience and saves the profile.
+func (tm *TeamManager) rewardBattle(profile *PokemonProfile, result BattleResult) error {
+	switch result {
+	case ResultWin:
 		fmt.Printf("\n%s\n", profile.GetFlavorText("victory"))
 		fmt.Printf("%s gained friendship! (+5)\n", profile.GetDisplayName())
-	} else {
-		profile.RecordDefeat()
+	case ResultDraw:
+		fmt.Printf("\n%s held its ground to a draw!\n", profile.GetDisplayName())
+		fmt.Printf("%s gained friendship! (+3)\n", profile.GetDisplayName())
+	default:
 		fmt.Printf("\n%s tried their best...\n", profile.GetDisplayName())
 		fmt.Printf("%s gained friendship! (+2)\n", profile.GetDisplayName())
 	}
 
+	// Award experience and level up according to the growth group
+	experience := BattleExperience(profile.Level, result)
+	fmt.Printf("%s gained %d experience!\n", profile.GetDisplayName(), experience)
+	if levels := profile.GainExperience(experience); levels > 0 {
+		fmt.Printf("%s grew to level %d!\n", profile.GetDisplayName(), profile.Level)
+	}
+
 	// Show updated stats
 	profile.DisplayProfile()
 
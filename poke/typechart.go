@@ -0,0 +1,207 @@
+package poke
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TypeChart maps an attacking type to its multipliers against defending
+// types. Pairs that are not listed are neutral (1x).
+type TypeChart map[string]map[string]float64
+
+// The chart battles use and its inverse, built once. They start as the
+// built-in TypeEffectiveness.
+var (
+	battleChart  = TypeEffectiveness
+	inverseChart = TypeEffectiveness.Inverse()
+)
+
+// UseTypeChart makes battles use chart instead of the built-in
+// TypeEffectiveness. It is called once at startup, before any battle.
+func UseTypeChart(chart TypeChart) {
+	battleChart, inverseChart = chart, chart.Inverse()
+}
+
+// BattleTypeChart returns the chart battles use, or its inverse for an
+// inverse battle.
+func BattleTypeChart(inverse bool) TypeChart {
+	if inverse {
+		return inverseChart
+	}
+	return battleChart
+}
+
+// TypeChartHash returns a short hash of the chart battles use. Each peer
+// loads its own chart, so they compare it before a battle.
+func TypeChartHash() string {
+	return dataHash(battleChart)
+}
+
+// Multiplier returns the multiplier of an attacking type against one defending type.
+func (c TypeChart) Multiplier(attackType string, defenseType string) float64 {
+	if matchups, ok := c[attackType]; ok {
+		if multiplier, ok := matchups[defenseType]; ok {
+			return multiplier
+		}
+	}
+	return 1.0 // Neutral effectiveness
+}
+
+// Against returns the combined multiplier of an attacking type against a
+// Pokemon with the given types (type2 is "" for a single type).
+func (c TypeChart) Against(attackType string, type1 string, type2 string) float64 {
+	multiplier := c.Multiplier(attackType, type1)
+	if type2 != "" {
+		multiplier *= c.Multiplier(attackType, type2)
+	}
+	return multiplier
+}
+
+// Inverse returns the inverse battle chart: every multiplier is flipped, so
+// super effective matchups become not very effective, resistances become
+// weaknesses and immunities become 2x weaknesses.
+func (c TypeChart) Inverse() TypeChart {
+	types := c.Types()
+	inverse := make(TypeChart, len(types))
+	for _, attackType := range types {
+		inverse[attackType] = map[string]float64{}
+		for _, defenseType := range types {
+			switch multiplier := c.Multiplier(attackType, defenseType); {
+			case multiplier == 0:
+				inverse[attackType][defenseType] = 2.0
+			case multiplier != 1:
+				inverse[attackType][defenseType] = 1 / multiplier
+			}
+		}
+	}
+	return inverse
+}
+
+// Types returns the attacking types of the chart in alphabetical order.
+func (c TypeChart) Types() []string {
+	types := make([]string, 0, len(c))
+	for attackType := range c {
+		types = append(types, attackType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Validate returns an error if the chart refers to a defending type that
+// cannot attack, or has a negative multiplier.
+func (c TypeChart) Validate() error {
+	if len(c) == 0 {
+		return fmt.Errorf("type chart is empty")
+	}
+	for _, attackType := range c.Types() {
+		for defenseType, multiplier := range c[attackType] {
+			if _, ok := c[defenseType]; !ok {
+				return fmt.Errorf("%s: unknown defending type %q", attackType, defenseType)
+			}
+			if multiplier < 0 {
+				return fmt.Errorf("%s vs %s: negative multiplier %g", attackType, defenseType, multiplier)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadTypeChart loads a type chart from a JSON file shaped like
+// {"fire": {"grass": 2, "water": 0.5}, ...}.
+func LoadTypeChart(filepath string) (TypeChart, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	var chart TypeChart
+	if err := json.Unmarshal(data, &chart); err != nil {
+		return nil, err
+	}
+	if err := chart.Validate(); err != nil {
+		return nil, err
+	}
+	return chart, nil
+}
+
+// TypeMismatch is a species whose against_* column disagrees with the
+// multiplier computed from a type chart.
+type TypeMismatch struct {
+	Species    string
+	AttackType string
+	Chart      float64 // Multiplier computed from the chart
+	CSV        float64 // Multiplier listed in the CSV
+}
+
+func (m TypeMismatch) String() string {
+	return fmt.Sprintf("%s: %s attacks are x%g in the chart but x%g in the CSV", m.Species, m.AttackType, m.Chart, m.CSV)
+}
+
+// csvTypeNames maps against_* column suffixes that differ from type names.
+var csvTypeNames = map[string]string{"fight": "fighting"}
+
+// CheckTypeChart compares the chart's dual-type multipliers with the
+// against_* columns of every species in the Pokemon CSV. Returns the
+// mismatches in CSV order. Some are expected: abilities such as Levitate
+// change a species' column without changing its types.
+func CheckTypeChart(chart TypeChart, csvPath string) ([]TypeMismatch, error) {
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is empty", csvPath)
+	}
+
+	// Find the columns by header so the check does not depend on their order
+	columns := map[string]int{}
+	against := map[string]int{}
+	for i, header := range records[0] {
+		header = strings.TrimSpace(header)
+		columns[header] = i
+		if suffix, ok := strings.CutPrefix(header, "against_"); ok {
+			if name, renamed := csvTypeNames[suffix]; renamed {
+				suffix = name
+			}
+			against[suffix] = i
+		}
+	}
+	for _, required := range []string{"name", "type1", "type2"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%s has no %s column", csvPath, required)
+		}
+	}
+
+	var mismatches []TypeMismatch
+	for _, record := range records[1:] {
+		name := strings.TrimSpace(record[columns["name"]])
+		type1 := strings.ToLower(strings.TrimSpace(record[columns["type1"]]))
+		type2 := strings.ToLower(strings.TrimSpace(record[columns["type2"]]))
+
+		for _, attackType := range chart.Types() {
+			column, ok := against[attackType]
+			if !ok {
+				continue
+			}
+			listed, err := strconv.ParseFloat(strings.TrimSpace(record[column]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: bad against_%s value %q", name, attackType, record[column])
+			}
+			if computed := chart.Against(attackType, type1, type2); computed != listed {
+				mismatches = append(mismatches, TypeMismatch{Species: name, AttackType: attackType, Chart: computed, CSV: listed})
+			}
+		}
+	}
+	return mismatches, nil
+}
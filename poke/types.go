@@ -1,36 +1,131 @@
 package poke
 
+import "fmt"
+
 // Pokemon represents a player's pokemon with stats and boosts.
+// The battle stats (HP through Speed) are derived from BaseStats, Level,
+// IVs, EVs and Nature by ApplyStats.
 type Pokemon struct {
-	Name           string // Pokemon name
-	HP             int    // Hit points (current HP)
-	MaxHP          int    // Maximum hit points
-	Attack         int    // Physical attack stat
-	Defense        int    // Physical defense stat
-	SpecialAttack  int    // Special attack stat
-	SpecialDefense int    // Special defense stat
-	Speed          int    // Speed stat (determines turn order)
-	Type1          string // Primary type (e.g., "fire", "water", "grass")
-	Type2          string // Secondary type (empty string if single type)
-	Moves          []Move // List of moves the pokemon can use
+	Name             string   // Pokemon name
+	HP               int      // Hit points (current HP)
+	MaxHP            int      // Maximum hit points
+	Attack           int      // Physical attack stat
+	Defense          int      // Physical defense stat
+	SpecialAttack    int      // Special attack stat
+	SpecialDefense   int      // Special defense stat
+	Speed            int      // Speed stat (determines turn order)
+	Type1            string   // Primary type (e.g., "fire", "water", "grass")
+	Type2            string   // Secondary type (empty string if single type)
+	Moves            []Move   // List of moves the pokemon can use
+	BaseStats        StatSet  // Species base stats from the CSV
+	Level            int      // Battle level (1-100)
+	IVs              StatSet  // Individual values (0-31 each)
+	EVs              StatSet  // Effort values (0-252 each, 510 total)
+	Nature           Nature   // Nature modifying two stats by 10%
+	ExperienceGrowth int      // Total experience to reach level 100 (growth group)
+	Abilities        []string // Possible abilities from the CSV
+	HeldItem         string   // Name of the held item ("" for none)
+	Status           string   // Status condition (StatusNone when healthy)
+	Stages           StatSet  // Stat stages from move effects (-6 to 6), cleared on switching out
+	Protecting       bool     // Blocks the next attack aimed at it
+	ChoiceLock       string   // Move a Choice item locks it into ("" for none), cleared on switching out
+	Generation       int      // Generation the species was introduced in
+	Legendary        bool     // The species is legendary
+}
+
+// ApplyStats sets the level, IVs, EVs and nature and recomputes the battle
+// stats with the standard formula. HP is restored to the new maximum.
+func (p *Pokemon) ApplyStats(level int, ivs StatSet, evs StatSet, nature Nature) {
+	p.Level = ClampLevel(level, MaxLevel)
+	p.IVs = ivs
+	p.EVs = evs
+	p.Nature = nature
+
+	stats := CalculateStats(p.BaseStats, p.Level, ivs, evs, nature)
+	p.MaxHP = stats.HP
+	p.HP = stats.HP
+	p.Attack = stats.Attack
+	p.Defense = stats.Defense
+	p.SpecialAttack = stats.SpecialAttack
+	p.SpecialDefense = stats.SpecialDefense
+	p.Speed = stats.Speed
 }
 
 // Move represents a single move a pokemon can use in battle.
 type Move struct {
-	Name           string  // Name of the pokemon move
-	BasePower      float64 // Base power of the move (default 1.0)
-	Type           string  // Type of the move (e.g., "fire", "water")
-	DamageCategory string  // "physical" or "special"
+	Name           string       // Name of the pokemon move
+	BasePower      float64      // Base power of the move (default 1.0)
+	Type           string       // Type of the move (e.g., "fire", "water")
+	DamageCategory string       // "physical", "special" or "status"
+	Weather        string       // Weather the move sets (e.g., "rain"), if any
+	Terrain        string       // Terrain the move sets (e.g., "grassy"), if any
+	MinHits        int          // Fewest hits a multi-hit move lands (0 for a single hit)
+	MaxHits        int          // Most hits a multi-hit move lands (0 for a single hit)
+	RecoilPercent  int          // Percent of the damage dealt the attacker takes back
+	DrainPercent   int          // Percent of the damage dealt the attacker restores
+	FixedDamage    int          // Damage dealt regardless of stats (0 to calculate it)
+	Priority       int          // Priority bracket; higher brackets act first in a round
+	Effects        []MoveEffect // Scripted effects from a move data file
 }
 
 // DamageCategory constants
 const (
-	Physical = "physical"
-	Special  = "special"
+	Physical       = "physical"
+	Special        = "special"
+	StatusCategory = "status" // Deals no damage (e.g., weather and terrain moves)
 )
 
-// Type effectiveness multipliers
-var TypeEffectiveness = map[string]map[string]float64{
+// IsDamaging reports whether the move deals damage.
+func (m Move) IsDamaging() bool {
+	return m.DamageCategory != StatusCategory
+}
+
+// IsMultiHit reports whether the move can strike more than once.
+func (m Move) IsMultiHit() bool {
+	return m.MaxHits > 1
+}
+
+// AffectsTarget reports whether any of the move's scripted effects lands on
+// the opposing Pokemon.
+func (m Move) AffectsTarget() bool {
+	for _, effect := range m.Effects {
+		if !effect.OnUser() && effect.DamageMultiplier == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectSummary lists the move's special effects, e.g. "2-5 hits, 33% recoil".
+func (m Move) EffectSummary() []string {
+	var effects []string
+	switch {
+	case m.IsMultiHit() && m.MinHits == m.MaxHits:
+		effects = append(effects, fmt.Sprintf("%d hits", m.MaxHits))
+	case m.IsMultiHit():
+		effects = append(effects, fmt.Sprintf("%d-%d hits", max(m.MinHits, 1), m.MaxHits))
+	}
+	if m.FixedDamage > 0 {
+		effects = append(effects, fmt.Sprintf("always %d damage", m.FixedDamage))
+	}
+	if m.RecoilPercent > 0 {
+		effects = append(effects, fmt.Sprintf("%d%% recoil", m.RecoilPercent))
+	}
+	if m.DrainPercent > 0 {
+		effects = append(effects, fmt.Sprintf("drains %d%%", m.DrainPercent))
+	}
+	if m.Priority != 0 {
+		effects = append(effects, fmt.Sprintf("priority %+d", m.Priority))
+	}
+	for _, effect := range m.Effects {
+		effects = append(effects, effect.Describe())
+	}
+	return effects
+}
+
+// TypeEffectiveness is the built-in standard type chart. Battles use
+// data/typechart.json instead when that file is present; see UseTypeChart.
+var TypeEffectiveness = TypeChart{
 	"normal": {
 		"rock": 0.5, "ghost": 0.0, "steel": 0.5,
 	},
@@ -93,12 +188,8 @@ var TypeEffectiveness = map[string]map[string]float64{
 	},
 }
 
-// GetTypeEffectiveness returns the type effectiveness multiplier for an attack type against a defending type.
+// GetTypeEffectiveness returns the type effectiveness multiplier for an attack
+// type against a defending type, using the standard type chart.
 func GetTypeEffectiveness(attackType string, defenseType string) float64 {
-	if matchups, ok := TypeEffectiveness[attackType]; ok {
-		if multiplier, ok := matchups[defenseType]; ok {
-			return multiplier
-		}
-	}
-	return 1.0 // Neutral effectiveness
+	return TypeEffectiveness.Multiplier(attackType, defenseType)
 }
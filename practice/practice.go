@@ -0,0 +1,49 @@
+// Package main implements the Pokemon Battler practice application.
+// The trainer battles a local bot in one process, with no networking.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game"
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/peer"
+)
+
+// main asks for the trainer's name, the rules and a Pokemon, then battles
+// the bot until the trainer stops.
+func main() {
+	botFlag := flag.String("bot", game.BotMedium, fmt.Sprintf("Bot difficulty (%s)", strings.Join(game.BotDifficulties, ", ")))
+	budgetFlag := flag.Duration("budget", game.DefaultSearchBudget, "Thinking time per move for the expert bot")
+	flag.Parse()
+
+	bot, err := game.NewBot(*botFlag, time.Now().UnixNano(), *budgetFlag)
+	if err != nil {
+		netio.ERLine(fmt.Sprintf("Could not start the bot: %v", err), true)
+	}
+
+	fmt.Println("Welcome to PokeBattler practice")
+	self := peer.MakePD(netio.PRLine("What is your trainer name?"), nil, nil)
+	opponent := peer.MakePD(fmt.Sprintf("Bot (%s)", bot.Difficulty), nil, nil)
+
+	for {
+		ruleset := game.Local_setRules()
+		botPlayer, err := bot.NewBotPlayer(opponent, ruleset)
+		if err != nil {
+			netio.ERLine(fmt.Sprintf("The bot could not build a team: %v. Choose other rules.", err), false)
+			continue
+		}
+		p := game.PlayerSetUp(self, ruleset)
+		fmt.Printf("\nThe %s bot chose %s Lv. %d\n", bot.Difficulty, botPlayer.PokemonStruct.Name, botPlayer.PokemonStruct.Level)
+
+		game.RunLocalBattle(&p, &botPlayer, bot, rand.Intn(999), ruleset)
+
+		if strings.ToLower(netio.PRLine("Battle again? [y / N:default]")) != "y" {
+			return
+		}
+	}
+}
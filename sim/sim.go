@@ -0,0 +1,457 @@
+// Package main implements the headless batch simulator.
+// It plays bot-vs-bot battles in one process with no sockets, spread over
+// every CPU core, and reports win rates, battle lengths and the damage
+// distribution, or writes a matchup matrix between species.
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zrygan/pokemonbattler/game"
+	"github.com/zrygan/pokemonbattler/game/player"
+	"github.com/zrygan/pokemonbattler/game/rules"
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/peer"
+)
+
+// Report tuning.
+const (
+	histogramWidth   = 40 // Characters in the longest histogram bar
+	rankedSpecies    = 10 // Species listed at the top and bottom of a ranking
+	minSpeciesSample = 5  // Battles a species needs to be ranked
+	progressSteps    = 20 // Progress lines printed while filling a matrix
+)
+
+// sim holds what every battle of a run shares.
+type sim struct {
+	rules   rules.Ruleset
+	legal   []string // Species a random side picks from
+	botA    string
+	botB    string
+	budget  time.Duration
+	seed    int64
+	workers int
+}
+
+// battle is the outcome of one battle between sides A and B.
+type battle struct {
+	aSpecies string // Lead of side A
+	bSpecies string // Lead of side B
+	aHosted  bool   // Side A opened the battle
+	result   game.SimResult
+}
+
+// tally sums up battles between sides A and B.
+type tally struct {
+	battles, aWins, bWins, draws, openerWins int
+	turns, shortest, longest                 int
+	hits                                     []float64
+	species                                  map[string]*record // Random sides only
+}
+
+// record counts a species' battles and wins.
+type record struct {
+	battles, wins int
+}
+
+// main runs the simulations the flags describe.
+func main() {
+	aFlag := flag.String("a", "", "Side A as comma-separated species, lead first (a random species each battle if empty)")
+	bFlag := flag.String("b", "", "Side B as comma-separated species, lead first (a random species each battle if empty)")
+	battlesFlag := flag.Int("battles", 1000, "Battles to run, or battles per pair of species with -matrix")
+	botAFlag := flag.String("bot-a", game.BotMedium, fmt.Sprintf("Bot playing side A, and both sides with -matrix (%s)", strings.Join(game.BotDifficulties, ", ")))
+	botBFlag := flag.String("bot-b", game.BotMedium, fmt.Sprintf("Bot playing side B (%s)", strings.Join(game.BotDifficulties, ", ")))
+	budgetFlag := flag.Duration("budget", 50*time.Millisecond, "Thinking time per move for expert bots")
+	formatFlag := flag.String("format", "", "Format in data/formats to play under (default rules if empty)")
+	seedFlag := flag.Int64("seed", 1, "Seed of the first battle; battle i uses seed+i")
+	workersFlag := flag.Int("workers", runtime.NumCPU(), "Battles run at once")
+	matrixFlag := flag.String("matrix", "", "Write a species matchup matrix to this CSV file instead")
+	speciesFlag := flag.String("species", "", "Comma-separated species for -matrix (every legal species if empty)")
+	flag.Parse()
+
+	r := rules.Default()
+	if *formatFlag != "" {
+		var err error
+		if r, err = game.FindFormat(*formatFlag); err != nil {
+			netio.ERLine(fmt.Sprintf("Could not load format: %v", err), true)
+		}
+	}
+	r = game.LocalRules(r)
+
+	for _, difficulty := range []string{*botAFlag, *botBFlag} {
+		if _, err := game.NewBot(difficulty, 0, *budgetFlag); err != nil {
+			netio.ERLine(err.Error(), true)
+		}
+	}
+	if *battlesFlag < 1 || *workersFlag < 1 {
+		netio.ERLine("Run at least one battle on at least one worker.", true)
+	}
+
+	s := &sim{
+		rules:   r,
+		legal:   game.LegalSpecies(r),
+		botA:    *botAFlag,
+		botB:    *botBFlag,
+		budget:  *budgetFlag,
+		seed:    *seedFlag,
+		workers: *workersFlag,
+	}
+	if len(s.legal) == 0 {
+		netio.ERLine("No Pokemon are legal under this ruleset.", true)
+	}
+
+	fmt.Printf("Format: %s\n", r.DescribeFormat())
+	fmt.Printf("Workers: %d\n", s.workers)
+	start := time.Now()
+	if *matrixFlag != "" {
+		species := s.legal
+		if *speciesFlag != "" {
+			species = splitTeam(*speciesFlag)
+		}
+		s.runMatrix(species, *battlesFlag, *matrixFlag)
+	} else {
+		a, b := splitTeam(*aFlag), splitTeam(*bFlag)
+		for _, team := range [][]string{a, b} {
+			if _, err := s.team("check", team, nil); err != nil {
+				netio.ERLine(err.Error(), true)
+			}
+		}
+		s.runBatch(a, b, *battlesFlag)
+	}
+	fmt.Printf("\nFinished in %s\n", time.Since(start).Round(time.Millisecond))
+}
+
+// runBatch plays battles between sides a and b and reports the tally. An
+// empty side picks a random legal species each battle.
+func (s *sim) runBatch(a []string, b []string, battles int) {
+	fmt.Printf("Side A: %s (%s bot)\n", describeSide(a), s.botA)
+	fmt.Printf("Side B: %s (%s bot)\n", describeSide(b), s.botB)
+	fmt.Printf("Battles: %d, the sides taking turns to host\n", battles)
+
+	t := tally{shortest: game.SimMaxTurns, species: map[string]*record{}}
+	results := make(chan battle, s.workers)
+	go func() {
+		s.parallel(battles, func(i int) {
+			results <- s.play(a, b, i)
+		})
+		close(results)
+	}()
+	for result := range results {
+		t.add(result, len(a) == 0, len(b) == 0)
+	}
+	t.report()
+}
+
+// runMatrix plays battles between every pair of species and writes each
+// species' score against each other to a CSV file: its share of wins, a
+// draw counting half. Mirror matches are even by definition and not played.
+// The side A bot plays both species, so the matrix stays symmetric.
+func (s *sim) runMatrix(species []string, battles int, path string) {
+	s.botB = s.botA
+	for _, name := range species {
+		if _, err := s.team("check", []string{name}, nil); err != nil {
+			netio.ERLine(err.Error(), true)
+		}
+	}
+
+	type pair struct{ i, j int }
+	var pairs []pair
+	for i := range species {
+		for j := i + 1; j < len(species); j++ {
+			pairs = append(pairs, pair{i, j})
+		}
+	}
+	fmt.Printf("Matrix: %d species, %d pairs, %d battles per pair (%d battles), %s bots\n",
+		len(species), len(pairs), battles, len(pairs)*battles, s.botA)
+
+	scores := make([][]float64, len(species))
+	for i := range scores {
+		scores[i] = make([]float64, len(species))
+		scores[i][i] = 0.5
+	}
+
+	var mu sync.Mutex // Guards done
+	done := 0
+	s.parallel(len(pairs), func(n int) {
+		p := pairs[n]
+		a, b := []string{species[p.i]}, []string{species[p.j]}
+		score := 0.0
+		for k := range battles {
+			switch result := s.play(a, b, n*battles+k); result.winner() {
+			case "a":
+				score++
+			case "":
+				score += 0.5
+			}
+		}
+		scores[p.i][p.j] = score / float64(battles)
+		scores[p.j][p.i] = 1 - scores[p.i][p.j]
+
+		mu.Lock()
+		done++
+		if step := max(len(pairs)/progressSteps, 1); done%step == 0 || done == len(pairs) {
+			fmt.Printf("Progress: %d of %d pairs\n", done, len(pairs))
+		}
+		mu.Unlock()
+	})
+
+	if err := writeMatrix(path, species, scores); err != nil {
+		netio.ERLine(fmt.Sprintf("Could not write the matrix: %v", err), true)
+	}
+	fmt.Printf("Wrote %s\n", path)
+
+	average := make(map[string]float64, len(species))
+	for i, name := range species {
+		total := 0.0
+		for j, score := range scores[i] {
+			if i != j {
+				total += score
+			}
+		}
+		average[name] = total / float64(max(len(species)-1, 1))
+	}
+	showRanking("Average score against the field", species, average)
+}
+
+// parallel runs job for 0..n-1 on s.workers goroutines.
+func (s *sim) parallel(n int, job func(i int)) {
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				job(i)
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// play runs battle number i between sides a and b. Everything random about
+// it, from random species to the bots' choices, follows from seed+i, so a
+// run gives the same results however the battles are spread over workers.
+func (s *sim) play(a []string, b []string, i int) battle {
+	seed := s.seed + int64(i)
+	rng := rand.New(rand.NewSource(seed))
+	aTeam, _ := s.team("A", a, rng)
+	bTeam, _ := s.team("B", b, rng)
+	aBot, _ := game.NewBot(s.botA, rng.Int63(), s.budget)
+	bBot, _ := game.NewBot(s.botB, rng.Int63(), s.budget)
+
+	result := battle{aSpecies: aTeam.PokemonStruct.Name, bSpecies: bTeam.PokemonStruct.Name, aHosted: i%2 == 0}
+	if result.aHosted {
+		result.result = game.SimulateBattle(&aTeam, &bTeam, aBot, bBot, int(seed), s.rules)
+	} else {
+		result.result = game.SimulateBattle(&bTeam, &aTeam, bBot, aBot, int(seed), s.rules)
+	}
+	return result
+}
+
+// team builds a side from species, or from a random legal species drawn
+// from rng when species is empty.
+func (s *sim) team(name string, species []string, rng *rand.Rand) (player.Player, error) {
+	if len(species) == 0 {
+		if rng == nil {
+			return player.Player{}, nil
+		}
+		species = []string{s.legal[rng.Intn(len(s.legal))]}
+	}
+	return game.NewTeam(peer.MakePD(name, nil, nil), s.rules, species)
+}
+
+// winner returns "a", "b", or "" for a draw.
+func (b battle) winner() string {
+	switch {
+	case b.result.Winner == "":
+		return ""
+	case (b.result.Winner == "host") == b.aHosted:
+		return "a"
+	}
+	return "b"
+}
+
+// add counts a battle. Leads of random sides get a record of their own.
+func (t *tally) add(b battle, aRandom bool, bRandom bool) {
+	t.battles++
+	t.turns += b.result.Turns
+	t.shortest = min(t.shortest, b.result.Turns)
+	t.longest = max(t.longest, b.result.Turns)
+	t.hits = append(t.hits, b.result.Hits...)
+	if b.result.Winner != "" && b.result.Winner == b.result.Opener {
+		t.openerWins++
+	}
+
+	winner := b.winner()
+	switch winner {
+	case "a":
+		t.aWins++
+	case "b":
+		t.bWins++
+	default:
+		t.draws++
+	}
+	if aRandom {
+		t.count(b.aSpecies, winner == "a")
+	}
+	if bRandom {
+		t.count(b.bSpecies, winner == "b")
+	}
+}
+
+// count adds a battle to a species' record.
+func (t *tally) count(species string, won bool) {
+	rec, ok := t.species[species]
+	if !ok {
+		rec = &record{}
+		t.species[species] = rec
+	}
+	rec.battles++
+	if won {
+		rec.wins++
+	}
+}
+
+// report prints the tally.
+func (t *tally) report() {
+	n := float64(t.battles)
+	fmt.Printf("\nA wins: %.1f%%  B wins: %.1f%%  Draws: %.1f%%\n",
+		100*float64(t.aWins)/n, 100*float64(t.bWins)/n, 100*float64(t.draws)/n)
+	fmt.Printf("Opener wins: %.1f%%\n", 100*float64(t.openerWins)/n)
+	fmt.Printf("Average turns: %.1f (shortest %d, longest %d)\n", float64(t.turns)/n, t.shortest, t.longest)
+	showDamage(t.hits)
+
+	if len(t.species) == 0 {
+		return
+	}
+	var species []string
+	rates := map[string]float64{}
+	for name, rec := range t.species {
+		if rec.battles >= minSpeciesSample {
+			species = append(species, name)
+			rates[name] = float64(rec.wins) / float64(rec.battles)
+		}
+	}
+	showRanking(fmt.Sprintf("Win rates of random species (at least %d battles)", minSpeciesSample), species, rates)
+}
+
+// showDamage prints the spread of damage per attack as a histogram in
+// steps of 10% of the defender's max HP.
+func showDamage(hits []float64) {
+	if len(hits) == 0 {
+		fmt.Println("No damaging attacks were made.")
+		return
+	}
+	sorted := slices.Clone(hits)
+	slices.Sort(sorted)
+	total := 0.0
+	for _, hit := range sorted {
+		total += hit
+	}
+	percentile := func(p float64) float64 {
+		return sorted[min(int(p*float64(len(sorted))), len(sorted)-1)]
+	}
+	fmt.Printf("\nDamage per attack, in %% of the defender's max HP (%d attacks):\n", len(sorted))
+	fmt.Printf("Mean %.1f, median %.1f, 90th percentile %.1f, most %.1f\n",
+		total/float64(len(sorted)), percentile(0.5), percentile(0.9), sorted[len(sorted)-1])
+
+	var buckets [11]int
+	for _, hit := range sorted {
+		buckets[min(int(hit/10), len(buckets)-1)]++
+	}
+	largest := slices.Max(buckets[:])
+	for i, count := range buckets {
+		label := fmt.Sprintf("%d-%d%%", i*10, i*10+10)
+		if i == len(buckets)-1 {
+			label = "100%+"
+		}
+		bar := strings.Repeat("#", count*histogramWidth/largest)
+		fmt.Printf("%8s %-*s %5.1f%%\n", label, histogramWidth, bar, 100*float64(count)/float64(len(sorted)))
+	}
+}
+
+// showRanking prints the species with the highest and lowest values.
+func showRanking(title string, species []string, values map[string]float64) {
+	if len(species) == 0 {
+		return
+	}
+	ranked := slices.Clone(species)
+	slices.SortStableFunc(ranked, func(a, b string) int {
+		switch {
+		case values[a] > values[b]:
+			return -1
+		case values[a] < values[b]:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	fmt.Printf("\n%s:\n", title)
+	shown := min(rankedSpecies, len(ranked))
+	fmt.Println("  Best:")
+	for _, name := range ranked[:shown] {
+		fmt.Printf("    %-20s %5.1f%%\n", name, 100*values[name])
+	}
+	if len(ranked) > rankedSpecies {
+		fmt.Println("  Worst:")
+		for _, name := range ranked[len(ranked)-shown:] {
+			fmt.Printf("    %-20s %5.1f%%\n", name, 100*values[name])
+		}
+	}
+}
+
+// writeMatrix writes the scores as CSV: a header row of species, then one
+// row per species with its score against each column's species.
+func writeMatrix(path string, species []string, scores [][]float64) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write(append([]string{"species"}, species...))
+	for i, name := range species {
+		row := []string{name}
+		for _, score := range scores[i] {
+			row = append(row, strconv.FormatFloat(score, 'f', 3, 64))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// describeSide lists a side's species, or says it is random.
+func describeSide(species []string) string {
+	if len(species) == 0 {
+		return "a random species each battle"
+	}
+	return strings.Join(species, ", ")
+}
+
+// splitTeam splits a comma-separated list of species.
+func splitTeam(list string) []string {
+	var species []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			species = append(species, name)
+		}
+	}
+	return species
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/zrygan/pokemonbattler/messages"
 	"github.com/zrygan/pokemonbattler/netio"
 	"github.com/zrygan/pokemonbattler/peer"
-	monsters "github.com/zrygan/pokemonbattler/poke/mons"
 )
 
 // Global sequence number for spectator chat messages
@@ -254,7 +253,10 @@ func observeBattle(self peer.PeerDescriptor, host *peer.PeerDescriptor) {
 	var hostPokemon, joinerPokemon string
 	var hostHP, joinerHP int
 	var hostMaxHP, joinerMaxHP int
+	var hostLevel, joinerLevel int
+	battleMode := ""
 	battleStarted := false
+	trainers := map[int]string{} // Free-for-all trainers by seat
 
 	// Message deduplication to prevent duplicate logging in broadcast mode
 	processedMessages := make(map[string]bool)
@@ -332,21 +334,43 @@ func observeBattle(self peer.PeerDescriptor, host *peer.PeerDescriptor) {
 				params := *msg.MessageParams
 				pokemonName := params["pokemon_name"].(string)
 
-				if !battleStarted {
+				// Free-for-all setups are tagged with the trainer's seat
+				if seat, ok := params["seat"].(int); ok {
+					trainer, _ := params["trainer"].(string)
+					trainers[seat] = trainer
+					mon, err := game.PokemonFromSetup(params)
+					if err != nil {
+						fmt.Printf("Warning: %v\n", err)
+					}
+					if seat == 0 && battleMode != "" {
+						fmt.Printf("\n=== %s ===\n", strings.ToUpper(battleMode))
+					}
+					fmt.Printf("Seat %d: %s - %s (Lv. %d, %d HP)\n", seat+1, trainer, pokemonName, mon.Level, mon.MaxHP)
+				} else if !battleStarted {
+					// Stats depend on the level, IVs, EVs and nature in the setup
+					mon, err := game.PokemonFromSetup(params)
+					if err != nil {
+						fmt.Printf("Warning: %v\n", err)
+					}
+					if mon.HeldItem != "" {
+						fmt.Printf("%s is holding %s\n", pokemonName, mon.HeldItem)
+					}
+
 					if hostPokemon == "" {
 						hostPokemon = pokemonName
-						if mon, ok := monsters.MONSTERS[pokemonName]; ok {
-							hostHP = mon.HP
-							hostMaxHP = mon.HP
-						}
+						hostLevel = mon.Level
+						hostHP = mon.MaxHP
+						hostMaxHP = mon.MaxHP
 					} else {
 						joinerPokemon = pokemonName
-						if mon, ok := monsters.MONSTERS[pokemonName]; ok {
-							joinerHP = mon.HP
-							joinerMaxHP = mon.HP
-						}
+						joinerLevel = mon.Level
+						joinerHP = mon.MaxHP
+						joinerMaxHP = mon.MaxHP
 						battleStarted = true
-						fmt.Printf("\nBATTLE: %s vs %s\n", hostPokemon, joinerPokemon)
+						if battleMode != "" {
+							fmt.Printf("\n=== %s ===", strings.ToUpper(battleMode))
+						}
+						fmt.Printf("\nBATTLE: %s (Lv. %d) vs %s (Lv. %d)\n", hostPokemon, hostLevel, joinerPokemon, joinerLevel)
 						fmt.Printf("   %s: %d/%d HP\n", hostPokemon, hostHP, hostMaxHP)
 						fmt.Printf("   %s: %d/%d HP\n\n", joinerPokemon, joinerHP, joinerMaxHP)
 					}
@@ -362,8 +386,104 @@ func observeBattle(self peer.PeerDescriptor, host *peer.PeerDescriptor) {
 				)
 
 				params := *msg.MessageParams
-				moveName := params["move_name"].(string)
-				fmt.Printf("Attack announced: %s\n", moveName)
+				if seat, ok := params["seat"].(int); ok {
+					fmt.Printf("\n%s acts:\n", trainers[seat])
+				}
+				switch params["action"] {
+				case messages.ActionForfeit:
+					fmt.Println("Forfeit announced")
+				case messages.ActionItem:
+					fmt.Printf("Item announced: %s\n", params["item_name"])
+				case messages.ActionProtect:
+					fmt.Println("Protect announced")
+				case messages.ActionSwitch:
+					fmt.Println("Switch announced")
+				default:
+					moveName, _ := params["move_name"].(string)
+					fmt.Printf("Attack announced: %s\n", moveName)
+					if params["attack_boost"] == "true" {
+						fmt.Println("   Attack boost used!")
+					}
+					switch params["target"] {
+					case messages.TargetSpread:
+						fmt.Println("   Target: both foes")
+					case messages.TargetAlly:
+						fmt.Println("   Target: its partner")
+					case messages.TargetFoe:
+						targetSlot, _ := params["target_slot"].(int)
+						fmt.Printf("   Target: foe slot %d\n", targetSlot+1)
+					}
+					if targetSeat, ok := params["target_seat"].(int); ok {
+						fmt.Printf("   Target: %s\n", trainers[targetSeat])
+					}
+				}
+
+			case messages.DefenseAnnounce:
+				netio.VerboseEventLog(
+					"PokeProtocol: Received DEFENSE_ANNOUNCE",
+					&netio.LogOptions{
+						MessageParams: msg.MessageParams,
+					},
+				)
+
+				if (*msg.MessageParams)["defense_boost"] == "true" {
+					fmt.Println("   Defense boost used!")
+				}
+
+			case messages.GS_RULESET:
+				netio.VerboseEventLog(
+					"PokeProtocol: Received RULESET",
+					&netio.LogOptions{
+						MessageParams: msg.MessageParams,
+					},
+				)
+
+				ruleset := game.ParseRuleset(*msg.MessageParams)
+				battleMode = ruleset.Mode()
+				fmt.Printf("\nRules: %s, level cap %d, boosts: %s, damage model: %s\n", battleMode, ruleset.LevelCap, ruleset.DescribeBoosts(), ruleset.DamageModel)
+				fmt.Printf("Format: %s\n", ruleset.DescribeFormat())
+				if ruleset.TurnTimer > 0 || ruleset.GameClock > 0 {
+					fmt.Printf("Timers: %s\n", ruleset.DescribeTimers())
+				}
+				// The raid boss sits after both trainers and sends no setup
+				if ruleset.Raid() {
+					trainers[ruleset.Players] = game.RaidBossName
+					fmt.Printf("Boss: %s Lv. %d, x%d HP, %d action(s) per round\n",
+						ruleset.RaidBoss, ruleset.RaidLevel, ruleset.RaidHPMultiplier, ruleset.RaidActions)
+				}
+
+			case messages.Clock:
+				netio.VerboseEventLog(
+					"PokeProtocol: Received CLOCK",
+					&netio.LogOptions{
+						MessageParams: msg.MessageParams,
+					},
+				)
+
+				params := *msg.MessageParams
+				turn, _ := params["turn"].(string)
+				turnLeft, _ := params["turn_left"].(int)
+				clockText := fmt.Sprintf("[Clock] %s to move, %ds left this turn", turn, turnLeft)
+				if hostClock, ok := params["host_clock"].(int); ok && hostClock >= 0 {
+					joinerClock, _ := params["joiner_clock"].(int)
+					clockText += fmt.Sprintf(" | host %s, joiner %s",
+						game.FormatClock(time.Duration(hostClock)*time.Second),
+						game.FormatClock(time.Duration(joinerClock)*time.Second))
+				}
+				fmt.Println(clockText)
+
+			case messages.FFATurn:
+				netio.VerboseEventLog(
+					"PokeProtocol: Received FFA_TURN",
+					&netio.LogOptions{
+						MessageParams: msg.MessageParams,
+					},
+				)
+
+				params := *msg.MessageParams
+				seat, _ := params["seat"].(int)
+				turn, _ := params["turn"].(int)
+				fmt.Printf("\n--- Turn %d: %s to move ---\n", turn, trainers[seat])
 
 			case messages.CalculationReport:
 				// Verbose logging for received CALCULATION_REPORT
@@ -382,19 +502,98 @@ func observeBattle(self peer.PeerDescriptor, host *peer.PeerDescriptor) {
 				statusMsg := params["status_message"].(string)
 
 				fmt.Printf("\n%s used %s!\n", attacker, moveName)
+				if priority, ok := params["priority"].(int); ok && priority != 0 {
+					fmt.Printf("   Priority: %+d\n", priority)
+				}
 				fmt.Printf("   Damage: %d\n", damage)
+				if hitCount, ok := params["hit_count"].(int); ok && hitCount > 1 {
+					fmt.Printf("   Hits: %d (%s)\n", hitCount, params["hit_damage"])
+				}
+				if drained, ok := params["drained"].(int); ok && drained > 0 {
+					fmt.Printf("   Drained: %d HP\n", drained)
+				}
+				if recoil, ok := params["recoil"].(int); ok && recoil > 0 {
+					fmt.Printf("   Recoil: %d HP\n", recoil)
+				}
+				if itemRecoil, ok := params["item_recoil"].(int); ok && itemRecoil > 0 {
+					fmt.Printf("   Held item recoil: %d HP\n", itemRecoil)
+				}
+				if scripted, ok := params["scripted_effects"].(string); ok && scripted != "" {
+					fmt.Printf("   Effects: %s\n", scripted)
+				}
+
+				// Prefer end-of-turn HP, which includes weather and terrain effects
+				attackerHP, hasAttackerHP := params["remaining_health"].(int)
+				if defenderHPEnd, ok := params["defender_hp_end"].(int); ok {
+					defenderHP = defenderHPEnd
+				}
 
 				// Update HP tracking
 				if attacker == hostPokemon {
 					joinerHP = defenderHP
+					if hasAttackerHP {
+						hostHP = attackerHP
+					}
 				} else {
 					hostHP = defenderHP
+					if hasAttackerHP {
+						joinerHP = attackerHP
+					}
+				}
+
+				// Newer reports carry both active Pokemon, which follows switches
+				if name, ok := params["host_pokemon"].(string); ok {
+					hostPokemon = name
+					hostHP, _ = params["host_hp"].(int)
+					hostMaxHP, _ = params["host_max_hp"].(int)
+				}
+				if name, ok := params["joiner_pokemon"].(string); ok {
+					joinerPokemon = name
+					joinerHP, _ = params["joiner_hp"].(int)
+					joinerMaxHP, _ = params["joiner_max_hp"].(int)
 				}
 
 				fmt.Printf("   Status: %s\n", statusMsg)
-				fmt.Printf("\n   Current HP:\n")
-				fmt.Printf("   %s: %d/%d\n", hostPokemon, hostHP, hostMaxHP)
-				fmt.Printf("   %s: %d/%d\n\n", joinerPokemon, joinerHP, joinerMaxHP)
+				if fieldEvents, ok := params["field_events"].(string); ok && fieldEvents != "" {
+					fmt.Printf("   Field events: %s\n", fieldEvents)
+				}
+				if field, ok := params["field"].(string); ok && field != "" {
+					fmt.Printf("   Field: %s\n", field)
+				}
+				// Free-for-all and raid reports list every trainer, raid
+				// reports add the boss's HP; doubles reports carry both
+				// partners, so show all four HP bars
+				if standings, ok := params["standings"].(string); ok {
+					fmt.Printf("   Standings: %s\n", standings)
+					if boss, ok := params["boss"].(string); ok {
+						bossHP, _ := params["boss_hp"].(int)
+						bossMaxHP, _ := params["boss_max_hp"].(int)
+						fmt.Printf("   Boss: %s\n", game.HPBar(boss, bossHP, bossMaxHP))
+					}
+					fmt.Println()
+				} else if hostPartner, ok := params["host_partner"].(string); ok {
+					if target, ok := params["target"].(string); ok && target != "" {
+						fmt.Printf("   Target: %s\n", target)
+					}
+					if spread, ok := params["spread_damage"]; ok && spread != "" {
+						fmt.Printf("   Spread damage: %v\n", spread)
+					}
+					hostPartnerHP, _ := params["host_partner_hp"].(int)
+					hostPartnerMaxHP, _ := params["host_partner_max_hp"].(int)
+					joinerPartner, _ := params["joiner_partner"].(string)
+					joinerPartnerHP, _ := params["joiner_partner_hp"].(int)
+					joinerPartnerMaxHP, _ := params["joiner_partner_max_hp"].(int)
+
+					fmt.Printf("\n   Current HP:\n")
+					fmt.Printf("   Host:   %s\n", game.HPBar(hostPokemon, hostHP, hostMaxHP))
+					fmt.Printf("           %s\n", game.HPBar(hostPartner, hostPartnerHP, hostPartnerMaxHP))
+					fmt.Printf("   Joiner: %s\n", game.HPBar(joinerPokemon, joinerHP, joinerMaxHP))
+					fmt.Printf("           %s\n\n", game.HPBar(joinerPartner, joinerPartnerHP, joinerPartnerMaxHP))
+				} else {
+					fmt.Printf("\n   Current HP:\n")
+					fmt.Printf("   %s: %d/%d\n", hostPokemon, hostHP, hostMaxHP)
+					fmt.Printf("   %s: %d/%d\n\n", joinerPokemon, joinerHP, joinerMaxHP)
+				}
 
 			case messages.GameOver:
 				// Verbose logging for received GAME_OVER
@@ -406,12 +605,32 @@ func observeBattle(self peer.PeerDescriptor, host *peer.PeerDescriptor) {
 				)
 
 				params := *msg.MessageParams
-				winner := params["winner"].(string)
-				loser := params["loser"].(string)
+				winner, _ := params["winner"].(string)
+				loser, _ := params["loser"].(string)
 
 				fmt.Printf("\n=== BATTLE END ===\n")
-				fmt.Printf("Winner: %s\n", winner)
-				fmt.Printf("Loser: %s\n", loser)
+				if placements, ok := params["placements"].(string); ok {
+					for i, name := range strings.Split(placements, ",") {
+						fmt.Printf("%s: %s\n", game.Ordinal(i+1), name)
+					}
+				} else if cleared, ok := params["raid_cleared"].(string); ok {
+					if cleared == "true" {
+						fmt.Printf("Raid cleared! %s defeated %s\n", winner, loser)
+					} else {
+						fmt.Printf("Raid failed! %s held off %s\n", winner, loser)
+					}
+				} else if params["result"] == messages.ResultDraw {
+					fmt.Println("Result: Draw")
+				} else {
+					fmt.Printf("Winner: %s\n", winner)
+					fmt.Printf("Loser: %s\n", loser)
+				}
+				if reason, ok := params["reason"].(string); ok {
+					fmt.Printf("Reason: %s\n", reason)
+				}
+				if tiebreak, ok := params["tiebreak"].(string); ok && tiebreak != "" {
+					fmt.Printf("Decided by: %s tiebreaker\n", tiebreak)
+				}
 				fmt.Println("\nBattle has ended. Returning to main menu...")
 
 				// Keep listening for any final messages
@@ -0,0 +1,40 @@
+// Package main implements the type chart consistency checker.
+// It compares a type chart's dual-type multipliers with the against_*
+// columns of every species in the Pokemon CSV and reports the mismatches.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zrygan/pokemonbattler/netio"
+	"github.com/zrygan/pokemonbattler/poke"
+)
+
+// main loads the chart and CSV named by the flags and prints every mismatch.
+// It exits with status 1 if any are found.
+func main() {
+	chartFlag := flag.String("chart", filepath.Join("data", "typechart.json"), "Type chart JSON file to check")
+	csvFlag := flag.String("csv", filepath.Join("data", "pokemon.csv"), "Pokemon CSV with against_* columns")
+	flag.Parse()
+
+	chart, err := poke.LoadTypeChart(*chartFlag)
+	if err != nil {
+		netio.ERLine(fmt.Sprintf("Failed to load type chart: %v", err), true)
+	}
+
+	mismatches, err := poke.CheckTypeChart(chart, *csvFlag)
+	if err != nil {
+		netio.ERLine(fmt.Sprintf("Failed to check type chart: %v", err), true)
+	}
+
+	for _, mismatch := range mismatches {
+		fmt.Println(mismatch)
+	}
+	fmt.Printf("%d mismatches across the species in %s\n", len(mismatches), *csvFlag)
+	if len(mismatches) > 0 {
+		os.Exit(1)
+	}
+}